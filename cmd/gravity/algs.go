@@ -0,0 +1,82 @@
+package main
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"errors"
+	"strings"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// marshaller is implemented by the marshallers of every algorithm
+type marshaller interface {
+	MarshalPrivKey(privKey crypto.PrivateKey) ([]byte, error)
+	UnmarshalPrivKey(privKeyBytes []byte) (crypto.PrivateKey, error)
+	MarshalPubKey(pubKey crypto.PublicKey) ([]byte, error)
+	UnmarshalPubKey(pubKeyBytes []byte) (crypto.PublicKey, error)
+	MarshalSig(sig ec.Sig) ([]byte, error)
+	UnmarshalSig(sigBytes []byte) (ec.Sig, error)
+}
+
+// algorithms lists the names accepted by --alg
+var algorithms = []string{"ecdsa256", "ecdsa512", "ed25519", "secp256k1"}
+
+// algorithm binds a worker with its marshaller
+type algorithm struct {
+	name   string
+	curve  string
+	worker ec.Worker
+	codec  marshaller
+}
+
+func newAlgorithm(name string) (*algorithm, error) {
+	switch name {
+	case "ed25519":
+		return &algorithm{name, "edwards25519", new(ed25519.Worker), new(ed25519.Marshaller)}, nil
+	case "ecdsa256":
+		w := new(ecdsa.Worker256)
+		return &algorithm{name, "P-256", w, w}, nil
+	case "ecdsa512":
+		w := new(ecdsa.Worker512)
+		return &algorithm{name, "P-521", w, w}, nil
+	case "secp256k1":
+		w := secp.New()
+		return &algorithm{name, "secp256k1", w, w}, nil
+	}
+
+	return nil, errors.New("unknown algorithm " + name + ", want one of " + algNames())
+}
+
+// algorithmOf finds the algorithm of a decoded key
+func algorithmOf(key interface{}) (*algorithm, error) {
+	var name string
+	switch k := key.(type) {
+	case ed25519.PrivateKey, ed25519.PublicKey:
+		name = "ed25519"
+	case *ecdsa.PrivateKey:
+		return algorithmOf(&k.PublicKey)
+	case *ecdsa.PublicKey:
+		switch {
+		case elliptic.P256() == k.Curve:
+			name = "ecdsa256"
+		case elliptic.P521() == k.Curve:
+			name = "ecdsa512"
+		case secp.IsS256(k.Curve):
+			name = "secp256k1"
+		default:
+			return nil, ec.ErrECTypeUnsupported
+		}
+	default:
+		return nil, ec.ErrECTypeUnsupported
+	}
+
+	return newAlgorithm(name)
+}
+
+func algNames() string {
+	return strings.Join(algorithms, ", ")
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"golang.org/x/crypto/sha3"
+)
+
+// hashes applied to the message before signing
+var hashes = map[string]func(msg []byte) []byte{
+	"sha3-256": func(msg []byte) []byte { h := sha3.Sum256(msg); return h[:] },
+	"sha256":   func(msg []byte) []byte { h := sha256.Sum256(msg); return h[:] },
+	"sha512":   func(msg []byte) []byte { h := sha512.Sum512(msg); return h[:] },
+	"none":     func(msg []byte) []byte { return msg },
+}
+
+const hashUsage = "hash of the message: sha3-256, sha256, sha512 or none if the input is the digest"
+
+func checkFormat(fs *flag.FlagSet, format string) error {
+	for _, f := range formats {
+		if f == format {
+			return nil
+		}
+	}
+	fmt.Fprintln(fs.Output(), errUnknownFormat)
+
+	return errUsage
+}
+
+// printKey writes the encoded key to out, or reports it in the JSON output
+// when it goes to stdout
+func (e *env) printKey(key interface{}, a *algorithm, format, out string) (int, error) {
+	encoded, err := encodeKey(key, a, format)
+	if nil != err {
+		return exitFailure, err
+	}
+
+	pub, err := a.codec.MarshalPubKey(publicOf(key))
+	if nil != err {
+		return exitFailure, err
+	}
+
+	result := map[string]string{
+		"alg":       a.name,
+		"curve":     a.curve,
+		"format":    format,
+		"publicKey": hex.EncodeToString(pub),
+	}
+
+	if !e.json || ("" != out && "-" != out) {
+		if err := e.writeOutput(out, encoded, isPrivKey(key)); nil != err {
+			return exitFailure, err
+		}
+		if "" == out || "-" == out {
+			return exitOK, nil
+		}
+		result["out"] = out
+	} else {
+		if formatRaw == format {
+			encoded = []byte(hex.EncodeToString(encoded))
+		}
+		result["key"] = string(bytes.TrimSpace(encoded))
+	}
+
+	e.print(result,
+		field{"alg", a.name},
+		field{"curve", a.curve},
+		field{"public key", result["publicKey"]},
+		field{"written to", out})
+
+	return exitOK, nil
+}
+
+func publicOf(key interface{}) ec.PublicKey {
+	if priv, ok := key.(ec.PrivateKey); ok && isPrivKey(key) {
+		return priv.Public()
+	}
+
+	return key
+}
+
+func runKeygen(e *env, args []string) (int, error) {
+	fs := e.newFlagSet("keygen")
+	alg := fs.String("alg", "", "algorithm: "+algNames())
+	format := fs.String("format", formatHex, "format of the private key: raw, hex, pem or jwk")
+	out := fs.String("out", "", "file of the private key (default stdout)")
+	if code, err := parse(fs, args); nil != err {
+		return code, err
+	}
+	if err := checkFormat(fs, *format); nil != err {
+		return exitUsage, err
+	}
+
+	a, err := newAlgorithm(*alg)
+	if nil != err {
+		fmt.Fprintln(fs.Output(), err)
+		return exitUsage, errUsage
+	}
+
+	priv, err := a.worker.GenerateKey(rand.Reader)
+	if nil != err {
+		return exitFailure, err
+	}
+
+	return e.printKey(priv, a, *format, *out)
+}
+
+func runPubkey(e *env, args []string) (int, error) {
+	fs := e.newFlagSet("pubkey")
+	keyFile := fs.String("key", "", "file of the private key")
+	alg := fs.String("alg", "", "algorithm of raw and hex keys (default detected)")
+	format := fs.String("format", formatHex, "format of the public key: raw, hex, pem or jwk")
+	out := fs.String("out", "", "file of the public key (default stdout)")
+	if code, err := parse(fs, args); nil != err {
+		return code, err
+	}
+	if err := checkFormat(fs, *format); nil != err {
+		return exitUsage, err
+	}
+	if "" == *keyFile {
+		fmt.Fprintln(fs.Output(), "--key is required")
+		return exitUsage, errUsage
+	}
+
+	data, err := e.readInput(*keyFile)
+	if nil != err {
+		return exitFailure, err
+	}
+	key, a, err := decodeKey(data, *alg)
+	if nil != err {
+		return exitFailure, err
+	}
+
+	return e.printKey(publicOf(key), a, *format, *out)
+}
+
+func runSign(e *env, args []string) (int, error) {
+	fs := e.newFlagSet("sign")
+	keyFile := fs.String("key", "", "file of the private key")
+	alg := fs.String("alg", "", "algorithm of raw and hex keys (default detected)")
+	in := fs.String("in", "", "file of the message (default stdin)")
+	hashName := fs.String("hash", "sha3-256", hashUsage)
+	format := fs.String("format", formatHex, "format of the signature: raw or hex")
+	out := fs.String("out", "", "file of the signature (default stdout)")
+	if code, err := parse(fs, args); nil != err {
+		return code, err
+	}
+
+	hash, ok := hashes[*hashName]
+	if !ok || "" == *keyFile || (formatRaw != *format && formatHex != *format) {
+		fs.Usage()
+		return exitUsage, errUsage
+	}
+
+	data, err := e.readInput(*keyFile)
+	if nil != err {
+		return exitFailure, err
+	}
+	key, a, err := decodeKey(data, *alg)
+	if nil != err {
+		return exitFailure, err
+	}
+	priv, ok := key.(ec.PrivateKey)
+	if !ok || !isPrivKey(key) {
+		return exitFailure, errors.New("signing needs a private key")
+	}
+
+	msg, err := e.readInput(*in)
+	if nil != err {
+		return exitFailure, err
+	}
+	digest := hash(msg)
+
+	sig, err := a.worker.Sign(priv, digest)
+	if nil != err {
+		return exitFailure, err
+	}
+	blob, err := a.codec.MarshalSig(sig)
+	if nil != err {
+		return exitFailure, err
+	}
+
+	if e.json && ("" == *out || "-" == *out) {
+		e.print(map[string]string{
+			"alg":       a.name,
+			"hash":      *hashName,
+			"digest":    hex.EncodeToString(digest),
+			"signature": hex.EncodeToString(blob),
+		})
+		return exitOK, nil
+	}
+
+	if formatHex == *format {
+		blob = []byte(hex.EncodeToString(blob) + "\n")
+	}
+	if err := e.writeOutput(*out, blob, false); nil != err {
+		return exitFailure, err
+	}
+	if "" != *out && "-" != *out {
+		e.print(map[string]string{"alg": a.name, "hash": *hashName, "out": *out},
+			field{"alg", a.name}, field{"written to", *out})
+	}
+
+	return exitOK, nil
+}
+
+func runVerify(e *env, args []string) (int, error) {
+	fs := e.newFlagSet("verify")
+	keyFile := fs.String("key", "", "file of the public or private key")
+	alg := fs.String("alg", "", "algorithm of raw and hex keys (default detected)")
+	sigFile := fs.String("sig", "", "file of the signature, raw or hex")
+	in := fs.String("in", "", "file of the message (default stdin)")
+	hashName := fs.String("hash", "sha3-256", hashUsage)
+	if code, err := parse(fs, args); nil != err {
+		return code, err
+	}
+
+	hash, ok := hashes[*hashName]
+	if !ok || "" == *keyFile || "" == *sigFile {
+		fs.Usage()
+		return exitUsage, errUsage
+	}
+
+	data, err := e.readInput(*keyFile)
+	if nil != err {
+		return exitFailure, err
+	}
+	key, a, err := decodeKey(data, *alg)
+	if nil != err {
+		return exitFailure, err
+	}
+
+	blob, err := e.readInput(*sigFile)
+	if nil != err {
+		return exitFailure, err
+	}
+	if formatHex == detectFormat(blob) {
+		blob, _ = hex.DecodeString(string(bytes.TrimSpace(blob)))
+	}
+
+	msg, err := e.readInput(*in)
+	if nil != err {
+		return exitFailure, err
+	}
+
+	// a signature of another algorithm or malformed is as invalid as a forged
+	// one, instead of a failure to read the inputs
+	sig, err := a.codec.UnmarshalSig(blob)
+	valid := (nil == err) && a.worker.Verify(publicOf(key), hash(msg), sig)
+	status := "valid"
+	if !valid {
+		status = "invalid"
+	}
+	e.print(map[string]interface{}{"alg": a.name, "valid": valid},
+		field{"signature", status})
+
+	if !valid {
+		return exitInvalid, nil
+	}
+
+	return exitOK, nil
+}
+
+func runConvert(e *env, args []string) (int, error) {
+	fs := e.newFlagSet("convert")
+	in := fs.String("in", "", "file of the key in any format (default stdin)")
+	alg := fs.String("alg", "", "algorithm of raw and hex keys (default detected)")
+	to := fs.String("to", "", "target format: raw, hex, pem or jwk")
+	out := fs.String("out", "", "file of the converted key (default stdout)")
+	if code, err := parse(fs, args); nil != err {
+		return code, err
+	}
+	if err := checkFormat(fs, *to); nil != err {
+		return exitUsage, err
+	}
+
+	data, err := e.readInput(*in)
+	if nil != err {
+		return exitFailure, err
+	}
+	key, a, err := decodeKey(data, *alg)
+	if nil != err {
+		return exitFailure, err
+	}
+
+	return e.printKey(key, a, *to, *out)
+}
+
+func runInspect(e *env, args []string) (int, error) {
+	fs := e.newFlagSet("inspect")
+	in := fs.String("in", "", "file of the key or signature in any format (default stdin)")
+	if code, err := parse(fs, args); nil != err {
+		return code, err
+	}
+
+	data, err := e.readInput(*in)
+	if nil != err {
+		return exitFailure, err
+	}
+
+	var info *blobInfo
+	switch format := detectFormat(data); format {
+	case formatPEM, formatJWK:
+		key, a, err := decodeKey(data, "")
+		if nil != err {
+			return exitFailure, err
+		}
+		info = &blobInfo{Format: format, Kind: kindPubKey, Alg: a.name, Curve: a.curve, Size: len(data)}
+		if isPrivKey(key) {
+			info.Kind = kindPrivKey
+		}
+	case formatHex:
+		data, _ = hex.DecodeString(string(bytes.TrimSpace(data)))
+		if info, err = inspectBlob(data); nil != err {
+			return exitFailure, err
+		}
+		info.Format = formatHex
+	default:
+		if info, err = inspectBlob(data); nil != err {
+			return exitFailure, err
+		}
+	}
+
+	fields := []field{{"format", info.Format}}
+	if 0 != info.Version {
+		fields = append(fields, field{"version", info.Version})
+	}
+	fields = append(fields,
+		field{"kind", info.Kind},
+		field{"alg", info.Alg},
+		field{"curve", info.Curve},
+		field{"size", info.Size})
+	e.print(info, fields...)
+
+	return exitOK, nil
+}
@@ -0,0 +1,441 @@
+package main
+
+import (
+	"bytes"
+	stdEcdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+	stdEd25519 "golang.org/x/crypto/ed25519"
+)
+
+// formats of the key files
+const (
+	formatRaw = "raw"
+	formatHex = "hex"
+	formatPEM = "pem"
+	formatJWK = "jwk"
+)
+
+var formats = []string{formatRaw, formatHex, formatPEM, formatJWK}
+
+var errUnknownFormat = errors.New("unknown format, want one of " + strings.Join(formats, ", "))
+
+// detectFormat guesses the format of data
+func detectFormat(data []byte) string {
+	text := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(text, []byte("-----BEGIN ")):
+		return formatPEM
+	case bytes.HasPrefix(text, []byte("{")):
+		return formatJWK
+	}
+
+	if 0 != len(text) && 0 == len(text)%2 {
+		if _, err := hex.DecodeString(string(text)); nil == err {
+			return formatHex
+		}
+	}
+
+	return formatRaw
+}
+
+// decodeKey decodes a private or public key in any format, where the
+// algorithm of raw and hex blobs is taken from alg if given
+func decodeKey(data []byte, alg string) (interface{}, *algorithm, error) {
+	var (
+		key interface{}
+		err error
+	)
+	switch detectFormat(data) {
+	case formatPEM:
+		key, err = decodePEM(data)
+	case formatJWK:
+		key, err = decodeJWK(data)
+	case formatHex:
+		var blob []byte
+		if blob, err = hex.DecodeString(string(bytes.TrimSpace(data))); nil == err {
+			key, err = decodeBlob(blob, alg)
+		}
+	default:
+		key, err = decodeBlob(data, alg)
+	}
+	if nil != err {
+		return nil, nil, err
+	}
+
+	a, err := algorithmOf(key)
+	if nil != err {
+		return nil, nil, err
+	}
+	if "" != alg && alg != a.name {
+		return nil, nil, errors.New("the key is of " + a.name + " rather than " + alg)
+	}
+
+	// PEM and JWK inputs get the same public key checks as the blobs
+	pub := key
+	if priv, ok := key.(ec.PrivateKey); ok {
+		pub = priv.Public()
+	}
+	if err := a.worker.(ec.Validator).Validate(pub); nil != err {
+		return nil, nil, err
+	}
+
+	return key, a, nil
+}
+
+func decodeBlob(blob []byte, alg string) (interface{}, error) {
+	info, err := inspectBlob(blob)
+	if nil != err {
+		return nil, err
+	}
+	if kindSig == info.Kind {
+		return nil, errors.New("a signature isn't a key")
+	}
+	if "" == alg {
+		alg = info.Alg
+	}
+
+	a, err := newAlgorithm(alg)
+	if nil != err {
+		return nil, err
+	}
+
+	if kindPrivKey == info.Kind {
+		return a.codec.UnmarshalPrivKey(blob)
+	}
+
+	return a.codec.UnmarshalPubKey(blob)
+}
+
+// isPrivKey tells whether key is a private key
+func isPrivKey(key interface{}) bool {
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return stdEd25519.PrivateKeySize == len(k.PrivateKey)
+	case *stdEcdsa.PrivateKey:
+		return (nil != k.D) && (0 != k.D.Cmp(k.X))
+	}
+
+	return false
+}
+
+// encodeKey encodes a private or public key in format
+func encodeKey(key interface{}, a *algorithm, format string) ([]byte, error) {
+	switch format {
+	case formatRaw, formatHex:
+		var (
+			blob []byte
+			err  error
+		)
+		if isPrivKey(key) {
+			blob, err = a.codec.MarshalPrivKey(key)
+		} else {
+			blob, err = a.codec.MarshalPubKey(key)
+		}
+		if nil != err {
+			return nil, err
+		}
+		if formatHex == format {
+			return []byte(hex.EncodeToString(blob) + "\n"), nil
+		}
+		return blob, nil
+	case formatPEM:
+		return encodePEM(key)
+	case formatJWK:
+		return encodeJWK(key)
+	}
+
+	return nil, errUnknownFormat
+}
+
+// ASN.1 structures for secp256k1, which the x509 package doesn't know
+var (
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidSecp256k1      = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// ecPrivateKey is the SEC1 private key
+type ecPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+func encodePEM(key interface{}) ([]byte, error) {
+	var block *pem.Block
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		der, err := x509.MarshalPKCS8PrivateKey(k.PrivateKey)
+		if nil != err {
+			return nil, err
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	case ed25519.PublicKey:
+		der, err := x509.MarshalPKIXPublicKey(k)
+		if nil != err {
+			return nil, err
+		}
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	case *stdEcdsa.PrivateKey:
+		var (
+			der []byte
+			err error
+		)
+		if secp.IsS256(k.Curve) {
+			der, err = asn1.Marshal(ecPrivateKey{
+				Version:       1,
+				PrivateKey:    k.D.FillBytes(make([]byte, 32)),
+				NamedCurveOID: oidSecp256k1,
+				PublicKey:     asn1.BitString{Bytes: marshalPoint(&k.PublicKey)},
+			})
+		} else {
+			der, err = x509.MarshalECPrivateKey(k)
+		}
+		if nil != err {
+			return nil, err
+		}
+		block = &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+	case *stdEcdsa.PublicKey:
+		var (
+			der []byte
+			err error
+		)
+		if secp.IsS256(k.Curve) {
+			params, _ := asn1.Marshal(oidSecp256k1)
+			der, err = asn1.Marshal(subjectPublicKeyInfo{
+				Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidPublicKeyECDSA, Parameters: asn1.RawValue{FullBytes: params}},
+				PublicKey: asn1.BitString{Bytes: marshalPoint(k)},
+			})
+		} else {
+			der, err = x509.MarshalPKIXPublicKey(k)
+		}
+		if nil != err {
+			return nil, err
+		}
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	default:
+		return nil, ec.ErrECTypeUnsupported
+	}
+
+	return pem.EncodeToMemory(block), nil
+}
+
+// marshalPoint encodes the point uncompressed as SEC1
+func marshalPoint(pub *stdEcdsa.PublicKey) []byte {
+	size := (pub.Curve.Params().BitSize + 7) / 8
+	point := make([]byte, 1+2*size)
+	point[0] = 4
+	pub.X.FillBytes(point[1 : 1+size])
+	pub.Y.FillBytes(point[1+size:])
+
+	return point
+}
+
+func unmarshalSecpPoint(point []byte) (*stdEcdsa.PublicKey, error) {
+	c := secp.S256()
+	if 65 != len(point) || 4 != point[0] {
+		return nil, errors.New("malformed secp256k1 point")
+	}
+
+	pub := &stdEcdsa.PublicKey{
+		Curve: c,
+		X:     new(big.Int).SetBytes(point[1:33]),
+		Y:     new(big.Int).SetBytes(point[33:]),
+	}
+	if !c.IsOnCurve(pub.X, pub.Y) {
+		return nil, errors.New("point not on secp256k1")
+	}
+
+	return pub, nil
+}
+
+func decodePEM(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if nil == block {
+		return nil, errors.New("malformed PEM")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if nil != err {
+			return nil, err
+		}
+		return fromStandard(key)
+	case "EC PRIVATE KEY":
+		var sec1 ecPrivateKey
+		if _, err := asn1.Unmarshal(block.Bytes, &sec1); nil == err && sec1.NamedCurveOID.Equal(oidSecp256k1) {
+			pub, err := unmarshalSecpPoint(sec1.PublicKey.Bytes)
+			if nil != err {
+				return nil, err
+			}
+			return &stdEcdsa.PrivateKey{PublicKey: *pub, D: new(big.Int).SetBytes(sec1.PrivateKey)}, nil
+		}
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		var spki subjectPublicKeyInfo
+		if _, err := asn1.Unmarshal(block.Bytes, &spki); nil == err {
+			var oid asn1.ObjectIdentifier
+			if _, err := asn1.Unmarshal(spki.Algorithm.Parameters.FullBytes, &oid); nil == err && oid.Equal(oidSecp256k1) {
+				return unmarshalSecpPoint(spki.PublicKey.Bytes)
+			}
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if nil != err {
+			return nil, err
+		}
+		return fromStandard(key)
+	}
+
+	return nil, errors.New("unsupported PEM type " + block.Type)
+}
+
+// fromStandard converts the keys of the standard library
+func fromStandard(key interface{}) (interface{}, error) {
+	switch k := key.(type) {
+	case *stdEcdsa.PrivateKey, *stdEcdsa.PublicKey:
+		return k, nil
+	case stdEd25519.PrivateKey:
+		return ed25519.PrivateKey{PrivateKey: k, PublicKey: k.Public().(ed25519.PublicKey)}, nil
+	case stdEd25519.PublicKey:
+		return ed25519.PublicKey(k), nil
+	}
+
+	return nil, ec.ErrECTypeUnsupported
+}
+
+// jwk is the JSON web key of RFC 7517, RFC 8037 and RFC 8812
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y,omitempty"`
+	D   string `json:"d,omitempty"`
+}
+
+var b64 = base64.RawURLEncoding
+
+func encodeJWK(key interface{}) ([]byte, error) {
+	var k jwk
+	switch key := key.(type) {
+	case ed25519.PrivateKey:
+		k = jwk{Kty: "OKP", Crv: "Ed25519", X: b64.EncodeToString(key.PublicKey), D: b64.EncodeToString(key.PrivateKey.Seed())}
+	case ed25519.PublicKey:
+		k = jwk{Kty: "OKP", Crv: "Ed25519", X: b64.EncodeToString(key)}
+	case *stdEcdsa.PrivateKey:
+		k = ecJWK(&key.PublicKey)
+		size := (key.Curve.Params().BitSize + 7) / 8
+		k.D = b64.EncodeToString(key.D.FillBytes(make([]byte, size)))
+	case *stdEcdsa.PublicKey:
+		k = ecJWK(key)
+	default:
+		return nil, ec.ErrECTypeUnsupported
+	}
+
+	data, err := json.MarshalIndent(k, "", "  ")
+	if nil != err {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+func ecJWK(pub *stdEcdsa.PublicKey) jwk {
+	crv := pub.Curve.Params().Name
+	if secp.IsS256(pub.Curve) {
+		crv = "secp256k1"
+	}
+
+	point := marshalPoint(pub)
+	size := (len(point) - 1) / 2
+
+	return jwk{Kty: "EC", Crv: crv, X: b64.EncodeToString(point[1 : 1+size]), Y: b64.EncodeToString(point[1+size:])}
+}
+
+func decodeJWK(data []byte) (interface{}, error) {
+	var k jwk
+	if err := json.Unmarshal(data, &k); nil != err {
+		return nil, err
+	}
+
+	x, err := b64.DecodeString(k.X)
+	if nil != err {
+		return nil, err
+	}
+	d, err := b64.DecodeString(k.D)
+	if nil != err {
+		return nil, err
+	}
+
+	if "OKP" == k.Kty && "Ed25519" == k.Crv {
+		if stdEd25519.PublicKeySize != len(x) {
+			return nil, errors.New("malformed Ed25519 JWK")
+		}
+		if 0 == len(d) {
+			return ed25519.PublicKey(x), nil
+		}
+		if stdEd25519.SeedSize != len(d) {
+			return nil, errors.New("malformed Ed25519 JWK")
+		}
+		priv := stdEd25519.NewKeyFromSeed(d)
+		if !bytes.Equal(x, priv.Public().(stdEd25519.PublicKey)) {
+			return nil, errors.New("mismatched Ed25519 JWK")
+		}
+		return fromStandard(priv)
+	}
+
+	if "EC" != k.Kty {
+		return nil, errors.New("unsupported JWK type " + k.Kty)
+	}
+
+	var c elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		c = elliptic.P256()
+	case "P-521":
+		c = elliptic.P521()
+	case "secp256k1":
+		c = secp.S256()
+	default:
+		return nil, errors.New("unsupported JWK curve " + k.Crv)
+	}
+
+	y, err := b64.DecodeString(k.Y)
+	if nil != err {
+		return nil, err
+	}
+
+	pub := &stdEcdsa.PublicKey{Curve: c, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	if !c.IsOnCurve(pub.X, pub.Y) {
+		return nil, errors.New("JWK point not on " + k.Crv)
+	}
+	if 0 == len(d) {
+		return pub, nil
+	}
+
+	priv := &stdEcdsa.PrivateKey{PublicKey: *pub, D: new(big.Int).SetBytes(d)}
+	if x, y := c.ScalarBaseMult(d); (0 != x.Cmp(pub.X)) || (0 != y.Cmp(pub.Y)) {
+		return nil, errors.New("mismatched EC JWK")
+	}
+
+	return priv, nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	stdEd25519 "golang.org/x/crypto/ed25519"
+)
+
+// kinds of the blobs
+const (
+	kindPrivKey = "private key"
+	kindPubKey  = "public key"
+	kindSig     = "signature"
+)
+
+// blobInfo is what inspect decodes from an internal blob
+type blobInfo struct {
+	Format  string `json:"format"`
+	Version int    `json:"version,omitempty"`
+	Kind    string `json:"kind"`
+	Alg     string `json:"alg"`
+	Curve   string `json:"curve"`
+	Size    int    `json:"size"`
+}
+
+var errUnknownBlob = errors.New("unrecognized blob")
+
+// p1363SizeP521 is the size of the P1363 signatures of P-521, i.e. r||s of
+// 66 bytes each
+const p1363SizeP521 = 132
+
+// layout of the ecdsa keys, where public keys duplicate X as D
+type ecdsaBigInt struct {
+	D, X, Y *big.Int
+}
+
+// layout of the secp keys, which follows the version and the 2-byte bit size
+type secpPubKey struct {
+	X, Y *big.Int
+}
+
+type secpPrivKey struct {
+	PubKey secpPubKey
+	D      *big.Int
+}
+
+// ecdsa signature as DER
+type ecdsaSig struct {
+	R, S *big.Int
+}
+
+// sec1Curves maps the curve tags of the public keys to the curves
+var sec1Curves = map[byte]struct {
+	alg, name string
+	size      int
+}{
+	ec.CurveP256:      {"ecdsa256", "P-256", 32},
+	ec.CurveP521:      {"ecdsa512", "P-521", 66},
+	ec.CurveSecp256k1: {"secp256k1", "secp256k1", 32},
+}
+
+// ed25519Tags maps the tags of the Ed25519 variant signatures to the variants
+var ed25519Tags = map[byte]string{
+	1: "ed25519ph",
+	2: "ed25519ctx",
+}
+
+// sec1Size is the length of a SEC1 point of a size-byte field tagged as prefix
+func sec1Size(size int, prefix byte) int {
+	if 0x04 == prefix {
+		return 1 + 2*size
+	}
+
+	return 1 + size
+}
+
+// inspectBlob recognizes the blob produced by the marshallers
+func inspectBlob(blob []byte) (*blobInfo, error) {
+	if 2 > len(blob) {
+		return nil, errUnknownBlob
+	}
+	info := &blobInfo{Format: formatRaw, Version: int(blob[0]), Size: len(blob)}
+	body := blob[1:]
+
+	// the public keys of version 2 carry the curve before the SEC1 point
+	if 2 == blob[0] && 2 < len(body) && 0x02 <= body[1] && body[1] <= 0x04 {
+		if curve, ok := sec1Curves[body[0]]; ok && sec1Size(curve.size, body[1]) == len(body)-1 {
+			info.Kind = kindPubKey
+			info.Alg, info.Curve = curve.alg, curve.name
+			return info, nil
+		}
+	}
+
+	// the Ed25519ph and Ed25519ctx signatures carry their tag before the
+	// signature
+	if 2 == blob[0] && stdEd25519.SignatureSize+1 == len(body) {
+		if alg, ok := ed25519Tags[body[0]]; ok {
+			info.Kind = kindSig
+			info.Alg, info.Curve = alg, "edwards25519"
+			return info, nil
+		}
+	}
+
+	// the secp blobs carry the bit size before the ASN.1 sequence
+	if 3 < len(body) && 0x30 == body[2] {
+		bitSize := (int(body[0]) << 8) | int(body[1])
+		if 256 == bitSize {
+			info.Alg, info.Curve = "secp256k1", "secp256k1"
+
+			var priv secpPrivKey
+			var pub secpPubKey
+			if rest, err := asn1.Unmarshal(body[2:], &priv); nil == err && 0 == len(rest) {
+				info.Kind = kindPrivKey
+				return info, nil
+			}
+			if rest, err := asn1.Unmarshal(body[2:], &pub); nil == err && 0 == len(rest) {
+				info.Kind = kindPubKey
+				return info, nil
+			}
+		}
+	}
+
+	if 0x30 == body[0] {
+		var key ecdsaBigInt
+		if rest, err := asn1.Unmarshal(body, &key); nil == err && 0 == len(rest) {
+			for _, c := range []elliptic.Curve{elliptic.P256(), elliptic.P521()} {
+				if c.IsOnCurve(key.X, key.Y) {
+					info.Curve = c.Params().Name
+					info.Alg = map[string]string{"P-256": "ecdsa256", "P-521": "ecdsa512"}[info.Curve]
+					if 0 == key.D.Cmp(key.X) {
+						info.Kind = kindPubKey
+					} else {
+						info.Kind = kindPrivKey
+					}
+					return info, nil
+				}
+			}
+			return nil, errUnknownBlob
+		}
+
+		var sig ecdsaSig
+		if rest, err := asn1.Unmarshal(body, &sig); nil == err && 0 == len(rest) {
+			info.Kind = kindSig
+			// signatures of P-256 and secp256k1 share the same layout
+			if 256 < sig.R.BitLen() || 256 < sig.S.BitLen() {
+				info.Alg, info.Curve = "ecdsa512", "P-521"
+			} else {
+				info.Alg, info.Curve = "ecdsa256|secp256k1", "P-256|secp256k1"
+			}
+			return info, nil
+		}
+	}
+
+	info.Alg, info.Curve = "ed25519", "edwards25519"
+	switch len(body) {
+	case stdEd25519.PrivateKeySize + stdEd25519.PublicKeySize:
+		info.Kind = kindPrivKey
+	case stdEd25519.PublicKeySize:
+		info.Kind = kindPubKey
+	case stdEd25519.SignatureSize:
+		// Ed25519 signatures and the P1363 ones of P-256 and secp256k1 are
+		// all 64 random looking bytes
+		info.Kind = kindSig
+		info.Alg, info.Curve = "ed25519|ecdsa256|secp256k1", "edwards25519|P-256|secp256k1"
+	case p1363SizeP521:
+		info.Kind = kindSig
+		info.Alg, info.Curve = "ecdsa512", "P-521"
+	default:
+		return nil, errUnknownBlob
+	}
+
+	return info, nil
+}
@@ -0,0 +1,181 @@
+// Command gravity generates keys, signs and verifies digests with the
+// workers of the crypto/ec packages, and converts between key formats
+package main
+
+// Usage:
+//   gravity keygen  --alg ALG [--format FMT] [--out FILE]
+//   gravity pubkey  --key FILE [--alg ALG] [--format FMT] [--out FILE]
+//   gravity sign    --key FILE [--alg ALG] [--in FILE] [--hash HASH] [--format raw|hex] [--out FILE]
+//   gravity verify  --key FILE --sig FILE [--alg ALG] [--in FILE] [--hash HASH]
+//   gravity convert --in FILE --to FMT [--alg ALG] [--out FILE]
+//   gravity inspect [--in FILE]
+// where every subcommand accepts --json to print the results as JSON
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// exit codes for scripts
+const (
+	exitOK = iota
+	// exitInvalid means the signature doesn't decode or verify
+	exitInvalid
+	// exitUsage means the command line is malformed
+	exitUsage
+	// exitFailure means the inputs can't be read, decoded or processed
+	exitFailure
+)
+
+// errUsage is returned by the subcommands on malformed arguments
+var errUsage = errors.New("usage error")
+
+// env is the context of a subcommand
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	json   bool
+}
+
+type command struct {
+	summary string
+	run     func(e *env, args []string) (int, error)
+}
+
+var commands = map[string]command{
+	"keygen":  {"generate a key pair", runKeygen},
+	"pubkey":  {"extract the public key of a private key", runPubkey},
+	"sign":    {"sign the digest of a message", runSign},
+	"verify":  {"verify a signature on the digest of a message", runVerify},
+	"convert": {"convert a key between raw, hex, pem and jwk", runConvert},
+	"inspect": {"decode the version, algorithm and curve of a blob", runInspect},
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: gravity <command> [flags]")
+	fmt.Fprintln(w)
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-8s %s\n", name, commands[name].summary)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "run `gravity <command> --help` for the flags of a command")
+}
+
+// run executes the command line and returns the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if 0 == len(args) {
+		usage(stderr)
+		return exitUsage
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		if "help" == args[0] || "-h" == args[0] || "--help" == args[0] {
+			usage(stdout)
+			return exitOK
+		}
+		fmt.Fprintf(stderr, "gravity: unknown command %q\n", args[0])
+		usage(stderr)
+		return exitUsage
+	}
+
+	e := &env{stdin: stdin, stdout: stdout, stderr: stderr}
+	code, err := cmd.run(e, args[1:])
+	if nil != err && errUsage != err && flag.ErrHelp != err {
+		if e.json {
+			e.print(map[string]string{"error": err.Error()})
+		} else {
+			fmt.Fprintf(stderr, "gravity %s: %v\n", args[0], err)
+		}
+	}
+
+	return code
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// newFlagSet makes the flag set of a subcommand with the common --json flag
+func (e *env) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("gravity "+name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.BoolVar(&e.json, "json", false, "print the results as JSON")
+
+	return fs
+}
+
+// parse parses args, and maps the errors to the exit code
+func parse(fs *flag.FlagSet, args []string) (int, error) {
+	if err := fs.Parse(args); nil != err {
+		if flag.ErrHelp == err {
+			return exitOK, err
+		}
+		return exitUsage, errUsage
+	}
+	if 0 != fs.NArg() {
+		fmt.Fprintf(fs.Output(), "unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		return exitUsage, errUsage
+	}
+
+	return exitOK, nil
+}
+
+// field is a line of the human output
+type field struct {
+	name  string
+	value interface{}
+}
+
+// print writes v as JSON, or as the "name: value" lines of fields
+func (e *env) print(v interface{}, fields ...field) {
+	if e.json {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(v)
+		return
+	}
+
+	for _, f := range fields {
+		fmt.Fprintf(e.stdout, "%s: %v\n", f.name, f.value)
+	}
+}
+
+// readInput reads the file at path, or stdin if path is empty or "-"
+func (e *env) readInput(path string) ([]byte, error) {
+	if "" == path || "-" == path {
+		return ioutil.ReadAll(e.stdin)
+	}
+
+	return ioutil.ReadFile(path)
+}
+
+// writeOutput writes data to the file at path, or stdout if path is empty or "-"
+func (e *env) writeOutput(path string, data []byte, private bool) error {
+	if "" == path || "-" == path {
+		_, err := e.stdout.Write(data)
+		return err
+	}
+
+	perm := os.FileMode(0644)
+	if private {
+		perm = 0600
+	}
+
+	return ioutil.WriteFile(path, data, perm)
+}
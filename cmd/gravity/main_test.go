@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+)
+
+// gravity runs the command line and returns the exit code and stdout
+func gravity(t *testing.T, stdin string, args ...string) (int, string) {
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	code := run(args, strings.NewReader(stdin), stdout, stderr)
+	if exitOK != code {
+		t.Logf("gravity %s: %s", strings.Join(args, " "), stderr)
+	}
+
+	return code, stdout.String()
+}
+
+func TestSignAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	msg := filepath.Join(dir, "msg")
+	if err := ioutil.WriteFile(msg, []byte("Hello World"), 0644); nil != err {
+		t.Fatal(err)
+	}
+
+	for _, alg := range algorithms {
+		for _, format := range formats {
+			priv := filepath.Join(dir, alg+"."+format)
+			pub := priv + ".pub"
+			sig := priv + ".sig"
+
+			if code, _ := gravity(t, "", "keygen", "--alg", alg, "--format", format, "--out", priv); exitOK != code {
+				t.Fatalf("%s/%s: keygen exits with %d", alg, format, code)
+			}
+			if code, _ := gravity(t, "", "pubkey", "--key", priv, "--format", format, "--out", pub); exitOK != code {
+				t.Fatalf("%s/%s: pubkey exits with %d", alg, format, code)
+			}
+			if code, _ := gravity(t, "", "sign", "--key", priv, "--in", msg, "--out", sig); exitOK != code {
+				t.Fatalf("%s/%s: sign exits with %d", alg, format, code)
+			}
+
+			if code, _ := gravity(t, "Hello World", "verify", "--key", pub, "--sig", sig); exitOK != code {
+				t.Fatalf("%s/%s: verify exits with %d", alg, format, code)
+			}
+			if code, _ := gravity(t, "Hello Gravity", "verify", "--key", pub, "--sig", sig); exitInvalid != code {
+				t.Fatalf("%s/%s: verify of a corrupted message exits with %d", alg, format, code)
+			}
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, alg := range algorithms {
+		priv := filepath.Join(dir, alg)
+		if code, _ := gravity(t, "", "keygen", "--alg", alg, "--format", formatRaw, "--out", priv); exitOK != code {
+			t.Fatalf("%s: keygen exits with %d", alg, code)
+		}
+		_, want := gravity(t, "", "pubkey", "--key", priv, "--json")
+
+		// convert through every format and back to raw
+		from := priv
+		for _, format := range append(formats, formatRaw) {
+			to := filepath.Join(dir, alg+"-to-"+format)
+			if code, _ := gravity(t, "", "convert", "--in", from, "--to", format, "--out", to); exitOK != code {
+				t.Fatalf("%s: convert to %s exits with %d", alg, format, code)
+			}
+			from = to
+		}
+
+		if _, got := gravity(t, "", "pubkey", "--key", from, "--json"); want != got {
+			t.Fatalf("%s: want %s, got %s", alg, want, got)
+		}
+
+		original, _ := ioutil.ReadFile(priv)
+		converted, _ := ioutil.ReadFile(from)
+		if !bytes.Equal(original, converted) {
+			t.Fatalf("%s: the round trip changes the key", alg)
+		}
+	}
+}
+
+func TestInspect(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, alg := range algorithms {
+		priv := filepath.Join(dir, alg)
+		if code, _ := gravity(t, "", "keygen", "--alg", alg, "--out", priv); exitOK != code {
+			t.Fatalf("%s: keygen exits with %d", alg, code)
+		}
+		_, pub := gravity(t, "", "pubkey", "--key", priv)
+		_, sig := gravity(t, "Hello World", "sign", "--key", priv)
+
+		blobs := map[string]string{kindPubKey: pub, kindSig: sig}
+		blobs[kindPrivKey], _ = func() (string, error) {
+			data, err := ioutil.ReadFile(priv)
+			return string(data), err
+		}()
+
+		for kind, blob := range blobs {
+			code, out := gravity(t, blob, "inspect", "--json")
+			if exitOK != code {
+				t.Fatalf("%s: inspect of %s exits with %d", alg, kind, code)
+			}
+
+			var info blobInfo
+			if err := json.Unmarshal([]byte(out), &info); nil != err {
+				t.Fatal(err)
+			}
+			// the ecdsa public keys are SEC1 points since version 2
+			version := 1
+			if (kindPubKey == kind) && ("ed25519" != alg) {
+				version = 2
+			}
+			if (version != info.Version) || (kind != info.Kind) || !strings.Contains(info.Alg, alg) {
+				t.Fatalf("%s: unexpected info of %s: %+v", alg, kind, info)
+			}
+		}
+	}
+}
+
+func TestInspectVariantSig(t *testing.T) {
+	priv, err := new(ed25519.Worker).GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	digest := sha512.Sum512([]byte("Hello World"))
+
+	workers := map[string]interface {
+		ec.Worker
+		MarshalSig(sig ec.Sig) ([]byte, error)
+	}{
+		"ed25519ph":  new(ed25519.WorkerPh),
+		"ed25519ctx": &ed25519.WorkerCtx{Context: "gravity"},
+	}
+	for alg, worker := range workers {
+		sig, err := worker.Sign(priv, digest[:])
+		if nil != err {
+			t.Fatal(err)
+		}
+		blob, err := worker.MarshalSig(sig)
+		if nil != err {
+			t.Fatal(err)
+		}
+
+		code, out := gravity(t, hex.EncodeToString(blob), "inspect", "--json")
+		if exitOK != code {
+			t.Fatalf("%s: inspect exits with %d", alg, code)
+		}
+		var info blobInfo
+		if err := json.Unmarshal([]byte(out), &info); nil != err {
+			t.Fatal(err)
+		}
+		if (2 != info.Version) || (kindSig != info.Kind) || (alg != info.Alg) {
+			t.Fatalf("%s: unexpected info %+v", alg, info)
+		}
+	}
+}
+
+func TestInspectP1363Sig(t *testing.T) {
+	digest := sha512.Sum512_256([]byte("Hello World"))
+
+	p256 := new(ecdsa.Worker256)
+	p256.Encoding = ecdsa.EncodingP1363
+	p521 := new(ecdsa.Worker512)
+	p521.Encoding = ecdsa.EncodingP1363
+	testCases := []struct {
+		worker interface {
+			ec.Worker
+			MarshalSig(sig ec.Sig) ([]byte, error)
+		}
+		alg string
+	}{
+		{p256, "ed25519|ecdsa256|secp256k1"},
+		{p521, "ecdsa512"},
+	}
+
+	for i, c := range testCases {
+		priv, err := c.worker.GenerateKey(rand.Reader)
+		if nil != err {
+			t.Fatal(err)
+		}
+		sig, err := c.worker.Sign(priv, digest[:])
+		if nil != err {
+			t.Fatal(err)
+		}
+		blob, err := c.worker.MarshalSig(sig)
+		if nil != err {
+			t.Fatal(err)
+		}
+
+		code, out := gravity(t, hex.EncodeToString(blob), "inspect", "--json")
+		if exitOK != code {
+			t.Fatalf("#%d: inspect exits with %d", i, code)
+		}
+		var info blobInfo
+		if err := json.Unmarshal([]byte(out), &info); nil != err {
+			t.Fatal(err)
+		}
+		if (1 != info.Version) || (kindSig != info.Kind) || (c.alg != info.Alg) {
+			t.Fatalf("#%d: unexpected info %+v", i, info)
+		}
+	}
+}
+
+func TestVerifyMalformedSig(t *testing.T) {
+	dir := t.TempDir()
+	sigs := map[string]string{}
+	for _, alg := range []string{"ed25519", "secp256k1"} {
+		priv := filepath.Join(dir, alg)
+		if code, _ := gravity(t, "", "keygen", "--alg", alg, "--out", priv); exitOK != code {
+			t.Fatalf("%s: keygen exits with %d", alg, code)
+		}
+		sigs[alg] = priv + ".sig"
+		if code, _ := gravity(t, "Hello World", "sign", "--key", priv, "--out", sigs[alg]); exitOK != code {
+			t.Fatalf("%s: sign exits with %d", alg, code)
+		}
+	}
+
+	garbage := filepath.Join(dir, "garbage.sig")
+	if err := ioutil.WriteFile(garbage, []byte("not a signature"), 0644); nil != err {
+		t.Fatal(err)
+	}
+
+	// the signatures of the other algorithm and the garbage don't decode
+	key := filepath.Join(dir, "ed25519")
+	for i, sig := range []string{sigs["secp256k1"], garbage} {
+		code, out := gravity(t, "Hello World", "verify", "--key", key, "--sig", sig, "--json")
+		if exitInvalid != code || !strings.Contains(out, `"valid": false`) {
+			t.Fatalf("#%d: want %d with an invalid result, got %d %s", i, exitInvalid, code, out)
+		}
+	}
+
+	// the unreadable signature is still a failure
+	missing := filepath.Join(dir, "missing.sig")
+	if code, _ := gravity(t, "Hello World", "verify", "--key", key, "--sig", missing); exitFailure != code {
+		t.Fatalf("want %d, got %d", exitFailure, code)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	if code, _ := gravity(t, ""); exitUsage != code {
+		t.Fatalf("want %d, got %d", exitUsage, code)
+	}
+	if code, _ := gravity(t, "", "unknown"); exitUsage != code {
+		t.Fatalf("want %d, got %d", exitUsage, code)
+	}
+	if code, _ := gravity(t, "", "keygen", "--alg", "rsa"); exitUsage != code {
+		t.Fatalf("want %d, got %d", exitUsage, code)
+	}
+	if code, _ := gravity(t, "", "sign", "--key", filepath.Join(t.TempDir(), "missing")); exitFailure != code {
+		t.Fatalf("want %d, got %d", exitFailure, code)
+	}
+
+	code, out := gravity(t, "not a key", "inspect", "--json")
+	if exitFailure != code || !strings.Contains(out, `"error"`) {
+		t.Fatalf("want %d with a JSON error, got %d %s", exitFailure, code, out)
+	}
+}
@@ -0,0 +1,152 @@
+// Package adaptor implements the adaptor signatures over secp256k1, i.e.
+// pre-signatures encrypted under an adaptor point T = tG, which only the
+// secret t completes into a valid signature, and which reveal t once the
+// signature is published
+package adaptor
+
+// Note:
+// + the adaptor secret t and point T are a *secp.PrivateKey and its public
+//   key, so they are generated by secp.Worker as any other key
+// + Schnorr completes into BIP-340 signatures verified by secp.VerifySchnorr,
+//   and ECDSA into DER signatures of low s verified by secp.Worker
+// + an atomic swap locks both legs under the same T, so that claiming one
+//   leg with its completed signature hands the secret to the other party
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	localECDSA "github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+var (
+	// ErrInvalidPreSig indicates the bytes aren't a well-formed pre-signature
+	ErrInvalidPreSig = errors.New("adaptor: invalid pre-signature")
+	// ErrSecretMismatch indicates the secret doesn't match the adaptor point
+	// of the pre-signature
+	ErrSecretMismatch = errors.New("adaptor: the secret doesn't match the adaptor point")
+)
+
+// Scheme is a scheme of adaptor signatures
+type Scheme interface {
+	// PreSign makes the pre-signature of msg by priv encrypted under T
+	PreSign(priv *secp.PrivateKey, msg []byte, T *secp.PublicKey) ([]byte, error)
+	// PreVerify checks preSig is a pre-signature of msg by pub under T, which
+	// the secret of T completes into a valid signature
+	PreVerify(pub *secp.PublicKey, msg []byte, T *secp.PublicKey, preSig []byte) bool
+	// Adapt completes preSig into a signature with the secret t of its
+	// adaptor point
+	Adapt(preSig []byte, t *secp.PrivateKey) (ec.Sig, error)
+	// Extract recovers the secret of T from the pre-signature preSig and the
+	// signature sig completed from it
+	Extract(preSig []byte, sig ec.Sig, T *secp.PublicKey) (*secp.PrivateKey, error)
+	// Verify verifies the completed signature sig of msg by pub
+	Verify(pub *secp.PublicKey, msg []byte, sig ec.Sig) bool
+}
+
+// the schemes of adaptor signatures
+var (
+	// Schnorr completes into BIP-340 signatures
+	Schnorr Scheme = schnorrScheme{}
+	// ECDSA completes into ECDSA signatures, its messages being digests
+	ECDSA Scheme = ecdsaScheme{}
+)
+
+// the byte size of the compressed points and of the scalars
+const (
+	pointSize  = 33
+	scalarSize = 32
+)
+
+// curve is the secp256k1 curve of all the schemes
+var curve = secp.S256()
+
+// point is a point of the curve, the point at infinity being (0, 0)
+type point struct {
+	X, Y *big.Int
+}
+
+func baseMult(k *big.Int) point {
+	x, y := curve.ScalarBaseMult(k.Bytes())
+	return point{x, y}
+}
+
+func (p point) mult(k *big.Int) point {
+	x, y := curve.ScalarMult(p.X, p.Y, k.Bytes())
+	return point{x, y}
+}
+
+func (p point) add(q point) point {
+	x, y := curve.Add(p.X, p.Y, q.X, q.Y)
+	return point{x, y}
+}
+
+func (p point) neg() point {
+	return point{p.X, new(big.Int).Sub(curve.Params().P, p.Y)}
+}
+
+func (p point) equal(q point) bool {
+	return (0 == p.X.Cmp(q.X)) && (0 == p.Y.Cmp(q.Y))
+}
+
+// bytes compresses p as SEC1, failing on the point at infinity
+func (p point) bytes() ([]byte, error) {
+	return localECDSA.MarshalSEC1(&secp.PublicKey{Curve: curve, X: p.X, Y: p.Y}, true)
+}
+
+// decodePoint decompresses the SEC1 point data
+func decodePoint(data []byte) (point, bool) {
+	if (pointSize != len(data)) || ((0x02 != data[0]) && (0x03 != data[0])) {
+		return point{}, false
+	}
+
+	pub, err := localECDSA.UnmarshalSEC1(curve, data)
+	if nil != err {
+		return point{}, false
+	}
+
+	return point{pub.X, pub.Y}, true
+}
+
+// pubPoint validates the public key pub of secp256k1
+func pubPoint(pub *secp.PublicKey) (point, error) {
+	if (nil == pub) || (nil == pub.Curve) || !secp.IsS256(pub.Curve) {
+		return point{}, ec.ErrKeyTampered
+	}
+	if err := secp.Validate(pub); nil != err {
+		return point{}, err
+	}
+
+	return point{pub.X, pub.Y}, nil
+}
+
+// secretScalar validates the private key priv of secp256k1
+func secretScalar(priv *secp.PrivateKey) (*big.Int, error) {
+	if (nil == priv) || (nil == priv.D) || (nil == priv.Curve) || !secp.IsS256(priv.Curve) {
+		return nil, ec.ErrKeyTampered
+	}
+	if (priv.D.Sign() <= 0) || (priv.D.Cmp(curve.Params().N) >= 0) {
+		return nil, ec.ErrInvalidKey
+	}
+
+	return priv.D, nil
+}
+
+// decodeScalar decodes the big-endian scalar data, which must be below n
+func decodeScalar(data []byte) (*big.Int, bool) {
+	s := new(big.Int).SetBytes(data)
+
+	return s, s.Cmp(curve.Params().N) < 0
+}
+
+// newSecret wraps the scalar t as a private key
+func newSecret(t *big.Int) *secp.PrivateKey {
+	priv := new(secp.PrivateKey)
+	priv.Curve = curve
+	priv.D = t
+	priv.X, priv.Y = curve.ScalarBaseMult(t.Bytes())
+
+	return priv
+}
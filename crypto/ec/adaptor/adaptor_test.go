@@ -0,0 +1,97 @@
+package adaptor_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec/adaptor"
+)
+
+var schemes = []struct {
+	name   string
+	scheme adaptor.Scheme
+}{
+	{"schnorr", adaptor.Schnorr},
+	{"ecdsa", adaptor.ECDSA},
+}
+
+func TestPreSignNotASignature(t *testing.T) {
+	msg := sha256.Sum256([]byte("hello"))
+
+	for _, s := range schemes {
+		priv, secret := generateKey(t), generateKey(t)
+		T := &secret.PublicKey
+
+		preSig, err := s.scheme.PreSign(priv, msg[:], T)
+		if nil != err {
+			t.Fatal(err)
+		}
+
+		// the pre-signature is bound to the key, the message and T
+		other := generateKey(t)
+		if s.scheme.PreVerify(&other.PublicKey, msg[:], T, preSig) {
+			t.Fatalf("%s: the pre-signature passes for another key", s.name)
+		}
+		if s.scheme.PreVerify(&priv.PublicKey, msg[1:], T, preSig) {
+			t.Fatalf("%s: the pre-signature passes for another message", s.name)
+		}
+		if s.scheme.PreVerify(&priv.PublicKey, msg[:], &other.PublicKey, preSig) {
+			t.Fatalf("%s: the pre-signature passes for another adaptor point", s.name)
+		}
+		for j := range preSig {
+			tampered := append([]byte(nil), preSig...)
+			tampered[j] ^= 0x01
+			if s.scheme.PreVerify(&priv.PublicKey, msg[:], T, tampered) {
+				t.Fatalf("%s: the pre-signature tampered at %d passes", s.name, j)
+			}
+		}
+
+		// a wrong secret makes no valid signature
+		if sig, err := s.scheme.Adapt(preSig, other); (nil == err) && s.scheme.Verify(&priv.PublicKey, msg[:], sig) {
+			t.Fatalf("%s: the pre-signature is completed by a wrong secret", s.name)
+		}
+
+		sig, err := s.scheme.Adapt(preSig, secret)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if !s.scheme.Verify(&priv.PublicKey, msg[:], sig) {
+			t.Fatalf("%s: the verification shouldn't fail", s.name)
+		}
+
+		// the secret only comes out for its own point
+		if _, err := s.scheme.Extract(preSig, sig, &other.PublicKey); adaptor.ErrSecretMismatch != err {
+			t.Fatalf("%s: want %v, got %v", s.name, adaptor.ErrSecretMismatch, err)
+		}
+		if _, err := s.scheme.Extract(preSig[1:], sig, T); adaptor.ErrInvalidPreSig != err {
+			t.Fatalf("%s: want %v, got %v", s.name, adaptor.ErrInvalidPreSig, err)
+		}
+	}
+}
+
+func TestExtractFromAnotherSignature(t *testing.T) {
+	msg := sha256.Sum256([]byte("hello"))
+
+	for _, s := range schemes {
+		priv, secret := generateKey(t), generateKey(t)
+		T := &secret.PublicKey
+
+		preA, err := s.scheme.PreSign(priv, msg[:], T)
+		if nil != err {
+			t.Fatal(err)
+		}
+		preB, err := s.scheme.PreSign(priv, msg[:], T)
+		if nil != err {
+			t.Fatal(err)
+		}
+		sigB, err := s.scheme.Adapt(preB, secret)
+		if nil != err {
+			t.Fatal(err)
+		}
+
+		// a signature completed from another pre-signature reveals nothing
+		if _, err := s.scheme.Extract(preA, sigB, T); adaptor.ErrSecretMismatch != err {
+			t.Fatalf("%s: want %v, got %v", s.name, adaptor.ErrSecretMismatch, err)
+		}
+	}
+}
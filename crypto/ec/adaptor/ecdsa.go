@@ -0,0 +1,277 @@
+package adaptor
+
+// Note:
+// + the pre-signature is R || R' || s' || e || z of 162 bytes, where R = kT
+//   and R' = kG are compressed, s' = k^-1(h + r*d) with r = R.X mod n, and
+//   (e, z) proves log_G(R') = log_T(R) as Chaum-Pedersen
+// + the secret completes it as s = s'/t, since s^-1(hG + rP) = t*kG = R,
+//   which is then normalized to a low s
+// + Extract tries t = s'/s and its negation, as the normalization may have
+//   negated s
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	localECDSA "github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// ecdsaPreSigSize is the byte size of the ECDSA pre-signatures
+const ecdsaPreSigSize = 2*pointSize + 3*scalarSize
+
+type ecdsaScheme struct{}
+
+// ecdsaPreSig is a decoded ECDSA pre-signature
+type ecdsaPreSig struct {
+	R, RG point
+	s     *big.Int
+	e, z  *big.Int
+	// the encodings of R and R'
+	rBytes, rgBytes []byte
+}
+
+// PreSign makes the pre-signature of the digest msg by priv encrypted under T
+func (ecdsaScheme) PreSign(priv *secp.PrivateKey, msg []byte, T *secp.PublicKey) ([]byte, error) {
+	d, err := secretScalar(priv)
+	if nil != err {
+		return nil, err
+	}
+	adaptor, err := pubPoint(T)
+	if nil != err {
+		return nil, err
+	}
+	n := curve.Params().N
+
+	for {
+		k, err := randScalar()
+		if nil != err {
+			return nil, err
+		}
+
+		R, RG := adaptor.mult(k), baseMult(k)
+		r := new(big.Int).Mod(R.X, n)
+		if 0 == r.Sign() {
+			continue
+		}
+
+		// s' = k^-1(h + r*d)
+		s := new(big.Int).Mul(r, d)
+		s.Add(s, hashToInt(msg)).Mod(s, n)
+		s.Mul(s, new(big.Int).ModInverse(k, n)).Mod(s, n)
+		if 0 == s.Sign() {
+			continue
+		}
+
+		rBytes, err := R.bytes()
+		if nil != err {
+			return nil, err
+		}
+		rgBytes, err := RG.bytes()
+		if nil != err {
+			return nil, err
+		}
+
+		// prove log_G(R') = log_T(R) = k
+		a, err := randScalar()
+		if nil != err {
+			return nil, err
+		}
+		e, err := dleqChallenge(adaptor, rgBytes, rBytes, baseMult(a), adaptor.mult(a))
+		if nil != err {
+			return nil, err
+		}
+		z := new(big.Int).Mul(e, k)
+		z.Add(z, a).Mod(z, n)
+
+		preSig := make([]byte, 0, ecdsaPreSigSize)
+		preSig = append(append(preSig, rBytes...), rgBytes...)
+		for _, v := range []*big.Int{s, e, z} {
+			preSig = append(preSig, v.FillBytes(make([]byte, scalarSize))...)
+		}
+
+		return preSig, nil
+	}
+}
+
+// PreVerify checks the proof of R and that s'R' = hG + rP
+func (ecdsaScheme) PreVerify(pub *secp.PublicKey, msg []byte, T *secp.PublicKey, preSig []byte) bool {
+	p, ok := decodeECDSAPreSig(preSig)
+	if !ok {
+		return false
+	}
+	adaptor, err := pubPoint(T)
+	if nil != err {
+		return false
+	}
+	P, err := pubPoint(pub)
+	if nil != err {
+		return false
+	}
+	n := curve.Params().N
+
+	// A1 = zG - eR' and A2 = zT - eR
+	negE := new(big.Int).Sub(n, p.e)
+	A1 := baseMult(p.z).add(p.RG.mult(negE))
+	A2 := adaptor.mult(p.z).add(p.R.mult(negE))
+	e, err := dleqChallenge(adaptor, p.rgBytes, p.rBytes, A1, A2)
+	if (nil != err) || (0 != e.Cmp(p.e)) {
+		return false
+	}
+
+	r := new(big.Int).Mod(p.R.X, n)
+	want := baseMult(hashToInt(msg)).add(P.mult(r))
+
+	return p.RG.mult(p.s).equal(want)
+}
+
+// Adapt completes preSig into the DER signature (r, s'/t) of low s
+func (ecdsaScheme) Adapt(preSig []byte, t *secp.PrivateKey) (ec.Sig, error) {
+	p, ok := decodeECDSAPreSig(preSig)
+	if !ok {
+		return nil, ErrInvalidPreSig
+	}
+	secret, err := secretScalar(t)
+	if nil != err {
+		return nil, err
+	}
+	n := curve.Params().N
+
+	// R = kT = t*R' must hold for the secret of T
+	if !p.RG.mult(secret).equal(p.R) {
+		return nil, ErrSecretMismatch
+	}
+
+	s := new(big.Int).ModInverse(secret, n)
+	s.Mul(s, p.s).Mod(s, n)
+
+	sig := make([]byte, 2*scalarSize)
+	new(big.Int).Mod(p.R.X, n).FillBytes(sig[:scalarSize])
+	s.FillBytes(sig[scalarSize:])
+
+	der, err := localECDSA.FromP1363(curve, sig)
+	if nil != err {
+		return nil, err
+	}
+
+	return localECDSA.NormalizeSig(curve, der)
+}
+
+// Extract recovers t = s'/s, or its negation
+func (ecdsaScheme) Extract(preSig []byte, sig ec.Sig, T *secp.PublicKey) (*secp.PrivateKey, error) {
+	p, ok := decodeECDSAPreSig(preSig)
+	if !ok {
+		return nil, ErrInvalidPreSig
+	}
+	adaptor, err := pubPoint(T)
+	if nil != err {
+		return nil, err
+	}
+	raw, err := localECDSA.ToP1363(curve, sig)
+	if nil != err {
+		return nil, err
+	}
+	n := curve.Params().N
+
+	if 0 != new(big.Int).Mod(p.R.X, n).Cmp(new(big.Int).SetBytes(raw[:scalarSize])) {
+		return nil, ErrSecretMismatch
+	}
+	s, ok := decodeScalar(raw[scalarSize:])
+	if !ok || (0 == s.Sign()) {
+		return nil, ec.ErrMalformedSig
+	}
+
+	t := new(big.Int).ModInverse(s, n)
+	t.Mul(t, p.s).Mod(t, n)
+	if baseMult(t).equal(adaptor) {
+		return newSecret(t), nil
+	}
+	if t.Sub(n, t); baseMult(t).equal(adaptor) {
+		return newSecret(t), nil
+	}
+
+	return nil, ErrSecretMismatch
+}
+
+// Verify verifies the DER signature sig of the digest msg by pub
+func (ecdsaScheme) Verify(pub *secp.PublicKey, msg []byte, sig ec.Sig) bool {
+	return secp.New().Verify(pub, msg, sig)
+}
+
+func decodeECDSAPreSig(preSig []byte) (*ecdsaPreSig, bool) {
+	if ecdsaPreSigSize != len(preSig) {
+		return nil, false
+	}
+
+	p := &ecdsaPreSig{rBytes: preSig[:pointSize], rgBytes: preSig[pointSize : 2*pointSize]}
+
+	var ok bool
+	if p.R, ok = decodePoint(p.rBytes); !ok {
+		return nil, false
+	}
+	if p.RG, ok = decodePoint(p.rgBytes); !ok {
+		return nil, false
+	}
+
+	scalars := preSig[2*pointSize:]
+	for i, v := range []**big.Int{&p.s, &p.e, &p.z} {
+		if *v, ok = decodeScalar(scalars[i*scalarSize : (i+1)*scalarSize]); !ok {
+			return nil, false
+		}
+	}
+	if 0 == p.s.Sign() {
+		return nil, false
+	}
+
+	return p, true
+}
+
+// dleqChallenge hashes the statement and the commitments of the proof
+func dleqChallenge(T point, rgBytes, rBytes []byte, A1, A2 point) (*big.Int, error) {
+	tBytes, err := T.bytes()
+	if nil != err {
+		return nil, err
+	}
+	a1, err := A1.bytes()
+	if nil != err {
+		return nil, err
+	}
+	a2, err := A2.bytes()
+	if nil != err {
+		return nil, err
+	}
+
+	e := new(big.Int).SetBytes(secp.TaggedHash("gravity/adaptor/DLEQ", tBytes, rgBytes, rBytes, a1, a2))
+
+	return e.Mod(e, curve.Params().N), nil
+}
+
+// randScalar draws a scalar in [1, n)
+func randScalar() (*big.Int, error) {
+	n1 := new(big.Int).Sub(curve.Params().N, big.NewInt(1))
+
+	k, err := rand.Int(rand.Reader, n1)
+	if nil != err {
+		return nil, err
+	}
+
+	return k.Add(k, big.NewInt(1)), nil
+}
+
+// hashToInt converts the digest to an integer as ECDSA, keeping its leftmost
+// bits up to the size of n
+func hashToInt(digest []byte) *big.Int {
+	orderBits := curve.Params().N.BitLen()
+	orderBytes := (orderBits + 7) / 8
+	if len(digest) > orderBytes {
+		digest = digest[:orderBytes]
+	}
+
+	h := new(big.Int).SetBytes(digest)
+	if excess := len(digest)*8 - orderBits; excess > 0 {
+		h.Rsh(h, uint(excess))
+	}
+
+	return h
+}
@@ -0,0 +1,198 @@
+package adaptor
+
+// Note:
+// + the pre-signature is R || s' of 65 bytes, where R = kG + T is compressed
+//   and s' = k + e*d, e being the BIP-340 challenge of R.X
+// + BIP-340 needs an even R, so an odd R negates k instead, and the secret
+//   then completes the signature as s = s' - t rather than s' + t
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// schnorrPreSigSize is the byte size of the Schnorr pre-signatures
+const schnorrPreSigSize = pointSize + scalarSize
+
+type schnorrScheme struct{}
+
+// PreSign makes the pre-signature of msg by priv encrypted under T
+func (schnorrScheme) PreSign(priv *secp.PrivateKey, msg []byte, T *secp.PublicKey) ([]byte, error) {
+	d, err := secretScalar(priv)
+	if nil != err {
+		return nil, err
+	}
+	adaptor, err := pubPoint(T)
+	if nil != err {
+		return nil, err
+	}
+	n := curve.Params().N
+
+	// the key of even Y as BIP-340
+	P := baseMult(d)
+	if 1 == P.Y.Bit(0) {
+		d = new(big.Int).Sub(n, d)
+	}
+	pub, err := secp.XOnly(&secp.PublicKey{Curve: curve, X: P.X, Y: P.Y})
+	if nil != err {
+		return nil, err
+	}
+	tBytes, err := adaptor.bytes()
+	if nil != err {
+		return nil, err
+	}
+
+	// the nonce mixes the scalar with fresh randomness, as BIP-340 does
+	aux := make([]byte, 32)
+	if _, err := rand.Read(aux); nil != err {
+		return nil, err
+	}
+	mixed := d.FillBytes(make([]byte, 32))
+	for i, b := range secp.TaggedHash("gravity/adaptor/aux", aux) {
+		mixed[i] ^= b
+	}
+	k := new(big.Int).SetBytes(secp.TaggedHash("gravity/adaptor/nonce", mixed, pub, tBytes, msg))
+	if k.Mod(k, n); 0 == k.Sign() {
+		return nil, ec.ErrInvalidKey
+	}
+
+	R := baseMult(k).add(adaptor)
+	rBytes, err := R.bytes()
+	if nil != err {
+		return nil, err
+	}
+	if 1 == R.Y.Bit(0) {
+		k.Sub(n, k)
+	}
+
+	e := schnorrChallenge(R, pub, msg)
+	s := e.Mul(e, d)
+	s.Add(s, k).Mod(s, n)
+
+	return append(rBytes, s.FillBytes(make([]byte, scalarSize))...), nil
+}
+
+// PreVerify checks s'G = R - T + eP for an even R, or s'G = T - R + eP for an
+// odd one
+func (schnorrScheme) PreVerify(pub *secp.PublicKey, msg []byte, T *secp.PublicKey, preSig []byte) bool {
+	R, s, ok := decodeSchnorrPreSig(preSig)
+	if !ok {
+		return false
+	}
+	adaptor, err := pubPoint(T)
+	if nil != err {
+		return false
+	}
+	x, err := secp.XOnly(pub)
+	if nil != err {
+		return false
+	}
+	P, err := secp.LiftX(x)
+	if nil != err {
+		return false
+	}
+
+	// R' = s'G - eP
+	e := schnorrChallenge(R, x, msg)
+	e.Sub(curve.Params().N, e)
+	got := baseMult(s).add(point{P.X, P.Y}.mult(e))
+
+	want := R.add(adaptor.neg())
+	if 1 == R.Y.Bit(0) {
+		want = want.neg()
+	}
+
+	return got.equal(want)
+}
+
+// Adapt completes preSig into the BIP-340 signature R.X || s
+func (schnorrScheme) Adapt(preSig []byte, t *secp.PrivateKey) (ec.Sig, error) {
+	R, s, ok := decodeSchnorrPreSig(preSig)
+	if !ok {
+		return nil, ErrInvalidPreSig
+	}
+	secret, err := secretScalar(t)
+	if nil != err {
+		return nil, err
+	}
+	n := curve.Params().N
+
+	if 1 == R.Y.Bit(0) {
+		s = new(big.Int).Sub(s, secret)
+	} else {
+		s = new(big.Int).Add(s, secret)
+	}
+	s.Mod(s, n)
+
+	sig := make([]byte, secp.SchnorrSigSize)
+	R.X.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	return sig, nil
+}
+
+// Extract recovers t = s - s', or s' - s for an odd R
+func (schnorrScheme) Extract(preSig []byte, sig ec.Sig, T *secp.PublicKey) (*secp.PrivateKey, error) {
+	R, preS, ok := decodeSchnorrPreSig(preSig)
+	if !ok {
+		return nil, ErrInvalidPreSig
+	}
+	adaptor, err := pubPoint(T)
+	if nil != err {
+		return nil, err
+	}
+	if secp.SchnorrSigSize != len(sig) {
+		return nil, ec.ErrMalformedSig
+	}
+	if 0 != R.X.Cmp(new(big.Int).SetBytes(sig[:32])) {
+		return nil, ErrSecretMismatch
+	}
+	s, ok := decodeScalar(sig[32:])
+	if !ok {
+		return nil, ec.ErrMalformedSig
+	}
+
+	t := new(big.Int).Sub(s, preS)
+	if 1 == R.Y.Bit(0) {
+		t.Neg(t)
+	}
+	t.Mod(t, curve.Params().N)
+
+	if (0 == t.Sign()) || !baseMult(t).equal(adaptor) {
+		return nil, ErrSecretMismatch
+	}
+
+	return newSecret(t), nil
+}
+
+// Verify verifies the BIP-340 signature sig of msg by pub
+func (schnorrScheme) Verify(pub *secp.PublicKey, msg []byte, sig ec.Sig) bool {
+	x, err := secp.XOnly(pub)
+
+	return (nil == err) && secp.VerifySchnorr(x, msg, sig)
+}
+
+func decodeSchnorrPreSig(preSig []byte) (point, *big.Int, bool) {
+	if schnorrPreSigSize != len(preSig) {
+		return point{}, nil, false
+	}
+
+	R, ok := decodePoint(preSig[:pointSize])
+	if !ok {
+		return point{}, nil, false
+	}
+	s, ok := decodeScalar(preSig[pointSize:])
+
+	return R, s, ok
+}
+
+// schnorrChallenge computes the BIP-340 challenge of the nonce point R
+func schnorrChallenge(R point, pub, msg []byte) *big.Int {
+	r := R.X.FillBytes(make([]byte, 32))
+	e := new(big.Int).SetBytes(secp.TaggedHash("BIP0340/challenge", r, pub, msg))
+
+	return e.Mod(e, curve.Params().N)
+}
@@ -0,0 +1,94 @@
+package adaptor_test
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec/adaptor"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+func generateKey(t *testing.T) *secp.PrivateKey {
+	priv, err := secp.New().GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	return priv.(*secp.PrivateKey)
+}
+
+// party is one side of the swap, owning coins on its chain
+type party struct {
+	key    *secp.PrivateKey
+	scheme adaptor.Scheme
+}
+
+// TestAtomicSwap simulates Alice trading her coins on one chain for the coins
+// of Bob on another, both spends being locked under the same adaptor point
+func TestAtomicSwap(t *testing.T) {
+	testCases := []struct {
+		alice, bob adaptor.Scheme
+	}{
+		{adaptor.Schnorr, adaptor.Schnorr},
+		{adaptor.ECDSA, adaptor.ECDSA},
+		{adaptor.Schnorr, adaptor.ECDSA},
+		{adaptor.ECDSA, adaptor.Schnorr},
+	}
+
+	for i, c := range testCases {
+		alice := party{generateKey(t), c.alice}
+		bob := party{generateKey(t), c.bob}
+
+		txA := sha256.Sum256([]byte("alice pays 1 BTC to bob"))
+		txB := sha256.Sum256([]byte("bob pays 30 ETH to alice"))
+
+		// Alice picks the secret, whose point locks both spends
+		secret := generateKey(t)
+		T := &secret.PublicKey
+
+		// each pre-signs the spend of its coins to the other
+		preA, err := alice.scheme.PreSign(alice.key, txA[:], T)
+		if nil != err {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+		preB, err := bob.scheme.PreSign(bob.key, txB[:], T)
+		if nil != err {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+
+		// and checks the pre-signature it receives before going on
+		if !bob.scheme.PreVerify(&bob.key.PublicKey, txB[:], T, preB) {
+			t.Fatalf("#%d: Alice refuses the pre-signature of Bob", i)
+		}
+		if !alice.scheme.PreVerify(&alice.key.PublicKey, txA[:], T, preA) {
+			t.Fatalf("#%d: Bob refuses the pre-signature of Alice", i)
+		}
+
+		// Alice claims the coins of Bob, publishing the completed signature
+		sigB, err := bob.scheme.Adapt(preB, secret)
+		if nil != err {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+		if !bob.scheme.Verify(&bob.key.PublicKey, txB[:], sigB) {
+			t.Fatalf("#%d: the chain of Bob refuses the spend", i)
+		}
+
+		// which tells Bob the secret to claim the coins of Alice
+		extracted, err := bob.scheme.Extract(preB, sigB, T)
+		if nil != err {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+		if 0 != extracted.D.Cmp(secret.D) {
+			t.Fatalf("#%d: want secret %x, got %x", i, secret.D, extracted.D)
+		}
+
+		sigA, err := alice.scheme.Adapt(preA, extracted)
+		if nil != err {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+		if !alice.scheme.Verify(&alice.key.PublicKey, txA[:], sigA) {
+			t.Fatalf("#%d: the chain of Alice refuses the spend", i)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+// Package address derives the blockchain addresses of the public keys made by
+// secp.Worker and ed25519.Worker, and parses and validates such addresses
+package address
+
+// Note:
+// + Ethereum addresses are the last 20 bytes of the Keccak-256 digest of the
+//   uncompressed point X||Y, in hex with the EIP-55 mixed-case checksum
+// + Bitcoin addresses are either Base58Check of the HASH160 of the compressed
+//   point (P2PKH), or the segwit programs in bech32 (P2WPKH, BIP-173) and
+//   bech32m (P2TR, BIP-350)
+// + P2TR addresses commit to the key only, i.e. the BIP-86 tweak without any
+//   script path
+// + Solana addresses are the 32-byte Ed25519 public keys in base58, and the
+//   program derived addresses are valid addresses off the curve on purpose
+
+import "errors"
+
+var (
+	// ErrMalformed indicates the address isn't well-formed in its encoding
+	ErrMalformed = errors.New("address: malformed address")
+	// ErrChecksum indicates the checksum of the address doesn't match
+	ErrChecksum = errors.New("address: checksum mismatch")
+	// ErrWrongNetwork indicates the address is of another network
+	ErrWrongNetwork = errors.New("address: the address is of another network")
+)
@@ -0,0 +1,91 @@
+package address
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"strings"
+)
+
+// the alphabet of Bitcoin, which Solana uses as well
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var bigRadix = big.NewInt(58)
+
+// Base58Encode encodes b in base58, where every leading zero byte is a '1'
+func Base58Encode(b []byte) string {
+	x := new(big.Int).SetBytes(b)
+	mod := new(big.Int)
+
+	out := make([]byte, 0, len(b)*138/100+1)
+	for 0 < x.Sign() {
+		x.DivMod(x, bigRadix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for _, c := range b {
+		if 0 != c {
+			break
+		}
+		out = append(out, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+
+	return string(out)
+}
+
+// Base58Decode decodes the base58 string s
+func Base58Decode(s string) ([]byte, error) {
+	x := new(big.Int)
+	for i := 0; i < len(s); i++ {
+		digit := strings.IndexByte(base58Alphabet, s[i])
+		if 0 > digit {
+			return nil, ErrMalformed
+		}
+		x.Mul(x, bigRadix)
+		x.Add(x, big.NewInt(int64(digit)))
+	}
+
+	zeros := 0
+	for zeros < len(s) && base58Alphabet[0] == s[zeros] {
+		zeros++
+	}
+
+	return append(make([]byte, zeros), x.Bytes()...), nil
+}
+
+// Base58CheckEncode encodes version || payload || checksum in base58, where
+// the checksum is the first 4 bytes of the double SHA-256 of the rest
+func Base58CheckEncode(version byte, payload []byte) string {
+	data := append([]byte{version}, payload...)
+
+	return Base58Encode(append(data, checksum(data)...))
+}
+
+// Base58CheckDecode decodes the Base58Check string s into its version and
+// payload
+func Base58CheckDecode(s string) (byte, []byte, error) {
+	data, err := Base58Decode(s)
+	if nil != err {
+		return 0, nil, err
+	}
+	if 5 > len(data) {
+		return 0, nil, ErrMalformed
+	}
+
+	sum := data[len(data)-4:]
+	data = data[:len(data)-4]
+	if string(checksum(data)) != string(sum) {
+		return 0, nil, ErrChecksum
+	}
+
+	return data[0], data[1:], nil
+}
+
+func checksum(data []byte) []byte {
+	h := sha256.Sum256(data)
+	h = sha256.Sum256(h[:])
+
+	return h[:4]
+}
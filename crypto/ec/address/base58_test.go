@@ -0,0 +1,82 @@
+package address_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec/address"
+)
+
+// the vectors of Bitcoin Core's base58_encode_decode.json
+var base58Vectors = []struct {
+	hex, encoded string
+}{
+	{"", ""},
+	{"61", "2g"},
+	{"626262", "a3gV"},
+	{"636363", "aPEr"},
+	{"73696d706c792061206c6f6e6720737472696e67", "2cFupjhnEsSn59qHXstmK2ffpLv2"},
+	{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+	{"516b6fcd0f", "ABnLTmg"},
+	{"bf4f89001e670274dd", "3SEo3LWLoPntC"},
+	{"572e4794", "3EFU7m"},
+	{"ecac89cad93923c02321", "EJDM8drfXA6uyA"},
+	{"10c8511e", "Rt5zm"},
+	{"00000000000000000000", "1111111111"},
+	{"000111d38e5fc9071ffcd20b4a763cc9ae4f252bb4e48fd66a835e252ada93ff480d6dd43dc62a641155a5", "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"},
+}
+
+func TestBase58(t *testing.T) {
+	for i, c := range base58Vectors {
+		data, _ := hex.DecodeString(c.hex)
+		if got := address.Base58Encode(data); c.encoded != got {
+			t.Fatalf("#%d: want %s, got %s", i, c.encoded, got)
+		}
+		if got, err := address.Base58Decode(c.encoded); (nil != err) || !bytes.Equal(data, got) {
+			t.Fatalf("#%d: want %x, got %x (%v)", i, data, got, err)
+		}
+	}
+
+	for i, s := range []string{"0", "O", "I", "l", "3mJr0", "O3yxU", "3sNI", "4kl8", "0OIl", "!@#$%^&*()-_=+~`", "abcd\xd80"} {
+		if _, err := address.Base58Decode(s); address.ErrMalformed != err {
+			t.Fatalf("#%d: want %v, got %v", i, address.ErrMalformed, err)
+		}
+	}
+}
+
+func TestBase58Check(t *testing.T) {
+	testCases := []struct {
+		version      byte
+		payload, out string
+	}{
+		{20, "", "3MNQE1X"},
+		{20, " ", "B2Kr6dBE"},
+		{20, "-", "B3jv1Aft"},
+		{20, "0", "B482yuaX"},
+		{20, "1", "B4CmeGAC"},
+		{20, "-1", "mM7eUf6kB"},
+		{20, "11", "mP7BMTDVH"},
+		{20, "abc", "4QiVtDjUdeq"},
+		{20, "1234598760", "ZmNb8uQn5zvnUohNCEPP"},
+		{20, "abcdefghijklmnopqrstuvwxyz", "K2RYDcKfupxwXdWhSAxQPCeiULntKm63UXyx5MvEH2"},
+	}
+
+	for i, c := range testCases {
+		if got := address.Base58CheckEncode(c.version, []byte(c.payload)); c.out != got {
+			t.Fatalf("#%d: want %s, got %s", i, c.out, got)
+		}
+
+		version, payload, err := address.Base58CheckDecode(c.out)
+		if (nil != err) || (c.version != version) || (c.payload != string(payload)) {
+			t.Fatalf("#%d: the round trip fails: %v", i, err)
+		}
+	}
+
+	if _, _, err := address.Base58CheckDecode("3MNQE1Y"); address.ErrChecksum != err {
+		t.Fatalf("want %v, got %v", address.ErrChecksum, err)
+	}
+	if _, _, err := address.Base58CheckDecode("3MNQE"); address.ErrMalformed != err {
+		t.Fatalf("want %v, got %v", address.ErrMalformed, err)
+	}
+}
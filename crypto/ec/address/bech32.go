@@ -0,0 +1,240 @@
+package address
+
+// Note:
+// + bech32 (BIP-173) and bech32m (BIP-350) only differ by the constant the
+//   checksum is xored with
+// + the data part is made of 5-bit groups, which ConvertBits regroups from
+//   and into bytes
+
+import "strings"
+
+// Bech32Variant selects the checksum of the bech32 strings
+type Bech32Variant int
+
+const (
+	// Bech32 is the checksum of BIP-173, used by the segwit version 0
+	Bech32 Bech32Variant = iota + 1
+	// Bech32m is the checksum of BIP-350, used by the segwit versions 1 to 16
+	Bech32m
+)
+
+const (
+	bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	bech32MaxLen  = 90
+)
+
+func (v Bech32Variant) constant() uint32 {
+	if Bech32m == v {
+		return 0x2bc830a3
+	}
+
+	return 1
+}
+
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+	chk := uint32(1)
+	for _, v := range values {
+		b := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if 1 == (b>>uint(i))&1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	out := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+
+	return out
+}
+
+// EncodeBech32 encodes the 5-bit groups data under the human-readable part
+// hrp with the checksum of variant v
+func EncodeBech32(hrp string, data []byte, v Bech32Variant) (string, error) {
+	if (0 == len(hrp)) || (bech32MaxLen < len(hrp)+1+len(data)+6) {
+		return "", ErrMalformed
+	}
+	for i := 0; i < len(hrp); i++ {
+		if (33 > hrp[i]) || (126 < hrp[i]) {
+			return "", ErrMalformed
+		}
+	}
+	hrp = strings.ToLower(hrp)
+
+	values := append(bech32HRPExpand(hrp), data...)
+	polymod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ v.constant()
+
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, d := range data {
+		if 31 < d {
+			return "", ErrMalformed
+		}
+		sb.WriteByte(bech32Charset[d])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(bech32Charset[(polymod>>uint(5*(5-i)))&31])
+	}
+
+	return sb.String(), nil
+}
+
+// DecodeBech32 decodes s into its lowercase human-readable part and its
+// 5-bit groups, telling which checksum it carries
+func DecodeBech32(s string) (string, []byte, Bech32Variant, error) {
+	if bech32MaxLen < len(s) {
+		return "", nil, 0, ErrMalformed
+	}
+
+	lower, upper := false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case (33 > c) || (126 < c):
+			return "", nil, 0, ErrMalformed
+		case ('a' <= c) && (c <= 'z'):
+			lower = true
+		case ('A' <= c) && (c <= 'Z'):
+			upper = true
+		}
+	}
+	if lower && upper {
+		return "", nil, 0, ErrMalformed
+	}
+	s = strings.ToLower(s)
+
+	sep := strings.LastIndexByte(s, '1')
+	if (1 > sep) || (sep+7 > len(s)) {
+		return "", nil, 0, ErrMalformed
+	}
+
+	hrp := s[:sep]
+	data := make([]byte, 0, len(s)-sep-1)
+	for i := sep + 1; i < len(s); i++ {
+		d := strings.IndexByte(bech32Charset, s[i])
+		if 0 > d {
+			return "", nil, 0, ErrMalformed
+		}
+		data = append(data, byte(d))
+	}
+
+	var v Bech32Variant
+	switch bech32Polymod(append(bech32HRPExpand(hrp), data...)) {
+	case Bech32.constant():
+		v = Bech32
+	case Bech32m.constant():
+		v = Bech32m
+	default:
+		return "", nil, 0, ErrChecksum
+	}
+
+	return hrp, data[:len(data)-6], v, nil
+}
+
+// ConvertBits regroups the fromBits-bit groups of data into toBits-bit ones,
+// padding the last group with zeros if pad is set, or otherwise refusing
+// more than fromBits-1 bits of padding or any non-zero padding
+func ConvertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	var acc, bits uint
+	maxv := uint(1)<<toBits - 1
+
+	out := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, d := range data {
+		if 0 != uint(d)>>fromBits {
+			return nil, ErrMalformed
+		}
+		acc = acc<<fromBits | uint(d)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte((acc>>bits)&maxv))
+		}
+	}
+
+	switch {
+	case pad && (0 < bits):
+		out = append(out, byte((acc<<(toBits-bits))&maxv))
+	case !pad && ((bits >= fromBits) || (0 != (acc<<(toBits-bits))&maxv)):
+		return nil, ErrMalformed
+	}
+
+	return out, nil
+}
+
+// EncodeSegWit encodes the witness program of version as a segwit address
+// under hrp, in bech32 for the version 0 and bech32m for the others
+func EncodeSegWit(hrp string, version byte, program []byte) (string, error) {
+	if err := checkWitness(version, program); nil != err {
+		return "", err
+	}
+
+	data, err := ConvertBits(program, 8, 5, true)
+	if nil != err {
+		return "", err
+	}
+
+	v := Bech32m
+	if 0 == version {
+		v = Bech32
+	}
+
+	return EncodeBech32(hrp, append([]byte{version}, data...), v)
+}
+
+// DecodeSegWit decodes the segwit address addr under hrp into its witness
+// version and program
+func DecodeSegWit(hrp, addr string) (byte, []byte, error) {
+	got, data, v, err := DecodeBech32(addr)
+	if nil != err {
+		return 0, nil, err
+	}
+	if strings.ToLower(hrp) != got {
+		return 0, nil, ErrWrongNetwork
+	}
+	if 1 > len(data) {
+		return 0, nil, ErrMalformed
+	}
+
+	version := data[0]
+	if ((0 == version) && (Bech32 != v)) || ((0 != version) && (Bech32m != v)) {
+		return 0, nil, ErrChecksum
+	}
+
+	program, err := ConvertBits(data[1:], 5, 8, false)
+	if nil != err {
+		return 0, nil, err
+	}
+	if err := checkWitness(version, program); nil != err {
+		return 0, nil, err
+	}
+
+	return version, program, nil
+}
+
+// checkWitness checks the length of the program of version
+func checkWitness(version byte, program []byte) error {
+	switch {
+	case 16 < version:
+		return ErrMalformed
+	case (2 > len(program)) || (40 < len(program)):
+		return ErrMalformed
+	case (0 == version) && (20 != len(program)) && (32 != len(program)):
+		return ErrMalformed
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package address_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec/address"
+)
+
+func TestBech32(t *testing.T) {
+	// the valid strings of BIP-173 and BIP-350
+	valid := map[address.Bech32Variant][]string{
+		address.Bech32: {
+			"A12UEL5L",
+			"a12uel5l",
+			"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs",
+			"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+			"11qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqc8247j",
+			"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w",
+			"?1ezyfcl",
+		},
+		address.Bech32m: {
+			"A1LQFN3A",
+			"a1lqfn3a",
+			"an83characterlonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11sg7hg6",
+			"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx",
+			"11llllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllludsr8",
+			"split1checkupstagehandshakeupstreamerranterredcaperredlc445v",
+			"?1v759aa",
+		},
+	}
+
+	for variant, vectors := range valid {
+		for i, s := range vectors {
+			hrp, data, v, err := address.DecodeBech32(s)
+			if (nil != err) || (variant != v) {
+				t.Fatalf("%d-#%d: want variant %d, got %d (%v)", variant, i, variant, v, err)
+			}
+
+			encoded, err := address.EncodeBech32(hrp, data, v)
+			if (nil != err) || (strings.ToLower(s) != encoded) {
+				t.Fatalf("%d-#%d: want %s, got %s (%v)", variant, i, strings.ToLower(s), encoded, err)
+			}
+		}
+	}
+
+	invalid := map[string]error{
+		"split1checkupstagehandshakeupstreamerranterredcaperred2y9e2w":    address.ErrChecksum,
+		"s lit1checkupstagehandshakeupstreamerranterredcaperredp8hs2p":    address.ErrMalformed,
+		"spl\x7ft1checkupstagehandshakeupstreamerranterredcaperred2y9e3w": address.ErrMalformed,
+		"split1cheo2y9e2w": address.ErrMalformed,
+		"split1a2y9w":      address.ErrMalformed,
+		"1checkupstagehandshakeupstreamerranterredcaperred2y9e3w":                                     address.ErrMalformed,
+		"11qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqsqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqc8247j": address.ErrMalformed,
+		"pzry9x0s0muk":  address.ErrMalformed,
+		"1pzry9x0s0muk": address.ErrMalformed,
+		"x1b4n0q5v":     address.ErrMalformed,
+		"li1dgmt3":      address.ErrMalformed,
+		"de1lg7wt\xff":  address.ErrMalformed,
+		"A1G7SGD8":      address.ErrChecksum,
+		"10a06t8":       address.ErrMalformed,
+		"1qzzfhee":      address.ErrMalformed,
+		"a12UEL5L":      address.ErrMalformed,
+		"M1VUXWEZ":      address.ErrChecksum,
+		"16plkw9":       address.ErrMalformed,
+		"1p2gdwpf":      address.ErrMalformed,
+	}
+	for s, expect := range invalid {
+		if _, _, _, err := address.DecodeBech32(s); expect != err {
+			t.Fatalf("%q: want %v, got %v", s, expect, err)
+		}
+	}
+}
+
+func TestSegWit(t *testing.T) {
+	// the valid segwit addresses of BIP-350 with their scriptPubKey
+	testCases := []struct {
+		addr, script string
+	}{
+		{"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", "0014751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", "00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262"},
+		{"bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kt5nd6y", "5128751e76e8199196d454941c45d1b3a323f1433bd6751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{"BC1SW50QGDZ25J", "6002751e"},
+		{"bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs", "5210751e76e8199196d454941c45d1b3a323"},
+		{"tb1qqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesrxh6hy", "0020000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433"},
+		{"tb1pqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesf3hn0c", "5120000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433"},
+		{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", "512079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"},
+	}
+
+	for i, c := range testCases {
+		hrp := strings.ToLower(c.addr[:2])
+		version, program, err := address.DecodeSegWit(hrp, c.addr)
+		if nil != err {
+			t.Fatalf("#%d: %v", i, err)
+		}
+
+		// scriptPubKey is OP_n || len(program) || program
+		script, _ := hex.DecodeString(c.script)
+		op := version
+		if 0 != op {
+			op += 0x50
+		}
+		if (op != script[0]) || (int(script[1]) != len(program)) || !bytes.Equal(script[2:], program) {
+			t.Fatalf("#%d: unexpected program %x of version %d", i, program, version)
+		}
+
+		encoded, err := address.EncodeSegWit(hrp, version, program)
+		if (nil != err) || (strings.ToLower(c.addr) != encoded) {
+			t.Fatalf("#%d: want %s, got %s (%v)", i, strings.ToLower(c.addr), encoded, err)
+		}
+	}
+
+	// the invalid segwit addresses of BIP-173 and BIP-350
+	invalid := []string{
+		"tc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq5zuyut",
+		"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd",
+		"tb1z0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqglt7rf",
+		"BC1S0XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ54WELL",
+		"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh",
+		"tb1q0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq24jc47",
+		"bc1p38j9r5y49hruaue7wxjce0updqjuyyx0kh56v8s25huc6995vvpql3jow4",
+		"BC130XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ7ZWS8R",
+		"bc1pw5dgrnzv",
+		"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7v8n0nx0muaewav253zgeav",
+		"BC1QR508D6QEJXTDG4Y5R3ZARVARYV98GJ9P",
+		"tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq47Zagq",
+		"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7v07qwwzcrf",
+		"tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vpggkg4j",
+		"bc1gmk9yu",
+		"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5",
+		"BC13W508D6QEJXTDG4Y5R3ZARVARY0C5XW7KN40WF2",
+		"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sL5k7",
+		"tb1pw508d6qejxtdg4y5r3zarqfsj6c3",
+		"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3pjxtptv",
+	}
+	for i, s := range invalid {
+		hrp := "bc"
+		if strings.HasPrefix(strings.ToLower(s), "tb") {
+			hrp = "tb"
+		}
+		if _, _, err := address.DecodeSegWit(hrp, s); nil == err {
+			t.Fatalf("#%d: %s should be refused", i, s)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package address
+
+import (
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// Network holds the prefixes of the Bitcoin addresses of a network
+type Network struct {
+	// PubKeyHashID and ScriptHashID are the version bytes of the P2PKH and
+	// P2SH addresses
+	PubKeyHashID, ScriptHashID byte
+	// HRP is the human-readable part of the segwit addresses
+	HRP string
+}
+
+var (
+	// MainNet is the Bitcoin main network
+	MainNet = &Network{PubKeyHashID: 0x00, ScriptHashID: 0x05, HRP: "bc"}
+	// TestNet is the Bitcoin test network, which signet shares
+	TestNet = &Network{PubKeyHashID: 0x6f, ScriptHashID: 0xc4, HRP: "tb"}
+)
+
+// BitcoinType is the type of the output a Bitcoin address pays to
+type BitcoinType int
+
+const (
+	// P2PKHType pays to the HASH160 of a public key
+	P2PKHType BitcoinType = iota + 1
+	// P2SHType pays to the HASH160 of a script
+	P2SHType
+	// P2WPKHType pays to the HASH160 of a public key in segwit version 0
+	P2WPKHType
+	// P2WSHType pays to the SHA-256 of a script in segwit version 0
+	P2WSHType
+	// P2TRType pays to a taproot output key in segwit version 1
+	P2TRType
+	// WitnessType pays to a program of the segwit versions not defined yet
+	WitnessType
+)
+
+// BitcoinAddress is a decoded Bitcoin address
+type BitcoinAddress struct {
+	Type BitcoinType
+	// Version is the witness version of the segwit addresses
+	Version byte
+	// Program is the hash or the witness program paid to
+	Program []byte
+}
+
+// Hash160 computes RIPEMD-160(SHA-256(data))
+func Hash160(data []byte) []byte {
+	h := sha256.Sum256(data)
+	r := ripemd160.New()
+	r.Write(h[:])
+
+	return r.Sum(nil)
+}
+
+// P2PKH derives the pay-to-pubkey-hash address of the compressed pub on net
+func P2PKH(pub *secp.PublicKey, net *Network) (string, error) {
+	point, err := compressed(pub)
+	if nil != err {
+		return "", err
+	}
+
+	return Base58CheckEncode(net.PubKeyHashID, Hash160(point)), nil
+}
+
+// P2WPKH derives the pay-to-witness-pubkey-hash address of pub on net
+func P2WPKH(pub *secp.PublicKey, net *Network) (string, error) {
+	point, err := compressed(pub)
+	if nil != err {
+		return "", err
+	}
+
+	return EncodeSegWit(net.HRP, 0, Hash160(point))
+}
+
+// P2TR derives the taproot address of pub on net, whose output key is pub
+// tweaked as BIP-86 without any script path
+func P2TR(pub *secp.PublicKey, net *Network) (string, error) {
+	outputKey, err := TaprootOutputKey(pub)
+	if nil != err {
+		return "", err
+	}
+
+	return EncodeSegWit(net.HRP, 1, outputKey)
+}
+
+// TaprootOutputKey computes the x-only output key Q = P + H_TapTweak(P)G,
+// where P is pub with an even Y
+func TaprootOutputKey(pub *secp.PublicKey) ([]byte, error) {
+	if err := secp.Validate(pub); nil != err {
+		return nil, err
+	}
+
+	c := pub.Curve
+	params := c.Params()
+
+	x := make([]byte, 32)
+	pub.X.FillBytes(x)
+	y := new(big.Int).Set(pub.Y)
+	if 1 == y.Bit(0) {
+		y.Sub(params.P, y)
+	}
+
+	t := new(big.Int).SetBytes(secp.TaggedHash("TapTweak", x))
+	if t.Cmp(params.N) >= 0 {
+		return nil, ErrMalformed
+	}
+
+	tx, ty := c.ScalarBaseMult(t.Bytes())
+	qx, _ := c.Add(pub.X, y, tx, ty)
+
+	out := make([]byte, 32)
+	qx.FillBytes(out)
+
+	return out, nil
+}
+
+// ParseBitcoin decodes the Bitcoin address s of net
+func ParseBitcoin(s string, net *Network) (*BitcoinAddress, error) {
+	// any valid bech32 string is taken as a segwit address
+	if _, _, _, err := DecodeBech32(s); nil == err {
+		version, program, err := DecodeSegWit(net.HRP, s)
+		if nil != err {
+			return nil, err
+		}
+
+		addr := &BitcoinAddress{Type: WitnessType, Version: version, Program: program}
+		switch {
+		case (0 == version) && (20 == len(program)):
+			addr.Type = P2WPKHType
+		case 0 == version:
+			addr.Type = P2WSHType
+		case (1 == version) && (32 == len(program)):
+			addr.Type = P2TRType
+		}
+
+		return addr, nil
+	}
+
+	version, hash, err := Base58CheckDecode(s)
+	if nil != err {
+		return nil, err
+	}
+	if 20 != len(hash) {
+		return nil, ErrMalformed
+	}
+
+	switch version {
+	case net.PubKeyHashID:
+		return &BitcoinAddress{Type: P2PKHType, Program: hash}, nil
+	case net.ScriptHashID:
+		return &BitcoinAddress{Type: P2SHType, Program: hash}, nil
+	}
+
+	return nil, ErrWrongNetwork
+}
+
+// ValidateBitcoin checks s is a valid Bitcoin address of net
+func ValidateBitcoin(s string, net *Network) error {
+	_, err := ParseBitcoin(s, net)
+	return err
+}
+
+func compressed(pub *secp.PublicKey) ([]byte, error) {
+	if err := secp.Validate(pub); nil != err {
+		return nil, err
+	}
+
+	return ecdsa.MarshalSEC1(pub, true)
+}
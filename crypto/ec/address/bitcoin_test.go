@@ -0,0 +1,156 @@
+package address_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec/address"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// xOnlyKey lifts the x-only key to the point of an even Y
+func xOnlyKey(t *testing.T, x string) *secp.PublicKey {
+	data, _ := hex.DecodeString("02" + x)
+	pub, err := ecdsa.UnmarshalSEC1(secp.S256(), data)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	return pub
+}
+
+func TestP2PKH(t *testing.T) {
+	testCases := []struct {
+		d      int64
+		net    *address.Network
+		expect string
+	}{
+		{1, address.MainNet, "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"},
+		{1, address.TestNet, "mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r"},
+	}
+
+	for i, c := range testCases {
+		got, err := address.P2PKH(secpKey(c.d), c.net)
+		if (nil != err) || (c.expect != got) {
+			t.Fatalf("#%d: want %s, got %s (%v)", i, c.expect, got, err)
+		}
+
+		addr, err := address.ParseBitcoin(got, c.net)
+		if (nil != err) || (address.P2PKHType != addr.Type) {
+			t.Fatalf("#%d: unexpected address %+v (%v)", i, addr, err)
+		}
+	}
+}
+
+func TestP2WPKH(t *testing.T) {
+	// the key of BIP-173 is the generator
+	testCases := []struct {
+		net    *address.Network
+		expect string
+	}{
+		{address.MainNet, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+		{address.TestNet, "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx"},
+	}
+
+	for i, c := range testCases {
+		got, err := address.P2WPKH(secpKey(1), c.net)
+		if (nil != err) || (c.expect != got) {
+			t.Fatalf("#%d: want %s, got %s (%v)", i, c.expect, got, err)
+		}
+
+		addr, err := address.ParseBitcoin(got, c.net)
+		if (nil != err) || (address.P2WPKHType != addr.Type) {
+			t.Fatalf("#%d: unexpected address %+v (%v)", i, addr, err)
+		}
+	}
+}
+
+func TestP2TR(t *testing.T) {
+	// the key-path-only outputs of BIP-86 and of the wallet vectors of BIP-341
+	testCases := []struct {
+		internalKey, outputKey, expect string
+	}{
+		{
+			"cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115",
+			"a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c",
+			"bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr",
+		},
+		{
+			"83dfe85a3151d2517290da461fe2815591ef69f2b18a2ce63f01697a8b313145",
+			"a82f29944d65b86ae6b5e5cc75e294ead6c59391a1edc5e016e3498c67fc7bbb",
+			"bc1p4qhjn9zdvkux4e44uhx8tc55attvtyu358kutcqkudyccelu0was9fqzwh",
+		},
+		{
+			"399f1b2f4393f29a18c937859c5dd8a77350103157eb880f02e8c08214277cef",
+			"882d74e5d0572d5a816cef0041a96b6c1de832f6f9676d9605c44d5e9a97d3dc",
+			"bc1p3qkhfews2uk44qtvauqyr2ttdsw7svhkl9nkm9s9c3x4ax5h60wqwruhk7",
+		},
+		{
+			"d6889cb081036e0faefa3a35157ad71086b123b2b144b649798b494c300a961d",
+			"53a1f6e454df1aa2776a2814a721372d6258050de330b3c6d10ee8f4e0dda343",
+			"bc1p2wsldez5mud2yam29q22wgfh9439spgduvct83k3pm50fcxa5dps59h4z5",
+		},
+	}
+
+	for i, c := range testCases {
+		pub := xOnlyKey(t, c.internalKey)
+
+		outputKey, err := address.TaprootOutputKey(pub)
+		if (nil != err) || (c.outputKey != hex.EncodeToString(outputKey)) {
+			t.Fatalf("#%d: want %s, got %x (%v)", i, c.outputKey, outputKey, err)
+		}
+
+		got, err := address.P2TR(pub, address.MainNet)
+		if (nil != err) || (c.expect != got) {
+			t.Fatalf("#%d: want %s, got %s (%v)", i, c.expect, got, err)
+		}
+
+		addr, err := address.ParseBitcoin(got, address.MainNet)
+		if (nil != err) || (address.P2TRType != addr.Type) || !bytes.Equal(outputKey, addr.Program) {
+			t.Fatalf("#%d: unexpected address %+v (%v)", i, addr, err)
+		}
+
+		// the odd twin of the internal key makes the same output
+		odd := &secp.PublicKey{Curve: pub.Curve, X: pub.X, Y: new(big.Int).Sub(pub.Curve.Params().P, pub.Y)}
+		if again, err := address.P2TR(odd, address.MainNet); (nil != err) || (got != again) {
+			t.Fatalf("#%d: want %s, got %s (%v)", i, got, again, err)
+		}
+	}
+}
+
+func TestParseBitcoin(t *testing.T) {
+	testCases := []struct {
+		addr   string
+		net    *address.Network
+		typ    address.BitcoinType
+		expect error
+	}{
+		{"1MirQ9bwyQcGVJPwKUgapu5ouK2E2Ey4gX", address.MainNet, address.P2PKHType, nil},
+		{"3QJmV3qfvL9SuYo34YihAf3sRCW3qSinyC", address.MainNet, address.P2SHType, nil},
+		{"mrX9vMRYLfVy1BnZbc5gZjuyaqH3ZW2ZHz", address.TestNet, address.P2PKHType, nil},
+		{"2NBFNJTktNa7GZusGbDbGKRZTxdK9VVez3n", address.TestNet, address.P2SHType, nil},
+		{"bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3", address.MainNet, address.P2WSHType, nil},
+		{"bc1paardr2nczq0rx5rqpfwnvpzm497zvux64y0f7wjgcs7xuuuh2nnqwr2d5c", address.MainNet, address.P2TRType, nil},
+		{"BC1SW50QGDZ25J", address.MainNet, address.WitnessType, nil},
+		// flipped last character
+		{"1MirQ9bwyQcGVJPwKUgapu5ouK2E2Ey4gY", address.MainNet, 0, address.ErrChecksum},
+		// another network
+		{"1MirQ9bwyQcGVJPwKUgapu5ouK2E2Ey4gX", address.TestNet, 0, address.ErrWrongNetwork},
+		{"tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", address.MainNet, 0, address.ErrWrongNetwork},
+		// litecoin
+		{"LM2WMpR1Rp6j3Sa59cMXMs1SPzj9eXpGc1", address.MainNet, 0, address.ErrWrongNetwork},
+	}
+
+	for i, c := range testCases {
+		addr, err := address.ParseBitcoin(c.addr, c.net)
+		if c.expect != err {
+			t.Fatalf("#%d: want %v, got %v", i, c.expect, err)
+		}
+		if (nil == err) && (c.typ != addr.Type) {
+			t.Fatalf("#%d: want type %d, got %d", i, c.typ, addr.Type)
+		}
+	}
+}
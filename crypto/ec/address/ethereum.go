@@ -0,0 +1,79 @@
+package address
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/sammy00/gravity/crypto/ec/secp"
+	"golang.org/x/crypto/sha3"
+)
+
+// EthereumAddressSize is the byte size of the Ethereum addresses
+const EthereumAddressSize = 20
+
+// Ethereum derives the EIP-55 address of the secp256k1 public key pub
+func Ethereum(pub *secp.PublicKey) (string, error) {
+	if err := secp.Validate(pub); nil != err {
+		return "", err
+	}
+
+	point := make([]byte, 64)
+	pub.X.FillBytes(point[:32])
+	pub.Y.FillBytes(point[32:])
+
+	return EthereumChecksum(keccak256(point)[12:]), nil
+}
+
+// EthereumChecksum encodes the 20-byte addr as 0x-prefixed hex, where the
+// i-th letter is capitalized iff the i-th nibble of the Keccak-256 digest of
+// the lowercase hex is at least 8
+func EthereumChecksum(addr []byte) string {
+	lower := hex.EncodeToString(addr)
+	digest := keccak256([]byte(lower))
+
+	out := []byte(lower)
+	for i, c := range out {
+		nibble := digest[i/2] >> 4
+		if 1 == i%2 {
+			nibble = digest[i/2] & 0x0f
+		}
+		if ('a' <= c) && (8 <= nibble) {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+
+	return "0x" + string(out)
+}
+
+// ParseEthereum decodes the hex address s, with or without 0x, which must
+// match its EIP-55 checksum unless it's all lowercase or all uppercase
+func ParseEthereum(s string) ([]byte, error) {
+	s = strings.TrimPrefix(s, "0x")
+	if 2*EthereumAddressSize != len(s) {
+		return nil, ErrMalformed
+	}
+
+	addr, err := hex.DecodeString(s)
+	if nil != err {
+		return nil, ErrMalformed
+	}
+
+	if (strings.ToLower(s) != s) && (strings.ToUpper(s) != s) && ("0x"+s != EthereumChecksum(addr)) {
+		return nil, ErrChecksum
+	}
+
+	return addr, nil
+}
+
+// ValidateEthereum checks s is a valid Ethereum address
+func ValidateEthereum(s string) error {
+	_, err := ParseEthereum(s)
+	return err
+}
+
+func keccak256(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,84 @@
+package address_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec/address"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// secpKey derives the public key of the scalar d
+func secpKey(d int64) *secp.PublicKey {
+	c := secp.S256()
+	x, y := c.ScalarBaseMult(big.NewInt(d).Bytes())
+
+	return &secp.PublicKey{Curve: c, X: x, Y: y}
+}
+
+func TestEthereum(t *testing.T) {
+	testCases := []struct {
+		d      int64
+		expect string
+	}{
+		{1, "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},
+		{2, "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"},
+		{3, "0x6813Eb9362372EEF6200f3b1dbC3f819671cBA69"},
+	}
+
+	for i, c := range testCases {
+		got, err := address.Ethereum(secpKey(c.d))
+		if (nil != err) || (c.expect != got) {
+			t.Fatalf("#%d: want %s, got %s (%v)", i, c.expect, got, err)
+		}
+		if err := address.ValidateEthereum(got); nil != err {
+			t.Fatalf("#%d: %v", i, err)
+		}
+	}
+}
+
+func TestEthereumChecksum(t *testing.T) {
+	// the examples of EIP-55, whose all-caps and all-lower ones are their own
+	// checksums as well
+	vectors := []string{
+		"0x52908400098527886E0F7030069857D2E4169EE7",
+		"0x8617E340B3D01FA5F11F306F4090FD50E238070D",
+		"0xde709f2102306220921060314715629080e2fb77",
+		"0x27b1fdb04752bbc536007a920d24acb045561c26",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	}
+
+	for i, s := range vectors {
+		addr, err := address.ParseEthereum(s)
+		if nil != err {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if got := address.EthereumChecksum(addr); s != got {
+			t.Fatalf("#%d: want %s, got %s", i, s, got)
+		}
+
+		// case-insensitive forms pass without any checksum
+		if err := address.ValidateEthereum(strings.ToLower(s)); nil != err {
+			t.Fatalf("#%d: %v", i, err)
+		}
+	}
+
+	testCases := []struct {
+		addr   string
+		expect error
+	}{
+		// a flipped letter
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", address.ErrChecksum},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA", address.ErrMalformed},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAgd", address.ErrMalformed},
+	}
+	for i, c := range testCases {
+		if err := address.ValidateEthereum(c.addr); c.expect != err {
+			t.Fatalf("#%d: want %v, got %v", i, c.expect, err)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package address
+
+import "github.com/sammy00/gravity/crypto/ec/ed25519"
+
+// SolanaAddressSize is the byte size of the Solana addresses
+const SolanaAddressSize = 32
+
+// Solana derives the base58 address of the Ed25519 public key pub
+func Solana(pub ed25519.PublicKey) (string, error) {
+	if err := ed25519.Validate(pub); nil != err {
+		return "", err
+	}
+
+	return Base58Encode(pub), nil
+}
+
+// ParseSolana decodes the base58 address s, which isn't necessarily a point
+// of the curve as the program derived addresses aren't
+func ParseSolana(s string) ([]byte, error) {
+	addr, err := Base58Decode(s)
+	if nil != err {
+		return nil, err
+	}
+	if SolanaAddressSize != len(addr) {
+		return nil, ErrMalformed
+	}
+
+	return addr, nil
+}
+
+// ValidateSolana checks s is a valid Solana address
+func ValidateSolana(s string) error {
+	_, err := ParseSolana(s)
+	return err
+}
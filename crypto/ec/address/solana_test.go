@@ -0,0 +1,50 @@
+package address_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec/address"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+)
+
+func TestSolana(t *testing.T) {
+	// the well-known program addresses
+	testCases := []struct {
+		addr, hex string
+	}{
+		{"11111111111111111111111111111111", "0000000000000000000000000000000000000000000000000000000000000000"},
+		{"TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA", "06ddf6e1d765a193d9cbe146ceeb79ac1cb485ed5f5b37913a8cf5857eff00a9"},
+	}
+	for i, c := range testCases {
+		got, err := address.ParseSolana(c.addr)
+		if (nil != err) || (c.hex != hex.EncodeToString(got)) {
+			t.Fatalf("#%d: want %s, got %x (%v)", i, c.hex, got, err)
+		}
+		if again := address.Base58Encode(got); c.addr != again {
+			t.Fatalf("#%d: want %s, got %s", i, c.addr, again)
+		}
+	}
+
+	// the public key of the first vector of RFC 8032
+	pub, _ := hex.DecodeString("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+	addr, err := address.Solana(ed25519.PublicKey(pub))
+	if nil != err {
+		t.Fatal(err)
+	}
+	if got, err := address.ParseSolana(addr); (nil != err) || !bytes.Equal(pub, got) {
+		t.Fatalf("the round trip fails: %v", err)
+	}
+
+	// the identity isn't the key of anyone
+	if _, err := address.Solana(make(ed25519.PublicKey, 32)); nil == err {
+		t.Fatal("the identity should be refused")
+	}
+
+	for i, s := range []string{"", "1111111111111111111111111111111", "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5D0"} {
+		if err := address.ValidateSolana(s); nil == err {
+			t.Fatalf("#%d: %s should be refused", i, s)
+		}
+	}
+}
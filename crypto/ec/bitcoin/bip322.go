@@ -0,0 +1,260 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"math/big"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/address"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// the hash types of the signatures in the witnesses
+const (
+	sighashDefault = 0x00
+	sighashAll     = 0x01
+)
+
+// opReturn is the output script of the transaction to_sign
+const opReturn = 0x6a
+
+// BIP322Hash computes the tagged hash of msg committed to by the BIP-322
+// signatures
+func BIP322Hash(msg []byte) []byte {
+	return secp.TaggedHash("BIP0322-signed-message", msg)
+}
+
+// SignBIP322 signs msg with priv as the simple format of BIP-322 for the
+// address of typ, which is P2WPKHType or P2TRType
+func SignBIP322(priv *secp.PrivateKey, typ address.BitcoinType, msg []byte) (string, error) {
+	point, err := ecdsa.MarshalSEC1(&priv.PublicKey, true)
+	if nil != err {
+		return "", err
+	}
+
+	var witness [][]byte
+	switch typ {
+	case address.P2WPKHType:
+		hash := address.Hash160(point)
+		digest := p2wpkhSighash(toSpend(p2wpkhScript(hash), msg), hash)
+
+		sig, err := strict.Sign(priv, digest)
+		if nil != err {
+			return "", err
+		}
+		witness = [][]byte{append(sig, sighashAll), point}
+	case address.P2TRType:
+		outputKey, err := address.TaprootOutputKey(&priv.PublicKey)
+		if nil != err {
+			return "", err
+		}
+		tweaked, err := tweakPrivKey(priv)
+		if nil != err {
+			return "", err
+		}
+
+		challenge := p2trScript(outputKey)
+		digest := p2trSighash(toSpend(challenge, msg), challenge, sighashDefault)
+
+		sig, err := strict.SignSchnorr(tweaked, digest)
+		if nil != err {
+			return "", err
+		}
+		witness = [][]byte{sig}
+	default:
+		return "", ErrUnsupportedAddress
+	}
+
+	out := appendVarInt(nil, uint64(len(witness)))
+	for _, item := range witness {
+		out = appendVarBytes(out, item)
+	}
+
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// verifyBIP322 verifies the simple BIP-322 signature sig of msg by addr,
+// i.e. the serialized witness stack
+func verifyBIP322(addr *address.BitcoinAddress, msg, sig []byte) error {
+	witness, err := parseWitness(sig)
+	if nil != err {
+		return err
+	}
+
+	switch addr.Type {
+	case address.P2WPKHType:
+		if 2 != len(witness) {
+			return ErrInvalidSig
+		}
+		der, point := witness[0], witness[1]
+		if (2 > len(der)) || (sighashAll != der[len(der)-1]) {
+			return ErrInvalidSig
+		}
+		if !bytes.Equal(address.Hash160(point), addr.Program) {
+			return ErrInvalidSig
+		}
+
+		// the witness keys must be compressed
+		if (33 != len(point)) || ((0x02 != point[0]) && (0x03 != point[0])) {
+			return ErrInvalidSig
+		}
+		pub, err := ecdsa.UnmarshalSEC1(secp.S256(), point)
+		if nil != err {
+			return ErrInvalidSig
+		}
+
+		digest := p2wpkhSighash(toSpend(p2wpkhScript(addr.Program), msg), addr.Program)
+		if !strict.Verify(pub, digest, der[:len(der)-1]) {
+			return ErrInvalidSig
+		}
+	case address.P2TRType:
+		if 1 != len(witness) {
+			return ErrInvalidSig
+		}
+
+		sig, hashType := witness[0], byte(sighashDefault)
+		switch {
+		case (secp.SchnorrSigSize+1 == len(sig)) && (sighashAll == sig[secp.SchnorrSigSize]):
+			sig, hashType = sig[:secp.SchnorrSigSize], sighashAll
+		case secp.SchnorrSigSize != len(sig):
+			return ErrInvalidSig
+		}
+
+		challenge := p2trScript(addr.Program)
+		digest := p2trSighash(toSpend(challenge, msg), challenge, hashType)
+		if !secp.VerifySchnorr(addr.Program, digest, sig) {
+			return ErrInvalidSig
+		}
+	default:
+		return ErrUnsupportedAddress
+	}
+
+	return nil
+}
+
+// parseWitness decodes the serialized witness stack data
+func parseWitness(data []byte) ([][]byte, error) {
+	n, data, ok := readVarInt(data)
+	if !ok || (uint64(len(data)) < n) {
+		return nil, ec.ErrMalformedSig
+	}
+
+	witness := make([][]byte, 0, n)
+	for i := uint64(0); i < n; i++ {
+		size, rest, ok := readVarInt(data)
+		if !ok || (uint64(len(rest)) < size) {
+			return nil, ec.ErrMalformedSig
+		}
+		witness = append(witness, rest[:size])
+		data = rest[size:]
+	}
+	if 0 != len(data) {
+		return nil, ec.ErrMalformedSig
+	}
+
+	return witness, nil
+}
+
+// toSpend computes the txid of the virtual transaction to_spend, whose only
+// output pays to challenge and whose input commits to msg
+func toSpend(challenge, msg []byte) []byte {
+	tx := make([]byte, 4, 128)              // nVersion
+	tx = append(tx, 1)                      // one input
+	tx = append(tx, make([]byte, 32)...)    // prevout.hash
+	tx = append(tx, 0xff, 0xff, 0xff, 0xff) // prevout.n
+	tx = appendVarBytes(tx, append([]byte{0x00, 0x20}, BIP322Hash(msg)...))
+	tx = append(tx, 0, 0, 0, 0)         // nSequence
+	tx = append(tx, 1)                  // one output
+	tx = append(tx, make([]byte, 8)...) // nValue
+	tx = appendVarBytes(tx, challenge)  // scriptPubKey
+	tx = append(tx, 0, 0, 0, 0)         // nLockTime
+
+	return doubleSHA256(tx)
+}
+
+// the parts of the virtual transaction to_sign spending the output 0 of
+// to_spend into OP_RETURN, with its version, lock time and sequence all zero
+var (
+	zero4    = []byte{0, 0, 0, 0}
+	zero8    = make([]byte, 8)
+	toOutput = append(append([]byte(nil), zero8...), 1, opReturn)
+)
+
+// p2wpkhSighash computes the BIP-143 digest signed by the key of hash for
+// SIGHASH_ALL, spending the output txid:0
+func p2wpkhSighash(txid, hash []byte) []byte {
+	outpoint := append(append([]byte(nil), txid...), zero4...)
+	scriptCode := append(append([]byte{0x19, 0x76, 0xa9, 0x14}, hash...), 0x88, 0xac)
+
+	return doubleSHA256(
+		zero4,                  // nVersion
+		doubleSHA256(outpoint), // hashPrevouts
+		doubleSHA256(zero4),    // hashSequence
+		outpoint,
+		scriptCode,
+		zero8,                  // amount
+		zero4,                  // nSequence
+		doubleSHA256(toOutput), // hashOutputs
+		zero4,                  // nLockTime
+		[]byte{sighashAll, 0, 0, 0},
+	)
+}
+
+// p2trSighash computes the BIP-341 digest signed by the key path for
+// hashType, spending the output txid:0 paying to challenge
+func p2trSighash(txid, challenge []byte, hashType byte) []byte {
+	outpoint := append(append([]byte(nil), txid...), zero4...)
+
+	return secp.TaggedHash("TapSighash",
+		[]byte{0x00, hashType}, // epoch and hash type
+		zero4,                  // nVersion
+		zero4,                  // nLockTime
+		singleSHA256(outpoint),
+		singleSHA256(zero8), // amounts
+		singleSHA256(appendVarBytes(nil, challenge)),
+		singleSHA256(zero4), // sequences
+		singleSHA256(toOutput),
+		[]byte{0x00}, // spend type without annex
+		zero4,        // input index
+	)
+}
+
+// p2trScript builds the output script OP_1 <outputKey> of P2TR
+func p2trScript(outputKey []byte) []byte {
+	return append([]byte{0x51, 0x20}, outputKey...)
+}
+
+// tweakPrivKey tweaks priv into the private key of its BIP-86 output key,
+// i.e. d + H_TapTweak(P) where d is negated if P has an odd Y
+func tweakPrivKey(priv *secp.PrivateKey) (*secp.PrivateKey, error) {
+	x, err := secp.XOnly(&priv.PublicKey)
+	if nil != err {
+		return nil, err
+	}
+
+	c := priv.Curve
+	n := c.Params().N
+
+	d := new(big.Int).Set(priv.D)
+	if 1 == priv.Y.Bit(0) {
+		d.Sub(n, d)
+	}
+
+	t := new(big.Int).SetBytes(secp.TaggedHash("TapTweak", x))
+	if t.Cmp(n) >= 0 {
+		return nil, ec.ErrInvalidKey
+	}
+	d.Add(d, t).Mod(d, n)
+	if 0 == d.Sign() {
+		return nil, ec.ErrInvalidKey
+	}
+
+	tweaked := new(secp.PrivateKey)
+	tweaked.Curve = c
+	tweaked.D = d
+	tweaked.X, tweaked.Y = c.ScalarBaseMult(d.Bytes())
+
+	return tweaked, nil
+}
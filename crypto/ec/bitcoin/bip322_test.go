@@ -0,0 +1,156 @@
+package bitcoin_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/address"
+	"github.com/sammy00/gravity/crypto/ec/bitcoin"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// the key of the test vectors of BIP-322
+const bip322WIF = "L3VFeEujGtevx9w18HD1fhRbCH67Az2dpCymeRE1SoPK6XQtaN2k"
+
+func TestBIP322Hash(t *testing.T) {
+	testCases := []struct {
+		msg  string
+		hash string
+	}{
+		{"", "c90c269c4f8fcbe6880f72a721ddfbf1914268a794cbb21cfafee13770ae19f1"},
+		{"Hello World", "f0eb03b1a75ac6d9847f55c624a99169b5dccba2a31f5b23bea77ba270de0a7a"},
+	}
+
+	for i, c := range testCases {
+		if got := hex.EncodeToString(bitcoin.BIP322Hash([]byte(c.msg))); c.hash != got {
+			t.Fatalf("#%d: want %s, got %s", i, c.hash, got)
+		}
+	}
+}
+
+func TestVerifyBIP322(t *testing.T) {
+	priv := decodeWIF(t, bip322WIF)
+
+	p2wpkh, err := address.P2WPKH(&priv.PublicKey, address.MainNet)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if want := "bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l"; want != p2wpkh {
+		t.Fatalf("want %s, got %s", want, p2wpkh)
+	}
+	p2tr, err := address.P2TR(&priv.PublicKey, address.MainNet)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		addr string
+		msg  string
+		sig  string
+	}{
+		{p2wpkh, "", "AkcwRAIgM2gBAQqvZX15ZiysmKmQpDrG83avLIT492QBzLnQIxYCIBaTpOaD20qRlEylyxFSeEA2ba9YOixpX8z46TSDtS40ASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI="},
+		{p2wpkh, "Hello World", "AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI="},
+		{p2wpkh, "Hello World", "AkgwRQIhAOzyynlqt93lOKJr+wmmxIens//zPzl9tqIOua93wO6MAiBi5n5EyAcPScOjf1lAqIUIQtr3zKNeavYabHyR8eGhowEhAsfxIAMZZEKUPYWI4BruhAQjzFT8FSFSajuFwrDL1Yhy"},
+		{p2tr, "Hello World", "AUHd69PrJQEv+oKTfZ8l+WROBHuy9HKrbFCJu7U1iK2iiEy1vMU5EfMtjc+VSHM7aU0SDbak5IUZRVno2P5mjSafAQ=="},
+	}
+
+	for i, c := range testCases {
+		if err := bitcoin.VerifyMessage(c.addr, address.MainNet, []byte(c.msg), c.sig); nil != err {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+
+		if err := bitcoin.VerifyMessage(c.addr, address.MainNet, []byte(c.msg+"!"), c.sig); bitcoin.ErrInvalidSig != err {
+			t.Fatalf("#%d: want %v, got %v", i, bitcoin.ErrInvalidSig, err)
+		}
+	}
+}
+
+func TestSignBIP322(t *testing.T) {
+	k, err := secp.New().GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	priv := k.(*secp.PrivateKey)
+
+	p2wpkh, err := address.P2WPKH(&priv.PublicKey, address.TestNet)
+	if nil != err {
+		t.Fatal(err)
+	}
+	p2tr, err := address.P2TR(&priv.PublicKey, address.TestNet)
+	if nil != err {
+		t.Fatal(err)
+	}
+	p2pkh, err := address.P2PKH(&priv.PublicKey, address.TestNet)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		typ  address.BitcoinType
+		addr string
+	}{
+		{address.P2WPKHType, p2wpkh},
+		{address.P2TRType, p2tr},
+	}
+
+	msg := []byte("Hello World")
+	for i, c := range testCases {
+		sig, err := bitcoin.SignBIP322(priv, c.typ, msg)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if err := bitcoin.VerifyMessage(c.addr, address.TestNet, msg, sig); nil != err {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+
+		// the signature doesn't prove the ownership of the other address
+		other := p2tr
+		if p2tr == c.addr {
+			other = p2wpkh
+		}
+		if err := bitcoin.VerifyMessage(other, address.TestNet, msg, sig); bitcoin.ErrInvalidSig != err {
+			t.Fatalf("#%d: want %v, got %v", i, bitcoin.ErrInvalidSig, err)
+		}
+	}
+
+	// legacy addresses sign as BIP-137
+	if _, err := bitcoin.SignBIP322(priv, address.P2PKHType, msg); bitcoin.ErrUnsupportedAddress != err {
+		t.Fatalf("want %v, got %v", bitcoin.ErrUnsupportedAddress, err)
+	}
+	sig, err := bitcoin.SignBIP322(priv, address.P2WPKHType, msg)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if err := bitcoin.VerifyMessage(p2pkh, address.TestNet, msg, sig); bitcoin.ErrUnsupportedAddress != err {
+		t.Fatalf("want %v, got %v", bitcoin.ErrUnsupportedAddress, err)
+	}
+}
+
+func TestVerifyMessageMalformed(t *testing.T) {
+	const addr = "bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l"
+
+	testCases := []struct {
+		sig string
+		err error
+	}{
+		{"not base64!", bitcoin.ErrInvalidSig},
+		{"", ec.ErrMalformedSig},
+		{"Ag==", ec.ErrMalformedSig},
+		{"AkcwRAIgM2gBAQqvZX15ZiysmKmQpDrG83avLIT492QBzLnQIxYCIBaTpOaD20qR", ec.ErrMalformedSig},
+		{"AUHd69PrJQEv+oKTfZ8l+WROBHuy9HKrbFCJu7U1iK2iiEy1vMU5EfMtjc+VSHM7aU0SDbak5IUZRVno2P5mjSafAQ==", bitcoin.ErrInvalidSig},
+	}
+
+	for i, c := range testCases {
+		if err := bitcoin.VerifyMessage(addr, address.MainNet, nil, c.sig); c.err != err {
+			t.Fatalf("#%d: want %v, got %v", i, c.err, err)
+		}
+	}
+
+	// an address of another network
+	const sig = "AkcwRAIgM2gBAQqvZX15ZiysmKmQpDrG83avLIT492QBzLnQIxYCIBaTpOaD20qRlEylyxFSeEA2ba9YOixpX8z46TSDtS40ASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI="
+	if err := bitcoin.VerifyMessage(addr, address.TestNet, nil, sig); address.ErrWrongNetwork != err {
+		t.Fatalf("want %v, got %v", address.ErrWrongNetwork, err)
+	}
+}
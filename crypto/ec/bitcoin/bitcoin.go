@@ -0,0 +1,130 @@
+// Package bitcoin signs and verifies the Bitcoin signed messages proving the
+// ownership of an address, in the legacy format of BIP-137 and the generic
+// format of BIP-322, on top of secp.Worker
+package bitcoin
+
+// Note:
+// + BIP-137 signatures are the base64 of header || r || s, where the header
+//   is 27 plus the recovery id, plus 4 for a compressed P2PKH, 8 for a
+//   P2SH-P2WPKH and 12 for a P2WPKH address
+// + the segwit addresses are accepted under any header of a compressed key,
+//   as wallets like Electrum sign them under the P2PKH ones
+// + BIP-322 signatures are the base64 of the witness spending a virtual
+//   transaction whose output pays to the address, which P2WPKH and single key
+//   P2TR addresses sign in the "simple" format
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+
+	"github.com/sammy00/gravity/crypto/ec/address"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+var (
+	// ErrInvalidSig indicates the signature isn't made by the address
+	ErrInvalidSig = errors.New("bitcoin: the signature doesn't match the address")
+	// ErrUnsupportedAddress indicates the type of the address can't sign
+	// messages in the given format
+	ErrUnsupportedAddress = errors.New("bitcoin: unsupported type of address")
+)
+
+// VerifyMessage verifies the base64 signature sig of msg by the address addr
+// of net, in either the format of BIP-137 or the simple one of BIP-322
+func VerifyMessage(addr string, net *address.Network, msg []byte, sig string) error {
+	a, err := address.ParseBitcoin(addr, net)
+	if nil != err {
+		return err
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(sig)
+	if nil != err {
+		return ErrInvalidSig
+	}
+
+	if isCompactSig(raw) {
+		return verifyCompact(a, msg, raw)
+	}
+
+	return verifyBIP322(a, msg, raw)
+}
+
+// strict refuses the high s and the public keys off the curve as the
+// standardness rules of the segwit transactions do
+var strict = newStrictWorker()
+
+func newStrictWorker() *secp.Worker {
+	w := secp.New()
+	w.Strict = true
+
+	return w
+}
+
+func doubleSHA256(data ...[]byte) []byte {
+	h := sha256.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+	sum := sha256.Sum256(h.Sum(nil))
+
+	return sum[:]
+}
+
+func singleSHA256(data ...[]byte) []byte {
+	h := sha256.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+
+	return h.Sum(nil)
+}
+
+// appendVarInt appends the CompactSize encoding of n
+func appendVarInt(out []byte, n uint64) []byte {
+	switch {
+	case n < 0xfd:
+		return append(out, byte(n))
+	case n <= 0xffff:
+		return append(out, 0xfd, byte(n), byte(n>>8))
+	case n <= 0xffffffff:
+		return append(out, 0xfe, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	}
+
+	return append(out, 0xff, byte(n), byte(n>>8), byte(n>>16), byte(n>>24),
+		byte(n>>32), byte(n>>40), byte(n>>48), byte(n>>56))
+}
+
+// readVarInt reads a CompactSize from data, returning the rest of data
+func readVarInt(data []byte) (uint64, []byte, bool) {
+	if 1 > len(data) {
+		return 0, nil, false
+	}
+
+	var size int
+	switch data[0] {
+	case 0xfd:
+		size = 2
+	case 0xfe:
+		size = 4
+	case 0xff:
+		size = 8
+	default:
+		return uint64(data[0]), data[1:], true
+	}
+	if 1+size > len(data) {
+		return 0, nil, false
+	}
+
+	var n uint64
+	for i := size; i > 0; i-- {
+		n = n<<8 | uint64(data[i])
+	}
+
+	return n, data[1+size:], true
+}
+
+// appendVarBytes appends b prefixed by its CompactSize length
+func appendVarBytes(out, b []byte) []byte {
+	return append(appendVarInt(out, uint64(len(b))), b...)
+}
@@ -0,0 +1,103 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/base64"
+
+	"github.com/sammy00/gravity/crypto/ec/address"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// the prefix of the messages signed by Bitcoin Core
+const messagePrefix = "Bitcoin Signed Message:\n"
+
+// the headers of BIP-137, before adding the recovery id
+const (
+	headerUncompressed = 27
+	headerP2PKH        = 31
+	headerP2SHP2WPKH   = 35
+	headerP2WPKH       = 39
+	headerMax          = 42
+)
+
+// compactSigSize is the byte size of the BIP-137 signatures
+const compactSigSize = secp.RecoverableSigSize
+
+// MessageHash computes the digest signed for msg, i.e. the double SHA-256 of
+// the prefix and msg, each prefixed by its length
+func MessageHash(msg []byte) []byte {
+	return doubleSHA256(appendVarBytes(appendVarBytes(nil, []byte(messagePrefix)), msg))
+}
+
+// SignMessage signs msg with priv as BIP-137 for the address of typ, among
+// P2PKHType as Bitcoin Core does, P2SHType for P2SH-P2WPKH and P2WPKHType
+func SignMessage(priv *secp.PrivateKey, typ address.BitcoinType, msg []byte) (string, error) {
+	var header byte
+	switch typ {
+	case address.P2PKHType:
+		header = headerP2PKH
+	case address.P2SHType:
+		header = headerP2SHP2WPKH
+	case address.P2WPKHType:
+		header = headerP2WPKH
+	default:
+		return "", ErrUnsupportedAddress
+	}
+
+	sig, err := strict.SignRecoverable(priv, MessageHash(msg))
+	if nil != err {
+		return "", err
+	}
+
+	compact := append([]byte{header + sig[compactSigSize-1]}, sig[:compactSigSize-1]...)
+
+	return base64.StdEncoding.EncodeToString(compact), nil
+}
+
+// isCompactSig tells whether sig looks like a BIP-137 signature
+func isCompactSig(sig []byte) bool {
+	return (compactSigSize == len(sig)) && (headerUncompressed <= sig[0]) && (sig[0] <= headerMax)
+}
+
+// verifyCompact verifies the BIP-137 signature sig of msg by addr
+func verifyCompact(addr *address.BitcoinAddress, msg, sig []byte) error {
+	header := sig[0]
+	compressed := headerP2PKH <= header
+
+	recoverable := append(append([]byte(nil), sig[1:]...), (header-headerUncompressed)&3)
+	pubKey, err := secp.New().RecoverPubKey(MessageHash(msg), recoverable)
+	if nil != err {
+		return err
+	}
+
+	point, err := ecdsa.MarshalSEC1(pubKey.(*secp.PublicKey), compressed)
+	if nil != err {
+		return err
+	}
+
+	var hash []byte
+	switch {
+	case address.P2PKHType == addr.Type:
+		hash = address.Hash160(point)
+	case !compressed:
+		return ErrInvalidSig
+	case address.P2WPKHType == addr.Type:
+		hash = address.Hash160(point)
+	case address.P2SHType == addr.Type:
+		hash = address.Hash160(p2wpkhScript(address.Hash160(point)))
+	default:
+		return ErrUnsupportedAddress
+	}
+
+	if !bytes.Equal(addr.Program, hash) {
+		return ErrInvalidSig
+	}
+
+	return nil
+}
+
+// p2wpkhScript builds the output script OP_0 <hash> of P2WPKH
+func p2wpkhScript(hash []byte) []byte {
+	return append([]byte{0x00, 0x14}, hash...)
+}
@@ -0,0 +1,147 @@
+package bitcoin_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec/address"
+	"github.com/sammy00/gravity/crypto/ec/bitcoin"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// decodeWIF decodes the private key in the wallet import format
+func decodeWIF(t *testing.T, wif string) *secp.PrivateKey {
+	_, payload, err := address.Base58CheckDecode(wif)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	priv := new(secp.PrivateKey)
+	priv.Curve = secp.S256()
+	priv.D = new(big.Int).SetBytes(payload[:32])
+	priv.X, priv.Y = priv.Curve.ScalarBaseMult(priv.D.Bytes())
+
+	return priv
+}
+
+func TestMessageHash(t *testing.T) {
+	const want = "a7af0baad5ae99b97fc69b3a0d1abcf3ef17f131cc4776e1bc11933ec8550f49"
+
+	got := hex.EncodeToString(bitcoin.MessageHash([]byte("Hello World")))
+	if want != got {
+		t.Fatalf("want %s, got %s", want, got)
+	}
+}
+
+// the signatures made by Bitcoin Core in its tests of signmessage
+func TestVerifyMessageBitcoinCore(t *testing.T) {
+	testCases := []struct {
+		addr string
+		net  *address.Network
+		msg  string
+		sig  string
+	}{
+		{
+			"15CRxFdyRpGZLW9w8HnHvVduizdL5jKNbs",
+			address.MainNet,
+			"Trust no one",
+			"IPojfrX2dfPnH26UegfbGQQLrdK844DlHq5157/P6h57WyuS/Qsl+h/WSVGDF4MUi4rWSswW38oimDYfNNUBUOk=",
+		},
+		{
+			"11canuhp9X2NocwCq7xNrQYTmUgZAnLK3",
+			address.MainNet,
+			"Trust me",
+			"IIcaIENoYW5jZWxsb3Igb24gYnJpbmsgb2Ygc2Vjb25kIGJhaWxvdXQgZm9yIGJhbmtzIAaHRtbCeDZINyavx14=",
+		},
+	}
+
+	for i, c := range testCases {
+		if err := bitcoin.VerifyMessage(c.addr, c.net, []byte(c.msg), c.sig); nil != err {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+
+		if err := bitcoin.VerifyMessage(c.addr, c.net, []byte(c.msg+"!"), c.sig); bitcoin.ErrInvalidSig != err {
+			t.Fatalf("#%d: want %v, got %v", i, bitcoin.ErrInvalidSig, err)
+		}
+	}
+}
+
+// the key signing the message of Bitcoin Core in its tests of MessageSign
+func TestSignMessageBitcoinCore(t *testing.T) {
+	priv := new(secp.PrivateKey)
+	priv.Curve = secp.S256()
+	priv.D, _ = new(big.Int).SetString("d97f5108f11cda6eeebaaa420fef0726b1f898060b98489fa3098463c0032866", 16)
+	priv.X, priv.Y = priv.Curve.ScalarBaseMult(priv.D.Bytes())
+
+	addr, err := address.P2PKH(&priv.PublicKey, address.MainNet)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if want := "15CRxFdyRpGZLW9w8HnHvVduizdL5jKNbs"; want != addr {
+		t.Fatalf("want %s, got %s", want, addr)
+	}
+
+	msg := []byte("Trust no one")
+	sig, err := bitcoin.SignMessage(priv, address.P2PKHType, msg)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if err := bitcoin.VerifyMessage(addr, address.MainNet, msg, sig); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func TestSignMessage(t *testing.T) {
+	k, err := secp.New().GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	priv := k.(*secp.PrivateKey)
+
+	p2pkh, err := address.P2PKH(&priv.PublicKey, address.MainNet)
+	if nil != err {
+		t.Fatal(err)
+	}
+	p2wpkh, err := address.P2WPKH(&priv.PublicKey, address.MainNet)
+	if nil != err {
+		t.Fatal(err)
+	}
+	p2tr, err := address.P2TR(&priv.PublicKey, address.MainNet)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		typ  address.BitcoinType
+		addr string
+	}{
+		{address.P2PKHType, p2pkh},
+		{address.P2WPKHType, p2wpkh},
+	}
+
+	msg := []byte("Hello World")
+	for i, c := range testCases {
+		sig, err := bitcoin.SignMessage(priv, c.typ, msg)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if err := bitcoin.VerifyMessage(c.addr, address.MainNet, msg, sig); nil != err {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+	}
+
+	if _, err := bitcoin.SignMessage(priv, address.P2TRType, msg); bitcoin.ErrUnsupportedAddress != err {
+		t.Fatalf("want %v, got %v", bitcoin.ErrUnsupportedAddress, err)
+	}
+
+	// taproot addresses accept no BIP-137 signature
+	sig, err := bitcoin.SignMessage(priv, address.P2PKHType, msg)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if err := bitcoin.VerifyMessage(p2tr, address.MainNet, msg, sig); bitcoin.ErrUnsupportedAddress != err {
+		t.Fatalf("want %v, got %v", bitcoin.ErrUnsupportedAddress, err)
+	}
+}
@@ -0,0 +1,82 @@
+// Package blind implements the Clause Blind Schnorr signatures over Ed25519,
+// whose unblinded signatures are plain Ed25519 signatures unlinkable to the
+// session issuing them
+package blind
+
+// Note:
+// + a session runs in three moves: the signer commits to two nonces R0 and
+//   R1, the user blinds a challenge for each, and the signer answers only
+//   one of them chosen at random
+// + answering a single random clause defeats the ROS attacks, which forge
+//   signatures out of many concurrent sessions of plain blind Schnorr
+// + the user blinds R' = R + aG + bA and c = H(R' || A || msg) + b, so the
+//   unblinded R' || s + a verifies as Ed25519 against A
+// + a signer session answers once, since two answers would leak the key
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+
+	"filippo.io/edwards25519"
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+)
+
+var (
+	// ErrSessionDone indicates the signer session has already answered
+	ErrSessionDone = errors.New("blind: the session has already answered")
+	// ErrInvalidMessage indicates a message of the session is malformed
+	ErrInvalidMessage = errors.New("blind: invalid message")
+	// ErrInvalidResponse indicates the answer of the signer doesn't verify
+	ErrInvalidResponse = errors.New("blind: invalid response")
+)
+
+// clauses is the number of nonces committed to per session
+const clauses = 2
+
+// Commitment is the first move of the signer, committing to its nonces
+type Commitment struct {
+	R [clauses][32]byte
+}
+
+// Challenge is the move of the user, blinding a challenge per clause
+type Challenge struct {
+	C [clauses][32]byte
+}
+
+// Response is the final move of the signer, answering a random clause
+type Response struct {
+	Clause int
+	S      [32]byte
+}
+
+// Verify verifies the unblinded signature sig of msg by pubKey, which is a
+// plain Ed25519 signature
+func Verify(pubKey ec.PublicKey, msg []byte, sig ec.Sig) bool {
+	worker := &ed25519.Worker{Strict: true}
+
+	return worker.Verify(pubKey, msg, sig)
+}
+
+// randomScalar draws a uniform scalar
+func randomScalar() (*edwards25519.Scalar, error) {
+	var buf [64]byte
+	if _, err := rand.Read(buf[:]); nil != err {
+		return nil, err
+	}
+
+	return edwards25519.NewScalar().SetUniformBytes(buf[:])
+}
+
+// challenge computes H(R || A || msg) mod l as Ed25519
+func challenge(R, A, msg []byte) *edwards25519.Scalar {
+	h := sha512.New()
+	h.Write(R)
+	h.Write(A)
+	h.Write(msg)
+
+	c, _ := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+
+	return c
+}
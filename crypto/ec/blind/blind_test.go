@@ -0,0 +1,188 @@
+package blind_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/blind"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+)
+
+func newSigner(t *testing.T) (*blind.Signer, ec.PublicKey) {
+	priv, err := new(ed25519.Worker).GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	signer, err := blind.NewSigner(priv)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	return signer, priv.Public()
+}
+
+func TestBlindSignature(t *testing.T) {
+	signer, pub := newSigner(t)
+	worker := new(ed25519.Worker)
+
+	for i := 0; i < 16; i++ {
+		token := []byte("rate-limit token")
+
+		session, cmt, err := signer.Commit()
+		if nil != err {
+			t.Fatal(err)
+		}
+		user, ch, err := blind.Blind(pub, token, cmt)
+		if nil != err {
+			t.Fatal(err)
+		}
+		resp, err := session.Sign(ch)
+		if nil != err {
+			t.Fatal(err)
+		}
+		sig, err := user.Unblind(resp)
+		if nil != err {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+
+		// the unblinded signature is a plain Ed25519 one
+		if !worker.Verify(pub, token, sig) || !blind.Verify(pub, token, sig) {
+			t.Fatalf("#%d: the verification shouldn't fail", i)
+		}
+		if worker.Verify(pub, []byte("another token"), sig) {
+			t.Fatalf("#%d: the signature of another message is accepted", i)
+		}
+
+		// and the signer never saw its nonce point nor its challenge
+		for _, R := range cmt.R {
+			if bytes.Equal(R[:], sig[:32]) {
+				t.Fatalf("#%d: the signature reveals the commitment", i)
+			}
+		}
+		if bytes.Equal(resp.S[:], sig[32:]) {
+			t.Fatalf("#%d: the signature reveals the response", i)
+		}
+	}
+}
+
+func TestSignerSessionOnce(t *testing.T) {
+	signer, pub := newSigner(t)
+
+	session, cmt, err := signer.Commit()
+	if nil != err {
+		t.Fatal(err)
+	}
+	_, ch, err := blind.Blind(pub, []byte("token"), cmt)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	if _, err := session.Sign(ch); nil != err {
+		t.Fatal(err)
+	}
+	if _, err := session.Sign(ch); blind.ErrSessionDone != err {
+		t.Fatalf("want %v, got %v", blind.ErrSessionDone, err)
+	}
+}
+
+func TestSignerSessionInvalidChallenge(t *testing.T) {
+	signer, pub := newSigner(t)
+
+	for clause := 0; clause < 2; clause++ {
+		session, cmt, err := signer.Commit()
+		if nil != err {
+			t.Fatal(err)
+		}
+		_, ch, err := blind.Blind(pub, []byte("token"), cmt)
+		if nil != err {
+			t.Fatal(err)
+		}
+
+		// a malformed challenge for one clause mustn't let the user retry
+		// until the signer answers the other one
+		for i := range ch.C[clause] {
+			ch.C[clause][i] = 0xff
+		}
+		if _, err := session.Sign(ch); blind.ErrInvalidMessage != err {
+			t.Fatalf("#%d: want %v, got %v", clause, blind.ErrInvalidMessage, err)
+		}
+		if _, err := session.Sign(ch); blind.ErrSessionDone != err {
+			t.Fatalf("#%d: want %v, got %v", clause, blind.ErrSessionDone, err)
+		}
+	}
+}
+
+func TestUnblindInvalidResponse(t *testing.T) {
+	signer, pub := newSigner(t)
+
+	session, cmt, err := signer.Commit()
+	if nil != err {
+		t.Fatal(err)
+	}
+	user, ch, err := blind.Blind(pub, []byte("token"), cmt)
+	if nil != err {
+		t.Fatal(err)
+	}
+	resp, err := session.Sign(ch)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	// the answer of the other clause
+	other := *resp
+	other.Clause = 1 - resp.Clause
+	if _, err := user.Unblind(&other); blind.ErrInvalidResponse != err {
+		t.Fatalf("want %v, got %v", blind.ErrInvalidResponse, err)
+	}
+
+	tampered := *resp
+	tampered.S[0] ^= 0x01
+	if _, err := user.Unblind(&tampered); blind.ErrInvalidResponse != err {
+		t.Fatalf("want %v, got %v", blind.ErrInvalidResponse, err)
+	}
+
+	outOfRange := *resp
+	outOfRange.Clause = 2
+	if _, err := user.Unblind(&outOfRange); blind.ErrInvalidMessage != err {
+		t.Fatalf("want %v, got %v", blind.ErrInvalidMessage, err)
+	}
+
+	// the commitment of another signer
+	_, otherPub := newSigner(t)
+	otherUser, _, err := blind.Blind(otherPub, []byte("token"), cmt)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if _, err := otherUser.Unblind(resp); blind.ErrInvalidResponse != err {
+		t.Fatalf("want %v, got %v", blind.ErrInvalidResponse, err)
+	}
+}
+
+func TestBlindInvalid(t *testing.T) {
+	signer, pub := newSigner(t)
+	_, cmt, err := signer.Commit()
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	if _, err := blind.NewSigner(ed25519.PrivateKey{}); ec.ErrKeyTampered != err {
+		t.Fatalf("want %v, got %v", ec.ErrKeyTampered, err)
+	}
+
+	identity := make(ed25519.PublicKey, 32)
+	identity[0] = 0x01
+	if _, _, err := blind.Blind(identity, nil, cmt); ec.ErrPointAtInfinity != err {
+		t.Fatalf("want %v, got %v", ec.ErrPointAtInfinity, err)
+	}
+
+	malformed := *cmt
+	for i := range malformed.R[0] {
+		malformed.R[0][i] = 0xff
+	}
+	if _, _, err := blind.Blind(pub, nil, &malformed); blind.ErrInvalidMessage != err {
+		t.Fatalf("want %v, got %v", blind.ErrInvalidMessage, err)
+	}
+}
@@ -0,0 +1,100 @@
+package blind
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sync"
+
+	"filippo.io/edwards25519"
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+)
+
+// Signer issues blind signatures with an Ed25519 key
+type Signer struct {
+	x *edwards25519.Scalar
+}
+
+// SignerSession is the state of the signer in one session
+type SignerSession struct {
+	x *edwards25519.Scalar
+
+	mu    sync.Mutex
+	nonce [clauses]*edwards25519.Scalar
+	done  bool
+}
+
+// NewSigner makes a signer of the ed25519.PrivateKey privKey
+func NewSigner(privKey ec.PrivateKey) (*Signer, error) {
+	priv, ok := privKey.(ed25519.PrivateKey)
+	if !ok {
+		return nil, ec.ErrKeyTampered
+	}
+
+	x, err := ed25519.Scalar(priv)
+	if nil != err {
+		return nil, err
+	}
+
+	return &Signer{x: x}, nil
+}
+
+// Commit opens a session, committing to its fresh nonces
+func (s *Signer) Commit() (*SignerSession, *Commitment, error) {
+	session := &SignerSession{x: s.x}
+	cmt := new(Commitment)
+
+	for i := range session.nonce {
+		r, err := randomScalar()
+		if nil != err {
+			return nil, nil, err
+		}
+		session.nonce[i] = r
+		copy(cmt.R[i][:], new(edwards25519.Point).ScalarBaseMult(r).Bytes())
+	}
+
+	return session, cmt, nil
+}
+
+// Sign answers the challenge of the user for a random clause, i.e.
+// s = r + c*x, after which the session is done
+func (session *SignerSession) Sign(ch *Challenge) (*Response, error) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.done {
+		return nil, ErrSessionDone
+	}
+	// a session answers at most once, even a malformed challenge
+	defer session.forget()
+
+	// every challenge is checked before the draw, since failing on the drawn
+	// one only would let the user retry until the clause of its choice
+	var c [clauses]*edwards25519.Scalar
+	for i := range ch.C {
+		var err error
+		if c[i], err = edwards25519.NewScalar().SetCanonicalBytes(ch.C[i][:]); nil != err {
+			return nil, ErrInvalidMessage
+		}
+	}
+
+	bit, err := rand.Int(rand.Reader, big.NewInt(clauses))
+	if nil != err {
+		return nil, err
+	}
+	clause := int(bit.Int64())
+
+	resp := &Response{Clause: clause}
+	copy(resp.S[:], edwards25519.NewScalar().MultiplyAdd(c[clause], session.x, session.nonce[clause]).Bytes())
+
+	return resp, nil
+}
+
+// forget ends the session, forgetting the nonces whose reuse would leak the
+// key
+func (session *SignerSession) forget() {
+	session.done = true
+	for i := range session.nonce {
+		session.nonce[i] = nil
+	}
+}
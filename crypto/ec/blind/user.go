@@ -0,0 +1,94 @@
+package blind
+
+import (
+	"filippo.io/edwards25519"
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+)
+
+// UserSession is the state of the user in one session
+type UserSession struct {
+	pub []byte
+	A   *edwards25519.Point
+
+	// the blinded nonce points, their nonces and blinded challenges per
+	// clause
+	blindedR [clauses][]byte
+	alpha    [clauses]*edwards25519.Scalar
+	R        [clauses]*edwards25519.Point
+	c        [clauses]*edwards25519.Scalar
+}
+
+// Blind blinds msg for the signer of the ed25519.PublicKey pubKey, which
+// committed to cmt, into the challenge to send back
+func Blind(pubKey ec.PublicKey, msg []byte, cmt *Commitment) (*UserSession, *Challenge, error) {
+	pub, ok := pubKey.(ed25519.PublicKey)
+	if !ok {
+		return nil, nil, ec.ErrKeyTampered
+	}
+	if err := ed25519.Validate(pub); nil != err {
+		return nil, nil, err
+	}
+	A, err := new(edwards25519.Point).SetBytes(pub)
+	if nil != err {
+		return nil, nil, ec.ErrNotOnCurve
+	}
+
+	session := &UserSession{pub: append([]byte(nil), pub...), A: A}
+	ch := new(Challenge)
+
+	for i := range cmt.R {
+		R, ok := ed25519.DecodePoint(cmt.R[i][:])
+		if !ok {
+			return nil, nil, ErrInvalidMessage
+		}
+
+		alpha, err := randomScalar()
+		if nil != err {
+			return nil, nil, err
+		}
+		beta, err := randomScalar()
+		if nil != err {
+			return nil, nil, err
+		}
+
+		// R' = R + aG + bA and c = H(R' || A || msg) + b
+		blindedR := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(beta, A, alpha)
+		blindedR.Add(blindedR, R)
+		session.blindedR[i] = blindedR.Bytes()
+
+		c := challenge(session.blindedR[i], session.pub, msg)
+		c.Add(c, beta)
+
+		session.alpha[i], session.R[i], session.c[i] = alpha, R, c
+		copy(ch.C[i][:], c.Bytes())
+	}
+
+	return session, ch, nil
+}
+
+// Unblind checks the answer of the signer, i.e. sG = R + cA, and unblinds
+// it into the Ed25519 signature R' || s + a
+func (session *UserSession) Unblind(resp *Response) (ec.Sig, error) {
+	if (resp.Clause < 0) || (resp.Clause >= clauses) {
+		return nil, ErrInvalidMessage
+	}
+	i := resp.Clause
+
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(resp.S[:])
+	if nil != err {
+		return nil, ErrInvalidMessage
+	}
+
+	want := new(edwards25519.Point).ScalarMult(session.c[i], session.A)
+	want.Add(want, session.R[i])
+	if 1 != new(edwards25519.Point).ScalarBaseMult(s).Equal(want) {
+		return nil, ErrInvalidResponse
+	}
+
+	sig := make([]byte, 0, 64)
+	sig = append(sig, session.blindedR[i]...)
+	sig = append(sig, s.Add(s, session.alpha[i]).Bytes()...)
+
+	return sig, nil
+}
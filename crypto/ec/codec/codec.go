@@ -0,0 +1,183 @@
+// Package codec versions the blobs produced by the marshallers, so that the
+// keys and signatures stored in a former format stay readable after a change
+package codec
+
+// Note:
+// + a blob is version || body, where the layout of the body is up to the
+//   version
+// + a Codec encodes in its current version only, but decodes every version
+//   registered to it, so that a format change only adds a decoder and bumps
+//   the current version
+// + Migrate decodes a blob of any registered version and encodes it again in
+//   the current one
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/sammy00/gravity/crypto/ec"
+)
+
+// ErrUnrecognized indicates the blob is of a known version but none of the
+// codecs of the set can decode it
+var ErrUnrecognized = errors.New("the blob isn't recognized by any codec")
+
+// Encoder encodes v as the body of a blob of the current version
+type Encoder func(v interface{}) ([]byte, error)
+
+// Decoder decodes the body of a blob following its version byte
+type Decoder func(body []byte) (interface{}, error)
+
+// Codec marshals a kind of values, i.e. private keys, public keys or
+// signatures of an algorithm
+type Codec struct {
+	current  byte
+	encoder  Encoder
+	decoders map[byte]Decoder
+}
+
+// New makes a codec encoding in the version current by encoder, and
+// decoding it by decoder
+func New(current byte, encoder Encoder, decoder Decoder) *Codec {
+	c := &Codec{current: current, encoder: encoder, decoders: make(map[byte]Decoder)}
+
+	return c.Register(current, decoder)
+}
+
+// Register adds the decoder of a historical version, and panics if the
+// version is registered already
+func (c *Codec) Register(version byte, decoder Decoder) *Codec {
+	if _, ok := c.decoders[version]; ok {
+		panic("codec: version registered twice")
+	}
+	c.decoders[version] = decoder
+
+	return c
+}
+
+// Current returns the version of the blobs made by Marshal
+func (c *Codec) Current() byte {
+	return c.current
+}
+
+// Versions returns the decodable versions in ascending order
+func (c *Codec) Versions() []byte {
+	versions := make([]byte, 0, len(c.decoders))
+	for v := range c.decoders {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	return versions
+}
+
+// Marshal encodes v as a blob of the current version
+func (c *Codec) Marshal(v interface{}) ([]byte, error) {
+	body, err := c.encoder(v)
+	if nil != err {
+		return nil, err
+	}
+
+	return append([]byte{c.current}, body...), nil
+}
+
+// Unmarshal decodes blob of any registered version
+func (c *Codec) Unmarshal(blob []byte) (interface{}, error) {
+	if 0 == len(blob) {
+		return nil, ec.ErrWrongVersion
+	}
+
+	decoder, ok := c.decoders[blob[0]]
+	if !ok {
+		return nil, ec.ErrWrongVersion
+	}
+
+	return decoder(blob[1:])
+}
+
+// Migrate upgrades blob of any registered version to the current one
+func (c *Codec) Migrate(blob []byte) ([]byte, error) {
+	v, err := c.Unmarshal(blob)
+	if nil != err {
+		return nil, err
+	}
+
+	return c.Marshal(v)
+}
+
+// Set groups the codecs of the blobs of an algorithm
+type Set struct {
+	PrivKey, PubKey, Sig *Codec
+}
+
+// Migrate upgrades blob to the current version of its kind, trying the
+// private keys, the public keys and the signatures in turn
+func (s *Set) Migrate(blob []byte) ([]byte, error) {
+	err := ec.ErrWrongVersion
+	for _, c := range []*Codec{s.PrivKey, s.PubKey, s.Sig} {
+		if nil == c {
+			continue
+		}
+
+		out, e := c.Migrate(blob)
+		switch {
+		case nil == e:
+			return out, nil
+		case ec.ErrWrongVersion != e:
+			err = ErrUnrecognized
+		}
+	}
+
+	return nil, err
+}
+
+var (
+	mu       sync.RWMutex
+	registry = make(map[string]*Set)
+)
+
+// Register makes the codecs of the algorithm name available to Lookup, and
+// panics if the name is registered already
+func Register(name string, set *Set) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := registry[name]; ok {
+		panic("codec: " + name + " registered twice")
+	}
+	registry[name] = set
+}
+
+// Lookup finds the codecs registered as name
+func Lookup(name string) (*Set, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	set, ok := registry[name]
+	return set, ok
+}
+
+// Names returns the registered algorithms in ascending order
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Migrate upgrades blob of the algorithm name to its current version
+func Migrate(name string, blob []byte) ([]byte, error) {
+	set, ok := Lookup(name)
+	if !ok {
+		return nil, ec.ErrECTypeUnsupported
+	}
+
+	return set.Migrate(blob)
+}
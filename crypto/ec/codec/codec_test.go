@@ -0,0 +1,111 @@
+package codec_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/codec"
+)
+
+// the toy codec stores strings, as is in version 2 and reversed in version 1
+func encodeString(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, ec.ErrKeyTampered
+	}
+
+	return []byte(s), nil
+}
+
+func decodeString(body []byte) (interface{}, error) {
+	if 0 == len(body) {
+		return nil, ec.ErrMalformedKey
+	}
+
+	return string(body), nil
+}
+
+func decodeReversed(body []byte) (interface{}, error) {
+	reversed := make([]byte, len(body))
+	for i, b := range body {
+		reversed[len(body)-1-i] = b
+	}
+
+	return decodeString(reversed)
+}
+
+func newToyCodec() *codec.Codec {
+	return codec.New(2, encodeString, decodeString).Register(1, decodeReversed)
+}
+
+func TestCodec(t *testing.T) {
+	c := newToyCodec()
+
+	if versions := c.Versions(); !bytes.Equal([]byte{1, 2}, versions) {
+		t.Fatalf("want versions [1 2], got %v", versions)
+	}
+
+	blob, err := c.Marshal("gravity")
+	if nil != err {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(append([]byte{2}, "gravity"...), blob) {
+		t.Fatalf("unexpected blob %x", blob)
+	}
+
+	for i, data := range [][]byte{blob, append([]byte{1}, "ytivarg"...)} {
+		v, err := c.Unmarshal(data)
+		if (nil != err) || ("gravity" != v) {
+			t.Fatalf("#%d: want gravity, got %v (%v)", i, v, err)
+		}
+		if out, err := c.Migrate(data); (nil != err) || !bytes.Equal(blob, out) {
+			t.Fatalf("#%d: the migration fails: %v", i, err)
+		}
+	}
+
+	testCases := []struct {
+		data   []byte
+		expect error
+	}{
+		{nil, ec.ErrWrongVersion},
+		{[]byte{3, 'g'}, ec.ErrWrongVersion},
+		{[]byte{1}, ec.ErrMalformedKey},
+	}
+	for i, tc := range testCases {
+		if _, err := c.Unmarshal(tc.data); tc.expect != err {
+			t.Fatalf("#%d: want %v, got %v", i, tc.expect, err)
+		}
+		if _, err := c.Migrate(tc.data); tc.expect != err {
+			t.Fatalf("#%d: want %v, got %v", i, tc.expect, err)
+		}
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	defer func() {
+		if nil == recover() {
+			t.Fatal("registering a version twice should panic")
+		}
+	}()
+
+	newToyCodec().Register(2, decodeString)
+}
+
+func TestSetMigrate(t *testing.T) {
+	set := &codec.Set{PubKey: newToyCodec()}
+
+	if out, err := set.Migrate(append([]byte{1}, "ytivarg"...)); (nil != err) || ("\x02gravity" != string(out)) {
+		t.Fatalf("the migration fails: %v", err)
+	}
+	if _, err := set.Migrate([]byte{3}); ec.ErrWrongVersion != err {
+		t.Fatalf("want %v, got %v", ec.ErrWrongVersion, err)
+	}
+	if _, err := set.Migrate([]byte{1}); codec.ErrUnrecognized != err {
+		t.Fatalf("want %v, got %v", codec.ErrUnrecognized, err)
+	}
+
+	if _, err := codec.Migrate("unknown", []byte{1}); ec.ErrECTypeUnsupported != err {
+		t.Fatalf("want %v, got %v", ec.ErrECTypeUnsupported, err)
+	}
+}
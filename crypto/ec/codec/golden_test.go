@@ -0,0 +1,109 @@
+package codec_test
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/codec"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+	"golang.org/x/crypto/sha3"
+)
+
+// goldenSigners tells how the golden signature of every algorithm is made
+var goldenSigners = map[string]struct {
+	worker ec.Worker
+	digest func() []byte
+}{
+	"ecdsa256":   {new(ecdsa.Worker256), sha3Digest},
+	"ecdsa512":   {new(ecdsa.Worker512), sha3Digest},
+	"secp256k1":  {secp.New(), sha3Digest},
+	"ed25519":    {new(ed25519.Worker), sha3Digest},
+	"ed25519ph":  {new(ed25519.WorkerPh), sha512Digest},
+	"ed25519ctx": {&ed25519.WorkerCtx{Context: "gravity"}, func() []byte { return []byte("Hello World") }},
+}
+
+func sha3Digest() []byte {
+	digest := sha3.Sum256([]byte("Hello World"))
+	return digest[:]
+}
+
+func sha512Digest() []byte {
+	digest := sha512.Sum512([]byte("Hello World"))
+	return digest[:]
+}
+
+// readGolden loads testdata/golden/<name>/<kind>.v<version>.hex
+func readGolden(t *testing.T, name, kind string, version byte) []byte {
+	path := filepath.Join("testdata", "golden", name, fmt.Sprintf("%s.v%d.hex", kind, version))
+	data, err := ioutil.ReadFile(path)
+	if nil != err {
+		t.Fatalf("every version must have its golden blob: %v", err)
+	}
+
+	blob, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	return blob
+}
+
+// TestGolden checks the blobs of every version ever written, which are
+// frozen in testdata/golden as the same key and signature of each algorithm,
+// so a format change adds the blobs of its version and never touches the
+// former ones
+func TestGolden(t *testing.T) {
+	for _, name := range codec.Names() {
+		signer, ok := goldenSigners[name]
+		if !ok {
+			t.Fatalf("%s: no golden blobs", name)
+		}
+		set, _ := codec.Lookup(name)
+
+		kinds := []struct {
+			name  string
+			codec *codec.Codec
+		}{
+			{"privkey", set.PrivKey}, {"pubkey", set.PubKey}, {"sig", set.Sig},
+		}
+
+		values := make(map[string]interface{})
+		for _, k := range kinds {
+			current := readGolden(t, name, k.name, k.codec.Current())
+
+			v, err := k.codec.Unmarshal(current)
+			if nil != err {
+				t.Fatalf("%s/%s: %v", name, k.name, err)
+			}
+			if out, err := k.codec.Marshal(v); (nil != err) || !bytes.Equal(current, out) {
+				t.Fatalf("%s/%s: the encoding of the current version changes: %v", name, k.name, err)
+			}
+			values[k.name] = v
+
+			for _, version := range k.codec.Versions() {
+				blob := readGolden(t, name, k.name, version)
+				if out, err := k.codec.Migrate(blob); (nil != err) || !bytes.Equal(current, out) {
+					t.Fatalf("%s/%s.v%d: the migration fails: %v", name, k.name, version, err)
+				}
+				if out, err := set.Migrate(blob); (nil != err) || !bytes.Equal(current, out) {
+					t.Fatalf("%s/%s.v%d: the migration fails: %v", name, k.name, version, err)
+				}
+			}
+		}
+
+		priv := values["privkey"].(ec.PrivateKey)
+		pub := values["pubkey"].(ec.PublicKey)
+		sig := values["sig"].(ec.Sig)
+		if !signer.worker.Verify(pub, signer.digest(), sig) || !signer.worker.Verify(priv.Public(), signer.digest(), sig) {
+			t.Fatalf("%s: the golden signature doesn't verify", name)
+		}
+	}
+}
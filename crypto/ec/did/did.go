@@ -0,0 +1,146 @@
+// Package did implements the did:key identifiers of the public keys made by
+// ed25519.Worker, ecdsa.Worker256 and secp.Worker, and resolves them into
+// DID Documents
+package did
+
+// Note:
+// + a did:key is "did:key:" followed by the base58btc multibase of the
+//   multicodec public key, e.g. did:key:z6Mk... for Ed25519, did:key:zDn...
+//   for P-256 and did:key:zQ3s... for secp256k1
+// + the document holds a single verification method of type Multikey, whose
+//   fragment is the multibase value, referred by every verification
+//   relationship
+// + the X25519 key agreement derived from Ed25519 keys isn't produced
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/sammy00/gravity/crypto/ec"
+)
+
+var (
+	// ErrInvalidDID indicates the identifier isn't a did:key
+	ErrInvalidDID = errors.New("did: invalid did:key")
+	// ErrMalformed indicates the multibase or multicodec data is malformed
+	ErrMalformed = errors.New("did: malformed encoding")
+	// ErrUnsupportedBase indicates the multibase prefix isn't supported
+	ErrUnsupportedBase = errors.New("did: unsupported multibase")
+	// ErrUnsupportedCodec indicates the multicodec code isn't a supported key
+	ErrUnsupportedCodec = errors.New("did: unsupported multicodec")
+)
+
+const (
+	prefix = "did:key:"
+	// MultikeyType is the type of the verification methods
+	MultikeyType = "Multikey"
+)
+
+// the JSON-LD contexts of the documents
+var contexts = []string{
+	"https://www.w3.org/ns/did/v1",
+	"https://w3id.org/security/multikey/v1",
+}
+
+// VerificationMethod is a public key of a DID Document
+type VerificationMethod struct {
+	ID                 string `json:"id"`
+	Type               string `json:"type"`
+	Controller         string `json:"controller"`
+	PublicKeyMultibase string `json:"publicKeyMultibase"`
+}
+
+// PublicKey decodes the key of vm into the key type of its worker
+func (vm *VerificationMethod) PublicKey() (ec.PublicKey, error) {
+	if MultikeyType != vm.Type {
+		return nil, ErrUnsupportedCodec
+	}
+
+	data, err := MultibaseDecode(vm.PublicKeyMultibase)
+	if nil != err {
+		return nil, err
+	}
+
+	return DecodeMulticodec(data)
+}
+
+// Document is the DID Document of a did:key
+type Document struct {
+	Context              []string             `json:"@context"`
+	ID                   string               `json:"id"`
+	VerificationMethod   []VerificationMethod `json:"verificationMethod"`
+	Authentication       []string             `json:"authentication"`
+	AssertionMethod      []string             `json:"assertionMethod"`
+	CapabilityInvocation []string             `json:"capabilityInvocation"`
+	CapabilityDelegation []string             `json:"capabilityDelegation"`
+}
+
+// Encode makes the did:key of pubKey
+func Encode(pubKey ec.PublicKey) (string, error) {
+	data, err := EncodeMulticodec(pubKey)
+	if nil != err {
+		return "", err
+	}
+
+	value, err := MultibaseEncode(Base58BTC, data)
+	if nil != err {
+		return "", err
+	}
+
+	return prefix + value, nil
+}
+
+// Parse decodes the public key identified by did
+func Parse(did string) (ec.PublicKey, error) {
+	value, err := methodSpecificID(did)
+	if nil != err {
+		return nil, err
+	}
+
+	data, err := MultibaseDecode(value)
+	if nil != err {
+		return nil, err
+	}
+
+	return DecodeMulticodec(data)
+}
+
+// Resolve resolves did into its document, checking the key it identifies
+func Resolve(did string) (*Document, error) {
+	if _, err := Parse(did); nil != err {
+		return nil, err
+	}
+	value, _ := methodSpecificID(did)
+
+	vmID := did + "#" + value
+
+	return &Document{
+		Context: append([]string(nil), contexts...),
+		ID:      did,
+		VerificationMethod: []VerificationMethod{{
+			ID:                 vmID,
+			Type:               MultikeyType,
+			Controller:         did,
+			PublicKeyMultibase: value,
+		}},
+		Authentication:       []string{vmID},
+		AssertionMethod:      []string{vmID},
+		CapabilityInvocation: []string{vmID},
+		CapabilityDelegation: []string{vmID},
+	}, nil
+}
+
+// methodSpecificID returns the multibase value of did, which did:key
+// requires in base58btc
+func methodSpecificID(did string) (string, error) {
+	if !strings.HasPrefix(did, prefix) {
+		return "", ErrInvalidDID
+	}
+
+	value := did[len(prefix):]
+	if (0 == len(value)) || (Base58BTC != value[0]) {
+		return "", ErrInvalidDID
+	}
+
+	return value, nil
+}
@@ -0,0 +1,146 @@
+package did_test
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/did"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// the test vectors of the did:key specification
+var vectors = []struct {
+	did    string
+	worker ec.Worker
+}{
+	{"did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK", new(ed25519.Worker)},
+	{"did:key:zDnaerDaTF5BXEavCrfRZEk316dpbLsfPDZ3WJ5hRTPFU2169", new(ecdsa.Worker256)},
+	{"did:key:zQ3shokFTS3brHcDQrn82RUDfCZESWL1ZdCEJwekUDPQiYBme", secp.New()},
+}
+
+func TestParseVectors(t *testing.T) {
+	for i, c := range vectors {
+		pub, err := did.Parse(c.did)
+		if nil != err {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+
+		// the key is of the type the worker produces
+		priv, err := c.worker.GenerateKey(rand.Reader)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if want, got := typeOf(priv.Public()), typeOf(pub); want != got {
+			t.Fatalf("#%d: want %s, got %s", i, want, got)
+		}
+
+		if got, err := did.Encode(pub); (nil != err) || (c.did != got) {
+			t.Fatalf("#%d: want %s, got %s (%v)", i, c.did, got, err)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	digest := sha256.Sum256([]byte("hello world"))
+	prefixes := []string{"did:key:z6Mk", "did:key:zDn", "did:key:zQ3s"}
+
+	for i, c := range vectors {
+		priv, err := c.worker.GenerateKey(rand.Reader)
+		if nil != err {
+			t.Fatal(err)
+		}
+
+		id, err := did.Encode(priv.Public())
+		if nil != err {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(id, prefixes[i]) {
+			t.Fatalf("#%d: want prefix %s, got %s", i, prefixes[i], id)
+		}
+
+		doc, err := did.Resolve(id)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if (id != doc.ID) || (1 != len(doc.VerificationMethod)) {
+			t.Fatalf("#%d: unexpected document %+v", i, doc)
+		}
+
+		vm := doc.VerificationMethod[0]
+		want := id + "#" + strings.TrimPrefix(id, "did:key:")
+		if (want != vm.ID) || (id != vm.Controller) || (did.MultikeyType != vm.Type) {
+			t.Fatalf("#%d: unexpected verification method %+v", i, vm)
+		}
+		for _, refs := range [][]string{doc.Authentication, doc.AssertionMethod, doc.CapabilityInvocation, doc.CapabilityDelegation} {
+			if (1 != len(refs)) || (vm.ID != refs[0]) {
+				t.Fatalf("#%d: want the relationship %s, got %v", i, vm.ID, refs)
+			}
+		}
+
+		// the method maps back to a key the worker verifies with
+		pub, err := vm.PublicKey()
+		if nil != err {
+			t.Fatal(err)
+		}
+		sig, err := c.worker.Sign(priv, digest[:])
+		if nil != err {
+			t.Fatal(err)
+		}
+		if !c.worker.Verify(pub, digest[:], sig) {
+			t.Fatalf("#%d: the verification shouldn't fail", i)
+		}
+
+		data, err := json.Marshal(doc)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), `"publicKeyMultibase":"`+vm.PublicKeyMultibase+`"`) {
+			t.Fatalf("#%d: unexpected JSON %s", i, data)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	// the code 0x1205 of the RSA keys
+	rsa, err := did.MultibaseEncode(did.Base58BTC, append([]byte{0x85, 0x24}, make([]byte, 270)...))
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		did string
+		err error
+	}{
+		{"did:web:example.com", did.ErrInvalidDID},
+		{"did:key:", did.ErrInvalidDID},
+		{"did:key:u7QE", did.ErrInvalidDID},
+		{"did:key:z0OIl", did.ErrMalformed},
+		{"did:key:" + rsa, did.ErrUnsupportedCodec},
+	}
+
+	for i, c := range testCases {
+		if _, err := did.Parse(c.did); c.err != err {
+			t.Fatalf("#%d: want %v, got %v", i, c.err, err)
+		}
+		if _, err := did.Resolve(c.did); c.err != err {
+			t.Fatalf("#%d: want %v, got %v", i, c.err, err)
+		}
+	}
+}
+
+func typeOf(pub ec.PublicKey) string {
+	switch pub.(type) {
+	case ed25519.PublicKey:
+		return "ed25519"
+	case *ecdsa.PublicKey:
+		return "ecdsa"
+	}
+
+	return "unknown"
+}
@@ -0,0 +1,50 @@
+package did
+
+import (
+	"encoding/base64"
+
+	"github.com/sammy00/gravity/crypto/ec/address"
+)
+
+// the multibase prefixes of the supported bases
+const (
+	// Base58BTC is base58 of the Bitcoin alphabet
+	Base58BTC = 'z'
+	// Base64URL is the base64 of URLs without padding
+	Base64URL = 'u'
+)
+
+// MultibaseEncode encodes data in base, prefixed by the character of base
+func MultibaseEncode(base byte, data []byte) (string, error) {
+	switch base {
+	case Base58BTC:
+		return string(base) + address.Base58Encode(data), nil
+	case Base64URL:
+		return string(base) + base64.RawURLEncoding.EncodeToString(data), nil
+	}
+
+	return "", ErrUnsupportedBase
+}
+
+// MultibaseDecode decodes s in the base its first character names
+func MultibaseDecode(s string) ([]byte, error) {
+	if 0 == len(s) {
+		return nil, ErrMalformed
+	}
+
+	var data []byte
+	var err error
+	switch s[0] {
+	case Base58BTC:
+		data, err = address.Base58Decode(s[1:])
+	case Base64URL:
+		data, err = base64.RawURLEncoding.Strict().DecodeString(s[1:])
+	default:
+		return nil, ErrUnsupportedBase
+	}
+	if nil != err {
+		return nil, ErrMalformed
+	}
+
+	return data, nil
+}
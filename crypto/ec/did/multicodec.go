@@ -0,0 +1,89 @@
+package did
+
+import (
+	"crypto/elliptic"
+	"encoding/binary"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// the multicodec codes of the public keys
+const (
+	CodecEd25519Pub   = 0xed
+	CodecP256Pub      = 0x1200
+	CodecSecp256k1Pub = 0xe7
+)
+
+// EncodeMulticodec encodes pubKey as the varint of its code followed by the
+// key, i.e. the 32 bytes of Ed25519 or the compressed SEC1 point of P-256 and
+// secp256k1
+func EncodeMulticodec(pubKey ec.PublicKey) ([]byte, error) {
+	var code uint64
+	var key []byte
+
+	switch pub := pubKey.(type) {
+	case ed25519.PublicKey:
+		if err := ed25519.Validate(pub); nil != err {
+			return nil, err
+		}
+		code, key = CodecEd25519Pub, pub
+	case *ecdsa.PublicKey:
+		switch {
+		case (nil != pub) && (elliptic.P256() == pub.Curve):
+			code = CodecP256Pub
+		case (nil != pub) && (nil != pub.Curve) && secp.IsS256(pub.Curve):
+			code = CodecSecp256k1Pub
+		default:
+			return nil, ec.ErrECTypeUnsupported
+		}
+
+		var err error
+		if key, err = ecdsa.MarshalSEC1(pub, true); nil != err {
+			return nil, err
+		}
+	default:
+		return nil, ec.ErrECTypeUnsupported
+	}
+
+	return append(binary.AppendUvarint(nil, code), key...), nil
+}
+
+// DecodeMulticodec decodes the public key data prefixed by its code into
+// the keys of ed25519.Worker, ecdsa.Worker256 and secp.Worker
+func DecodeMulticodec(data []byte) (ec.PublicKey, error) {
+	code, n := binary.Uvarint(data)
+	// the varints of multiformats are minimal
+	if (n <= 0) || (n != len(binary.AppendUvarint(nil, code))) {
+		return nil, ErrMalformed
+	}
+	key := data[n:]
+
+	switch code {
+	case CodecEd25519Pub:
+		pub := ed25519.PublicKey(append([]byte(nil), key...))
+		if err := ed25519.Validate(pub); nil != err {
+			return nil, err
+		}
+		return pub, nil
+	case CodecP256Pub:
+		return decodeCompressed(elliptic.P256(), key)
+	case CodecSecp256k1Pub:
+		return decodeCompressed(secp.S256(), key)
+	}
+
+	return nil, ErrUnsupportedCodec
+}
+
+// decodeCompressed decodes the compressed SEC1 point key, the only encoding
+// did:key admits for the curves
+func decodeCompressed(c elliptic.Curve, key []byte) (ec.PublicKey, error) {
+	size := (c.Params().BitSize + 7) / 8
+	if (1+size != len(key)) || ((0x02 != key[0]) && (0x03 != key[0])) {
+		return nil, ec.ErrMalformedKey
+	}
+
+	return ecdsa.UnmarshalSEC1(c, key)
+}
@@ -0,0 +1,119 @@
+package did_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/did"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+func TestMulticodecPrefix(t *testing.T) {
+	testCases := []struct {
+		worker ec.Worker
+		prefix []byte
+		size   int
+	}{
+		{new(ed25519.Worker), []byte{0xed, 0x01}, 32},
+		{new(ecdsa.Worker256), []byte{0x80, 0x24}, 33},
+		{secp.New(), []byte{0xe7, 0x01}, 33},
+	}
+
+	for i, c := range testCases {
+		priv, err := c.worker.GenerateKey(rand.Reader)
+		if nil != err {
+			t.Fatal(err)
+		}
+
+		data, err := did.EncodeMulticodec(priv.Public())
+		if nil != err {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(data, c.prefix) || (len(c.prefix)+c.size != len(data)) {
+			t.Fatalf("#%d: want %x and %d bytes, got %x", i, c.prefix, c.size, data)
+		}
+
+		pub, err := did.DecodeMulticodec(data)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if again, _ := did.EncodeMulticodec(pub); !bytes.Equal(data, again) {
+			t.Fatalf("#%d: want %x, got %x", i, data, again)
+		}
+	}
+}
+
+func TestMulticodecInvalid(t *testing.T) {
+	p521, err := new(ecdsa.Worker512).GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if _, err := did.EncodeMulticodec(p521.Public()); ec.ErrECTypeUnsupported != err {
+		t.Fatalf("want %v, got %v", ec.ErrECTypeUnsupported, err)
+	}
+
+	identity := make([]byte, 32)
+	identity[0] = 0x01
+
+	testCases := []struct {
+		data []byte
+		err  error
+	}{
+		{nil, did.ErrMalformed},
+		// the code 0xed encoded in three bytes instead of two
+		{append([]byte{0xed, 0x81, 0x00}, make([]byte, 32)...), did.ErrMalformed},
+		{append([]byte{0xed, 0x01}, identity...), ec.ErrPointAtInfinity},
+		{append([]byte{0xe7, 0x01}, make([]byte, 65)...), ec.ErrMalformedKey},
+		{[]byte{0x80, 0x24, 0x04}, ec.ErrMalformedKey},
+		{[]byte{0x12, 0x00}, did.ErrUnsupportedCodec},
+	}
+
+	for i, c := range testCases {
+		if _, err := did.DecodeMulticodec(c.data); c.err != err {
+			t.Fatalf("#%d: want %v, got %v", i, c.err, err)
+		}
+	}
+}
+
+func TestMultibase(t *testing.T) {
+	data := []byte{0x00, 0xed, 0x01, 0xff}
+
+	testCases := []struct {
+		base byte
+		want string
+	}{
+		{did.Base58BTC, "z12NcHU"},
+		{did.Base64URL, "uAO0B_w"},
+	}
+
+	for i, c := range testCases {
+		got, err := did.MultibaseEncode(c.base, data)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if c.want != got {
+			t.Fatalf("#%d: want %s, got %s", i, c.want, got)
+		}
+
+		decoded, err := did.MultibaseDecode(got)
+		if (nil != err) || !bytes.Equal(data, decoded) {
+			t.Fatalf("#%d: want %x, got %x (%v)", i, data, decoded, err)
+		}
+	}
+
+	if _, err := did.MultibaseEncode('f', data); did.ErrUnsupportedBase != err {
+		t.Fatalf("want %v, got %v", did.ErrUnsupportedBase, err)
+	}
+	if _, err := did.MultibaseDecode("fed01"); did.ErrUnsupportedBase != err {
+		t.Fatalf("want %v, got %v", did.ErrUnsupportedBase, err)
+	}
+	for _, s := range []string{"", "uAO0B_w=", "u+w", "z0"} {
+		if _, err := did.MultibaseDecode(s); did.ErrMalformed != err {
+			t.Fatalf("%q: want %v, got %v", s, did.ErrMalformed, err)
+		}
+	}
+}
@@ -22,6 +22,25 @@ var (
 	ErrKeyTampered = errors.New("the key provided has been tampered")
 	//ErrWrongVersion indicates the unmarshal version doesn't match the marshal version
 	ErrWrongVersion = errors.New("Mismatching unmarshal version")
+	// ErrMalformedKey indicates the bytes to unmarshal aren't a well-formed key
+	ErrMalformedKey = errors.New("malformed key encoding")
+	// ErrInvalidKey indicates the unmarshalled key isn't a valid key of its curve
+	ErrInvalidKey = errors.New("invalid key")
+	// ErrMalformedSig indicates the bytes to unmarshal aren't a well-formed signature
+	ErrMalformedSig = errors.New("malformed signature encoding")
+	// ErrNotOnCurve indicates the public key isn't a point of its curve
+	ErrNotOnCurve = errors.New("the point isn't on the curve")
+	// ErrPointAtInfinity indicates the public key is the identity element
+	ErrPointAtInfinity = errors.New("the point is at infinity")
+	// ErrSmallOrder indicates the public key lies in a small subgroup
+	ErrSmallOrder = errors.New("the point is of small order")
+)
+
+// identifiers of the curves tagging the marshalled public keys
+const (
+	CurveP256      byte = 1
+	CurveP521      byte = 2
+	CurveSecp256k1 byte = 3
 )
 
 type PublicKey = crypto.PublicKey
@@ -33,6 +52,12 @@ type PrivateKey interface {
 // Sig is an alias for []byte for readability
 type Sig = []byte
 
+// Validator is implemented by workers able to check whether a public key is a
+// valid point of their curve, which all the unmarshal paths do automatically
+type Validator interface {
+	Validate(pubKey PublicKey) error
+}
+
 // Worker specifies the api for ec package
 type Worker interface {
 	// GenerateKey generates a (priv,pub) EC key pair
@@ -5,7 +5,6 @@ import (
 	stdEcdsa "crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
-	"encoding/asn1"
 	"io"
 	"math/big"
 
@@ -18,7 +17,14 @@ type PublicKey = stdEcdsa.PublicKey
 // PrivateKey aliases standard private key
 type PrivateKey = stdEcdsa.PrivateKey
 
-type worker struct{}
+type worker struct {
+	// Strict makes Verify refuse public keys failing Validate and the
+	// signatures with a high s
+	Strict bool
+	// Encoding selects the encoding of the signatures made by Sign and
+	// accepted by Verify, defaulting to EncodingDER
+	Encoding SigEncoding
+}
 
 type ecdsaSig struct {
 	R, S *big.Int
@@ -31,21 +37,32 @@ func (ed worker) Sign(privKey ec.PrivateKey, digest []byte) (ec.Sig, error) {
 	if !ok {
 		return nil, ec.ErrKeyTampered
 	}
-	return ecdsaPrivKey.Sign(rand.Reader, digest, nil)
+	sig, err := ecdsaPrivKey.Sign(rand.Reader, digest, nil)
+	if nil != err {
+		return nil, err
+	}
+
+	if sig, err = NormalizeSig(ecdsaPrivKey.Curve, sig); (nil != err) || (EncodingP1363 != ed.Encoding) {
+		return sig, err
+	}
+
+	return ToP1363(ecdsaPrivKey.Curve, sig)
 }
 
-// Verify verifies the signature in sig of hash using the public key, pubKey.
-// Its return value records whether the signature is valid.
-func (ed worker) Verify(pubKey ec.PublicKey, digest []byte, sig ec.Sig) bool {
+// verify verifies the signature in sig of hash using the public key, pubKey,
+// which must be over c under Strict
+func (ed worker) verify(c elliptic.Curve, pubKey ec.PublicKey, digest []byte, sig ec.Sig) bool {
 	ecdsaPubKey, ok := pubKey.(*PublicKey)
-	if !ok {
+	if !ok || (ed.Strict && (nil != validateOver(c, ecdsaPubKey))) {
 		return false
 	}
 	// unmarshal the signature for verification
-	var decodedSig ecdsaSig
-	_, err := asn1.Unmarshal(sig, &decodedSig)
+	decodedSig, err := ed.Encoding.decode(ecdsaPubKey.Curve, sig)
+	if (nil != err) || (ed.Strict && !IsLowS(ecdsaPubKey.Curve, decodedSig.S)) {
+		return false
+	}
 	// verify the signature (r,s) on the digest by the corresponding public key
-	return (nil == err) && stdEcdsa.Verify(ecdsaPubKey, digest, decodedSig.R, decodedSig.S)
+	return stdEcdsa.Verify(ecdsaPubKey, digest, decodedSig.R, decodedSig.S)
 }
 
 func generateKey(c elliptic.Curve, rand io.Reader) (ec.PrivateKey, error) {
@@ -69,6 +86,12 @@ func (ecdsa256 *Worker256) GenerateKey(rand io.Reader) (ec.PrivateKey, error) {
 	return generateKey(elliptic.P256(), rand)
 }
 
+// Verify verifies the signature in sig of hash using the public key, pubKey.
+// Its return value records whether the signature is valid.
+func (ecdsa256 *Worker256) Verify(pubKey ec.PublicKey, digest []byte, sig ec.Sig) bool {
+	return ecdsa256.verify(elliptic.P256(), pubKey, digest, sig)
+}
+
 // Worker512 implements ecdsa-256
 type Worker512 struct {
 	worker
@@ -78,3 +101,9 @@ type Worker512 struct {
 func (ecdsa512 *Worker512) GenerateKey(rand io.Reader) (ec.PrivateKey, error) {
 	return generateKey(elliptic.P521(), rand)
 }
+
+// Verify verifies the signature in sig of hash using the public key, pubKey.
+// Its return value records whether the signature is valid.
+func (ecdsa512 *Worker512) Verify(pubKey ec.PublicKey, digest []byte, sig ec.Sig) bool {
+	return ecdsa512.verify(elliptic.P521(), pubKey, digest, sig)
+}
@@ -10,10 +10,11 @@ import (
 
 func TestECDSA256(t *testing.T) {
 	wkr256 := new(ecdsa.Worker256)
-	priv, pub, err := wkr256.GenerateKey(rand.Reader)
+	priv, err := wkr256.GenerateKey(rand.Reader)
 	if nil != err {
 		t.Fatal(err)
 	}
+	pub := priv.Public()
 
 	rawMsg := []byte("Hello World")
 	digest := sha3.Sum256(rawMsg)
@@ -37,10 +38,11 @@ func TestECDSA256(t *testing.T) {
 
 func TestECDSA512(t *testing.T) {
 	wkr512 := new(ecdsa.Worker512)
-	priv, pub, err := wkr512.GenerateKey(rand.Reader)
+	priv, err := wkr512.GenerateKey(rand.Reader)
 	if nil != err {
 		t.Fatal(err)
 	}
+	pub := priv.Public()
 
 	rawMsg := []byte("Hello World")
 	digest := sha3.Sum256(rawMsg)
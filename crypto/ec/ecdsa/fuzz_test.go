@@ -0,0 +1,108 @@
+package ecdsa_test
+
+import (
+	"bytes"
+	"crypto"
+	stdEcdsa "crypto/ecdsa"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"golang.org/x/crypto/sha3"
+)
+
+type marshalWorker interface {
+	ec.Worker
+	MarshalPrivKey(privKey crypto.PrivateKey) ([]byte, error)
+	UnmarshalPrivKey(privKeyBytes []byte) (crypto.PrivateKey, error)
+	MarshalPubKey(pubKey crypto.PublicKey) ([]byte, error)
+	UnmarshalPubKey(pubKeyBytes []byte) (crypto.PublicKey, error)
+	MarshalSig(sig ec.Sig) ([]byte, error)
+	UnmarshalSig(sigBytes []byte) (ec.Sig, error)
+}
+
+// every input is fed to the workers of both curves
+var fuzzWorkers = []marshalWorker{new(ecdsa.Worker256), new(ecdsa.Worker512)}
+
+// checkErr fails unless err is one of the typed errors of the decoders
+func checkErr(t *testing.T, err error) {
+	switch err {
+	case ec.ErrWrongVersion, ec.ErrMalformedKey, ec.ErrInvalidKey,
+		ec.ErrNotOnCurve, ec.ErrPointAtInfinity, ec.ErrSmallOrder, ec.ErrMalformedSig, ec.ErrECTypeUnsupported:
+	default:
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func FuzzUnmarshalPrivKey(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, worker := range fuzzWorkers {
+			key, err := worker.UnmarshalPrivKey(data)
+			if nil != err {
+				checkErr(t, err)
+				continue
+			}
+
+			// a decoded key must sign verifiable signatures
+			priv := key.(*stdEcdsa.PrivateKey)
+			digest := sha3.Sum256([]byte("Hello World"))
+			sig, err := worker.Sign(priv, digest[:])
+			if nil != err {
+				t.Fatal(err)
+			}
+			if !worker.Verify(priv.Public(), digest[:], sig) {
+				t.Fatal("the verification shouldn't fail")
+			}
+
+			if out, err := worker.MarshalPrivKey(priv); nil != err || !bytes.Equal(data, out) {
+				t.Fatalf("the round trip changes the key: %v", err)
+			}
+		}
+	})
+}
+
+func FuzzUnmarshalPubKey(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, worker := range fuzzWorkers {
+			key, err := worker.UnmarshalPubKey(data)
+			if nil != err {
+				checkErr(t, err)
+				continue
+			}
+
+			pub := key.(*stdEcdsa.PublicKey)
+			if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+				t.Fatal("the decoded point isn't on the curve")
+			}
+
+			// the keys of the former versions and the uncompressed points are
+			// marshalled in the latest version as compressed points
+			out, err := worker.MarshalPubKey(pub)
+			if nil != err {
+				t.Fatal(err)
+			}
+			if (data[0] == out[0]) && (len(data) == len(out)) && !bytes.Equal(data, out) {
+				t.Fatal("the round trip changes the key")
+			}
+			if again, err := worker.UnmarshalPubKey(out); (nil != err) || !pub.Equal(again) {
+				t.Fatalf("the round trip changes the key: %v", err)
+			}
+		}
+	})
+}
+
+func FuzzUnmarshalSig(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, worker := range fuzzWorkers {
+			sig, err := worker.UnmarshalSig(data)
+			if nil != err {
+				checkErr(t, err)
+				continue
+			}
+
+			if out, err := worker.MarshalSig(sig); nil != err || !bytes.Equal(data, out) {
+				t.Fatalf("the round trip changes the signature: %v", err)
+			}
+		}
+	})
+}
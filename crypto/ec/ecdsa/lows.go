@@ -0,0 +1,63 @@
+package ecdsa
+
+// Note:
+// + (r, n-s) is as valid as (r, s), so anyone may flip s of a signature
+//   without the private key, changing the bytes signed in a transaction
+// + signatures made by the workers carry the low s in [1, n/2] only, and the
+//   strict verification refuses the high s after BIP-62 and BIP-146
+// + encodings other than the canonical DER are always refused
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"math/big"
+
+	"github.com/sammy00/gravity/crypto/ec"
+)
+
+// IsLowS tells whether s lies in [1, n/2] for the order n of c
+func IsLowS(c elliptic.Curve, s *big.Int) bool {
+	halfN := new(big.Int).Rsh(c.Params().N, 1)
+
+	return (s.Sign() > 0) && (s.Cmp(halfN) <= 0)
+}
+
+// NormalizeSig re-encodes the signature sig over c as the canonical DER with
+// the low s, which tolerates the data trailing or appended to the sequence
+func NormalizeSig(c elliptic.Curve, sig ec.Sig) (ec.Sig, error) {
+	var decodedSig ecdsaSig
+	if _, err := asn1.Unmarshal(sig, &decodedSig); nil != err {
+		return nil, ec.ErrMalformedSig
+	}
+
+	n := c.Params().N
+	if (decodedSig.R.Sign() <= 0) || (decodedSig.S.Sign() <= 0) || (decodedSig.S.Cmp(n) >= 0) {
+		return nil, ec.ErrMalformedSig
+	}
+	if !IsLowS(c, decodedSig.S) {
+		decodedSig.S.Sub(n, decodedSig.S)
+	}
+
+	return asn1.Marshal(decodedSig)
+}
+
+// decodeSig decodes sig, which must be the canonical DER of a sequence of
+// exactly the two positive integers r and s
+func decodeSig(sig ec.Sig) (*ecdsaSig, error) {
+	decodedSig := new(ecdsaSig)
+	if _, err := asn1.Unmarshal(sig, decodedSig); nil != err {
+		return nil, ec.ErrMalformedSig
+	}
+	if (decodedSig.R.Sign() <= 0) || (decodedSig.S.Sign() <= 0) {
+		return nil, ec.ErrMalformedSig
+	}
+
+	// the re-encoding differs for the trailing data, and for the elements
+	// appended to the sequence which asn1.Unmarshal skips
+	if der, err := asn1.Marshal(*decodedSig); (nil != err) || !bytes.Equal(der, sig) {
+		return nil, ec.ErrMalformedSig
+	}
+
+	return decodedSig, nil
+}
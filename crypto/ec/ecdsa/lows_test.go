@@ -0,0 +1,102 @@
+package ecdsa_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"golang.org/x/crypto/sha3"
+)
+
+type rs struct {
+	R, S *big.Int
+}
+
+func TestLowS(t *testing.T) {
+	strict256, strict512 := new(ecdsa.Worker256), new(ecdsa.Worker512)
+	strict256.Strict, strict512.Strict = true, true
+
+	testCases := []struct {
+		worker, strict ec.Worker
+	}{
+		{new(ecdsa.Worker256), strict256},
+		{new(ecdsa.Worker512), strict512},
+	}
+
+	digest := sha3.Sum256([]byte("Hello World"))
+	for i, c := range testCases {
+		priv, err := c.worker.GenerateKey(rand.Reader)
+		if nil != err {
+			t.Fatal(err)
+		}
+		curve := priv.(*ecdsa.PrivateKey).Curve
+
+		// half of the raw signatures would carry a high s
+		for j := 0; j < 16; j++ {
+			sig, err := c.worker.Sign(priv, digest[:])
+			if nil != err {
+				t.Fatal(err)
+			}
+
+			var decoded rs
+			if _, err := asn1.Unmarshal(sig, &decoded); nil != err {
+				t.Fatal(err)
+			}
+			if !ecdsa.IsLowS(curve, decoded.S) {
+				t.Fatalf("#%d-%d: the signature isn't normalized", i, j)
+			}
+			if !c.strict.Verify(priv.Public(), digest[:], sig) {
+				t.Fatalf("#%d-%d: the verification shouldn't fail", i, j)
+			}
+
+			// (r, n-s) passes the loose verification only
+			decoded.S.Sub(curve.Params().N, decoded.S)
+			flipped, _ := asn1.Marshal(decoded)
+			if !c.worker.Verify(priv.Public(), digest[:], flipped) {
+				t.Fatalf("#%d-%d: the verification shouldn't fail", i, j)
+			}
+			if c.strict.Verify(priv.Public(), digest[:], flipped) {
+				t.Fatalf("#%d-%d: the verification should fail", i, j)
+			}
+
+			if normalized, err := ecdsa.NormalizeSig(curve, flipped); nil != err || !bytes.Equal(sig, normalized) {
+				t.Fatalf("#%d-%d: want %x, got %x (%v)", i, j, sig, normalized, err)
+			}
+		}
+	}
+}
+
+func TestNonCanonicalDER(t *testing.T) {
+	worker := new(ecdsa.Worker256)
+	priv, err := worker.GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	digest := sha3.Sum256([]byte("Hello World"))
+	sig, err := worker.Sign(priv, digest[:])
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	// an integer appended to the sequence, whose length grows by 3
+	appended := append([]byte{sig[0], sig[1] + 3}, sig[2:]...)
+	appended = append(appended, 0x02, 0x01, 0x00)
+
+	testCases := [][]byte{
+		append(append([]byte{}, sig...), 0x00),
+		appended,
+	}
+	for i, bad := range testCases {
+		if worker.Verify(priv.Public(), digest[:], bad) {
+			t.Fatalf("#%d: the verification should fail", i)
+		}
+		if normalized, err := ecdsa.NormalizeSig(priv.(*ecdsa.PrivateKey).Curve, bad); nil != err || !bytes.Equal(sig, normalized) {
+			t.Fatalf("#%d: want %x, got %x (%v)", i, sig, normalized, err)
+		}
+	}
+}
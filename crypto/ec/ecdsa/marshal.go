@@ -5,13 +5,17 @@ import (
 	"crypto"
 	"crypto/elliptic"
 	"encoding/asn1"
-	"github.com/sammy00/gravity/crypto/ec"
 	"math/big"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/codec"
 )
 
 const (
-	marshalVersion   = 1
-	unmarshalVersion = 1
+	marshalVersion = 1
+	// public keys are marshalled as pubKeyVersion || curve || SEC1 compressed
+	// point since version 2, and as the ASN.1 integers (X, X, Y) before
+	pubKeyVersion = 2
 )
 
 // Marshaller works for ecdsa  to marshal/unmarshal the privKey, pubKey and sig
@@ -20,119 +24,241 @@ type ecdsaBigInt struct {
 	D, X, Y *big.Int
 }
 
-// MarshalPrivKey marshal privKey to []byte where byte[0] records the marshal version
-func (msller *worker) MarshalPrivKey(privKey crypto.PrivateKey) ([]byte, error) {
-	priv, ok := privKey.(*PrivateKey)
-	if !ok {
-		return nil, ec.ErrKeyTampered
-	}
-	result := make([]byte, 1)
-	result[0] = byte(marshalVersion)
-	bytes, err := asn1.Marshal(ecdsaBigInt{priv.D, priv.X, priv.Y})
-	if nil != err {
-		return nil, err
+// codecs256 and codecs512 version the blobs of Worker256 and Worker512
+var (
+	codecs256 = newCodecs(elliptic.P256(), ec.CurveP256)
+	codecs512 = newCodecs(elliptic.P521(), ec.CurveP521)
+)
+
+func init() {
+	codec.Register("ecdsa256", codecs256)
+	codec.Register("ecdsa512", codecs512)
+}
+
+func newCodecs(c elliptic.Curve, curve byte) *codec.Set {
+	return &codec.Set{
+		PrivKey: codec.New(marshalVersion, encodePrivKey(c), decodePrivKey(c)),
+		PubKey: codec.New(pubKeyVersion, encodePubKey(c, curve), decodePubKey(c, curve)).
+			Register(1, decodeLegacyPubKey(c)),
+		Sig: codec.New(marshalVersion, encodeSig, decodeAnySig(c)),
 	}
-	return append(result, bytes...), nil
+}
+
+// MarshalPrivKey marshal privKey to []byte where byte[0] records the marshal version
+func (msller *Worker256) MarshalPrivKey(privKey crypto.PrivateKey) ([]byte, error) {
+	return codecs256.PrivKey.Marshal(privKey)
+}
+
+// MarshalPrivKey marshal privKey to []byte where byte[0] records the marshal version
+func (msller *Worker512) MarshalPrivKey(privKey crypto.PrivateKey) ([]byte, error) {
+	return codecs512.PrivKey.Marshal(privKey)
 }
 
 // UnmarshalPrivKey unmarshal privKeyBytes to privKey
 func (msller *Worker256) UnmarshalPrivKey(privKeyBytes []byte) (crypto.PrivateKey, error) {
-	if len(privKeyBytes) == 0 || int(privKeyBytes[0]) != unmarshalVersion {
-		return nil, ec.ErrWrongVersion
-	}
-	privKey := new(PrivateKey)
-
-	ecdsaBigIntKey := new(ecdsaBigInt)
-	if _, err := asn1.Unmarshal(privKeyBytes[1:], ecdsaBigIntKey); nil != err {
-		return nil, err
-	}
-	privKey.D = ecdsaBigIntKey.D
-	privKey.X = ecdsaBigIntKey.X
-	privKey.Y = ecdsaBigIntKey.Y
-	privKey.PublicKey.Curve = elliptic.P256()
-	return privKey, nil
+	return codecs256.PrivKey.Unmarshal(privKeyBytes)
 }
 
 // UnmarshalPrivKey unmarshal privKeyBytes to privKey
 func (msller *Worker512) UnmarshalPrivKey(privKeyBytes []byte) (crypto.PrivateKey, error) {
-	if len(privKeyBytes) == 0 || int(privKeyBytes[0]) != unmarshalVersion {
-		return nil, ec.ErrWrongVersion
+	return codecs512.PrivKey.Unmarshal(privKeyBytes)
+}
+
+// MarshalPubKey marshal pubKey to []byte where byte[0] records the marshal
+// version and byte[1] the curve, followed by the compressed SEC1 point
+func (msller *Worker256) MarshalPubKey(pubKey crypto.PublicKey) ([]byte, error) {
+	return codecs256.PubKey.Marshal(pubKey)
+}
+
+// MarshalPubKey marshal pubKey to []byte where byte[0] records the marshal
+// version and byte[1] the curve, followed by the compressed SEC1 point
+func (msller *Worker512) MarshalPubKey(pubKey crypto.PublicKey) ([]byte, error) {
+	return codecs512.PubKey.Marshal(pubKey)
+}
+
+// UnmarshalPubKey unmarshal pubKeyBytes of any version to pubKey
+func (msller *Worker256) UnmarshalPubKey(pubKeyBytes []byte) (crypto.PublicKey, error) {
+	return codecs256.PubKey.Unmarshal(pubKeyBytes)
+}
+
+// UnmarshalPubKey unmarshal pubKeyBytes of any version to pubKey
+func (msller *Worker512) UnmarshalPubKey(pubKeyBytes []byte) (crypto.PublicKey, error) {
+	return codecs512.PubKey.Unmarshal(pubKeyBytes)
+}
+
+// MarshalSig marshal sig to []byte where byte[0] records the marshal version
+func (msller *worker) MarshalSig(sig ec.Sig) ([]byte, error) {
+	return codecs256.Sig.Marshal(sig)
+}
+
+// UnmarshalSig unmarshal sigBytes to sig in the encoding of the worker
+func (msller *Worker256) UnmarshalSig(sigBytes []byte) (ec.Sig, error) {
+	return msller.unmarshalSig(codecs256, elliptic.P256(), sigBytes)
+}
+
+// UnmarshalSig unmarshal sigBytes to sig in the encoding of the worker
+func (msller *Worker512) UnmarshalSig(sigBytes []byte) (ec.Sig, error) {
+	return msller.unmarshalSig(codecs512, elliptic.P521(), sigBytes)
+}
+
+// Migrate upgrades a private key, public key or signature blob of any
+// version to the current one
+func (msller *Worker256) Migrate(blob []byte) ([]byte, error) {
+	return codecs256.Migrate(blob)
+}
+
+// Migrate upgrades a private key, public key or signature blob of any
+// version to the current one
+func (msller *Worker512) Migrate(blob []byte) ([]byte, error) {
+	return codecs512.Migrate(blob)
+}
+
+func (msller *worker) unmarshalSig(codecs *codec.Set, c elliptic.Curve, sigBytes []byte) (ec.Sig, error) {
+	sig, err := codecs.Sig.Unmarshal(sigBytes)
+	if nil != err {
+		return nil, err
 	}
-	privKey := new(PrivateKey)
 
-	ecdsaBigIntKey := new(ecdsaBigInt)
-	if _, err := asn1.Unmarshal(privKeyBytes[1:], ecdsaBigIntKey); nil != err {
+	if _, err := msller.Encoding.decode(c, sig.(ec.Sig)); nil != err {
 		return nil, err
 	}
-	privKey.D = ecdsaBigIntKey.D
-	privKey.X = ecdsaBigIntKey.X
-	privKey.Y = ecdsaBigIntKey.Y
-	privKey.PublicKey.Curve = elliptic.P521()
-	return privKey, nil
+
+	return sig.(ec.Sig), nil
 }
 
-// MarshalPubKey marshal pubKey to []byte where byte[0] records the marshal version
-func (msller *worker) MarshalPubKey(pubKey crypto.PublicKey) ([]byte, error) {
-	pub, ok := pubKey.(*PublicKey)
-	if !ok {
-		return nil, ec.ErrKeyTampered
+// encodePrivKey encodes the private keys of c as asn1(D,X,Y)
+func encodePrivKey(c elliptic.Curve) codec.Encoder {
+	return func(v interface{}) ([]byte, error) {
+		priv, ok := v.(*PrivateKey)
+		if !ok || (nil == priv.Curve) {
+			return nil, ec.ErrKeyTampered
+		}
+		if c != priv.Curve {
+			return nil, ec.ErrECTypeUnsupported
+		}
+
+		return asn1.Marshal(ecdsaBigInt{priv.D, priv.X, priv.Y})
 	}
-	result := make([]byte, 1)
-	result[0] = byte(marshalVersion)
-	bytes, err := asn1.Marshal(ecdsaBigInt{D: pub.X, X: pub.X, Y: pub.Y})
-	if nil != err {
-		return nil, err
+}
+
+// encodePubKey encodes the public keys of c as curve || SEC1 compressed point
+func encodePubKey(c elliptic.Curve, curve byte) codec.Encoder {
+	return func(v interface{}) ([]byte, error) {
+		pub, ok := v.(*PublicKey)
+		if !ok || (nil == pub.Curve) {
+			return nil, ec.ErrKeyTampered
+		}
+		if c != pub.Curve {
+			return nil, ec.ErrECTypeUnsupported
+		}
+
+		point, err := MarshalSEC1(pub, true)
+		if nil != err {
+			return nil, err
+		}
+
+		return append([]byte{curve}, point...), nil
 	}
-	return append(result, bytes...), nil
 }
 
-// UnmarshalPubKey unmarshal pubKeyBytes to pubKey
-func (msller *Worker256) UnmarshalPubKey(pubKeyBytes []byte) (crypto.PublicKey, error) {
-	if len(pubKeyBytes) == 0 || int(pubKeyBytes[0]) != unmarshalVersion {
-		return nil, ec.ErrWrongVersion
+// encodeSig keeps the signature as is, in whatever encoding
+func encodeSig(v interface{}) ([]byte, error) {
+	sig, ok := v.(ec.Sig)
+	if !ok {
+		return nil, ec.ErrMalformedSig
 	}
-	pubKey := new(PublicKey)
 
+	return append([]byte(nil), sig...), nil
+}
+
+// unmarshalBigInts decodes the ASN.1 integers of a body of version 1
+func unmarshalBigInts(body []byte) (*ecdsaBigInt, error) {
 	ecdsaBigIntKey := new(ecdsaBigInt)
-	if _, err := asn1.Unmarshal(pubKeyBytes[1:], ecdsaBigIntKey); nil != err {
-		return nil, err
+	if rest, err := asn1.Unmarshal(body, ecdsaBigIntKey); (nil != err) || (0 != len(rest)) {
+		return nil, ec.ErrMalformedKey
 	}
-	pubKey.X = ecdsaBigIntKey.X
-	pubKey.Y = ecdsaBigIntKey.Y
-	pubKey.Curve = elliptic.P256()
-	return pubKey, nil
+
+	return ecdsaBigIntKey, nil
 }
 
-// UnmarshalPubKey unmarshal pubKeyBytes to pubKey
-func (msller *Worker512) UnmarshalPubKey(pubKeyBytes []byte) (crypto.PublicKey, error) {
-	if len(pubKeyBytes) == 0 || int(pubKeyBytes[0]) != unmarshalVersion {
-		return nil, ec.ErrWrongVersion
-	}
-	pubKey := new(PublicKey)
+// decodePubKey decodes the public keys of c tagged as curve, whose point must
+// be a valid public key
+func decodePubKey(c elliptic.Curve, curve byte) codec.Decoder {
+	return func(body []byte) (interface{}, error) {
+		if 1 > len(body) {
+			return nil, ec.ErrMalformedKey
+		}
+		if curve != body[0] {
+			return nil, ec.ErrECTypeUnsupported
+		}
 
-	ecdsaBigIntKey := new(ecdsaBigInt)
-	if _, err := asn1.Unmarshal(pubKeyBytes[1:], ecdsaBigIntKey); nil != err {
-		return nil, err
+		return UnmarshalSEC1(c, body[1:])
 	}
-	pubKey.X = ecdsaBigIntKey.X
-	pubKey.Y = ecdsaBigIntKey.Y
-	pubKey.Curve = elliptic.P521()
-	return pubKey, nil
 }
 
-// MarshalSig marshal sig to []byte where byte[0] records the marshal version
-func (msller *worker) MarshalSig(sig ec.Sig) ([]byte, error) {
-	result := make([]byte, 1)
-	result[0] = byte(marshalVersion)
-	return append(result, sig...), nil
+// decodeLegacyPubKey decodes the public keys of c of version 1, i.e. the
+// ASN.1 integers (X, X, Y)
+func decodeLegacyPubKey(c elliptic.Curve) codec.Decoder {
+	return func(body []byte) (interface{}, error) {
+		ecdsaBigIntKey, err := unmarshalBigInts(body)
+		if nil != err {
+			return nil, err
+		}
+
+		// the leading integer of a public key is a copy of X
+		if 0 != ecdsaBigIntKey.D.Cmp(ecdsaBigIntKey.X) {
+			return nil, ec.ErrMalformedKey
+		}
+
+		pubKey := &PublicKey{Curve: c, X: ecdsaBigIntKey.X, Y: ecdsaBigIntKey.Y}
+		if err := Validate(pubKey); nil != err {
+			return nil, err
+		}
+
+		return pubKey, nil
+	}
 }
 
-// UnmarshalSig unmarshal sigBytes to sig
-func (msller *worker) UnmarshalSig(sigBytes []byte) (ec.Sig, error) {
-	if len(sigBytes) == 0 || int(sigBytes[0]) != unmarshalVersion {
-		return nil, ec.ErrWrongVersion
+// decodePrivKey decodes the private keys of c, whose scalar must lie in
+// [1,N-1] and match the public point
+func decodePrivKey(c elliptic.Curve) codec.Decoder {
+	return func(body []byte) (interface{}, error) {
+		ecdsaBigIntKey, err := unmarshalBigInts(body)
+		if nil != err {
+			return nil, err
+		}
+
+		privKey := new(PrivateKey)
+		privKey.D = ecdsaBigIntKey.D
+		privKey.X = ecdsaBigIntKey.X
+		privKey.Y = ecdsaBigIntKey.Y
+		privKey.PublicKey.Curve = c
+
+		if err := Validate(&privKey.PublicKey); nil != err {
+			return nil, err
+		}
+		if (privKey.D.Sign() <= 0) || (privKey.D.Cmp(c.Params().N) >= 0) {
+			return nil, ec.ErrInvalidKey
+		}
+		if x, y := c.ScalarBaseMult(privKey.D.Bytes()); (0 != x.Cmp(privKey.X)) || (0 != y.Cmp(privKey.Y)) {
+			return nil, ec.ErrInvalidKey
+		}
+
+		return privKey, nil
 	}
+}
 
-	return sigBytes[1:], nil
+// decodeAnySig decodes the signatures over c in either encoding, leaving the
+// choice of the encoding to the worker
+func decodeAnySig(c elliptic.Curve) codec.Decoder {
+	return func(body []byte) (interface{}, error) {
+		if _, err := decodeSig(body); nil == err {
+			return append(ec.Sig(nil), body...), nil
+		}
+		if _, err := decodeP1363(c, body); nil != err {
+			return nil, err
+		}
 
+		return append(ec.Sig(nil), body...), nil
+	}
 }
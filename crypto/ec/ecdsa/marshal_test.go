@@ -10,7 +10,7 @@ import (
 func TestMarshalPrivKey(t *testing.T) {
 	//worker := new(ecdsa.Worker256)
 	worker := new(ecdsa.Worker512)
-	priv, _, err := worker.GenerateKey(rand.Reader)
+	priv, err := worker.GenerateKey(rand.Reader)
 	if nil != err {
 		t.Fatal(err)
 	}
@@ -56,11 +56,12 @@ func TestMarshalPrivKey(t *testing.T) {
 func TestMarshalPubKey(t *testing.T) {
 	//worker := new(ecdsa.Worker256)
 	worker := new(ecdsa.Worker512)
-	priv, pub, err := worker.GenerateKey(rand.Reader)
+	priv, err := worker.GenerateKey(rand.Reader)
 
 	if nil != err {
 		t.Fatal(err)
 	}
+	pub := priv.Public()
 
 	rawMsg := []byte("Hello World")
 	digest := sha3.Sum256(rawMsg)
@@ -103,7 +104,7 @@ func TestMarshalSig(t *testing.T) {
 	//worker := new(ecdsa.Worker256)
 	worker := new(ecdsa.Worker512)
 
-	priv, _, err := worker.GenerateKey(rand.Reader)
+	priv, err := worker.GenerateKey(rand.Reader)
 	if nil != err {
 		t.Fatal(err)
 	}
@@ -0,0 +1,82 @@
+package ecdsa
+
+// Note:
+// + IEEE P1363 encodes a signature as r||s, each left-padded to the byte
+//   size of the curve order, i.e. 64 bytes for P-256 and secp256k1 and 132
+//   bytes for P-521
+
+import (
+	"crypto/elliptic"
+	"encoding/asn1"
+	"math/big"
+
+	"github.com/sammy00/gravity/crypto/ec"
+)
+
+// SigEncoding selects how the workers encode the signatures
+type SigEncoding int
+
+const (
+	// EncodingDER encodes signatures as the ASN.1 DER sequence of r and s
+	EncodingDER SigEncoding = iota
+	// EncodingP1363 encodes signatures as the fixed-width r||s of IEEE P1363
+	EncodingP1363
+)
+
+// P1363Size returns the size of the P1363 signatures over c
+func P1363Size(c elliptic.Curve) int {
+	return 2 * ((c.Params().BitSize + 7) / 8)
+}
+
+// ToP1363 converts the DER signature sig over c into r||s
+func ToP1363(c elliptic.Curve, sig ec.Sig) (ec.Sig, error) {
+	decodedSig, err := decodeSig(sig)
+	if nil != err {
+		return nil, err
+	}
+
+	size := P1363Size(c) / 2
+	if (decodedSig.R.BitLen() > 8*size) || (decodedSig.S.BitLen() > 8*size) {
+		return nil, ec.ErrMalformedSig
+	}
+
+	out := make([]byte, 2*size)
+	decodedSig.R.FillBytes(out[:size])
+	decodedSig.S.FillBytes(out[size:])
+
+	return out, nil
+}
+
+// FromP1363 converts the r||s signature sig over c into DER
+func FromP1363(c elliptic.Curve, sig ec.Sig) (ec.Sig, error) {
+	decodedSig, err := decodeP1363(c, sig)
+	if nil != err {
+		return nil, err
+	}
+
+	return asn1.Marshal(*decodedSig)
+}
+
+// decodeP1363 decodes the r||s signature sig over c, whose r and s must be positive
+func decodeP1363(c elliptic.Curve, sig ec.Sig) (*ecdsaSig, error) {
+	if P1363Size(c) != len(sig) {
+		return nil, ec.ErrMalformedSig
+	}
+
+	size := len(sig) / 2
+	decodedSig := &ecdsaSig{new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])}
+	if (decodedSig.R.Sign() <= 0) || (decodedSig.S.Sign() <= 0) {
+		return nil, ec.ErrMalformedSig
+	}
+
+	return decodedSig, nil
+}
+
+// decode decodes sig over c in the encoding e
+func (e SigEncoding) decode(c elliptic.Curve, sig ec.Sig) (*ecdsaSig, error) {
+	if EncodingP1363 == e {
+		return decodeP1363(c, sig)
+	}
+
+	return decodeSig(sig)
+}
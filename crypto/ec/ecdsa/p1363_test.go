@@ -0,0 +1,76 @@
+package ecdsa_test
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestP1363(t *testing.T) {
+	der256, der512 := new(ecdsa.Worker256), new(ecdsa.Worker512)
+	p1363256, p1363512 := new(ecdsa.Worker256), new(ecdsa.Worker512)
+	p1363256.Encoding, p1363512.Encoding = ecdsa.EncodingP1363, ecdsa.EncodingP1363
+
+	testCases := []struct {
+		der, p1363 ec.Worker
+		curve      elliptic.Curve
+		size       int
+	}{
+		{der256, p1363256, elliptic.P256(), 64},
+		{der512, p1363512, elliptic.P521(), 132},
+	}
+
+	digest := sha3.Sum256([]byte("Hello World"))
+	for i, c := range testCases {
+		if ecdsa.P1363Size(c.curve) != c.size {
+			t.Fatalf("#%d: want size %d, got %d", i, c.size, ecdsa.P1363Size(c.curve))
+		}
+
+		priv, err := c.p1363.GenerateKey(rand.Reader)
+		if nil != err {
+			t.Fatal(err)
+		}
+
+		// every signature is of the same size, whatever the sizes of r and s
+		for j := 0; j < 8; j++ {
+			sig, err := c.p1363.Sign(priv, digest[:])
+			if nil != err {
+				t.Fatal(err)
+			}
+			if c.size != len(sig) {
+				t.Fatalf("#%d-%d: want %d bytes, got %d", i, j, c.size, len(sig))
+			}
+			if !c.p1363.Verify(priv.Public(), digest[:], sig) {
+				t.Fatalf("#%d-%d: the verification shouldn't fail", i, j)
+			}
+			// each worker accepts its configured encoding only
+			if c.der.Verify(priv.Public(), digest[:], sig) {
+				t.Fatalf("#%d-%d: the verification should fail", i, j)
+			}
+
+			der, err := ecdsa.FromP1363(c.curve, sig)
+			if nil != err {
+				t.Fatal(err)
+			}
+			if !c.der.Verify(priv.Public(), digest[:], der) {
+				t.Fatalf("#%d-%d: the verification shouldn't fail", i, j)
+			}
+			if back, err := ecdsa.ToP1363(c.curve, der); nil != err || !bytes.Equal(sig, back) {
+				t.Fatalf("#%d-%d: the round trip changes the signature: %v", i, j, err)
+			}
+		}
+
+		sig, _ := c.p1363.Sign(priv, digest[:])
+		if _, err := ecdsa.FromP1363(c.curve, sig[1:]); ec.ErrMalformedSig != err {
+			t.Fatalf("#%d: want %v, got %v", i, ec.ErrMalformedSig, err)
+		}
+		if _, err := ecdsa.FromP1363(c.curve, make([]byte, c.size)); ec.ErrMalformedSig != err {
+			t.Fatalf("#%d: want %v, got %v", i, ec.ErrMalformedSig, err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package ecdsa
+
+// Note:
+// + SEC1 encodes a point as 0x04||X||Y, or compressed as 0x02||X for an even
+//   Y and 0x03||X for an odd Y, the coordinates being left-padded to the byte
+//   size of the field
+// + decompression solves y^2 = x^3 + ax + b, where a is recovered from the
+//   base point since elliptic.CurveParams assumes a = -3 of the NIST curves
+//   while secp256k1 takes a = 0
+
+import (
+	"crypto/elliptic"
+	"math/big"
+
+	"github.com/sammy00/gravity/crypto/ec"
+)
+
+// prefixes of the SEC1 encodings
+const (
+	sec1Infinity     = 0x00
+	sec1Even         = 0x02
+	sec1Odd          = 0x03
+	sec1Uncompressed = 0x04
+)
+
+// MarshalSEC1 encodes pubKey as a SEC1 point, compressed if asked
+func MarshalSEC1(pubKey *PublicKey, compressed bool) ([]byte, error) {
+	if err := Validate(pubKey); nil != err {
+		return nil, err
+	}
+
+	size := (pubKey.Curve.Params().BitSize + 7) / 8
+	if compressed {
+		out := make([]byte, 1+size)
+		out[0] = byte(sec1Even + pubKey.Y.Bit(0))
+		pubKey.X.FillBytes(out[1:])
+		return out, nil
+	}
+
+	out := make([]byte, 1+2*size)
+	out[0] = sec1Uncompressed
+	pubKey.X.FillBytes(out[1 : 1+size])
+	pubKey.Y.FillBytes(out[1+size:])
+
+	return out, nil
+}
+
+// UnmarshalSEC1 decodes the SEC1 point data of c in either form, which must
+// be a valid public key
+func UnmarshalSEC1(c elliptic.Curve, data []byte) (*PublicKey, error) {
+	if 0 == len(data) {
+		return nil, ec.ErrMalformedKey
+	}
+
+	size := (c.Params().BitSize + 7) / 8
+	pubKey := &PublicKey{Curve: c}
+	switch {
+	case (sec1Infinity == data[0]) && (1 == len(data)):
+		return nil, ec.ErrPointAtInfinity
+	case (sec1Uncompressed == data[0]) && (1+2*size == len(data)):
+		pubKey.X = new(big.Int).SetBytes(data[1 : 1+size])
+		pubKey.Y = new(big.Int).SetBytes(data[1+size:])
+	case ((sec1Even == data[0]) || (sec1Odd == data[0])) && (1+size == len(data)):
+		pubKey.X = new(big.Int).SetBytes(data[1:])
+		pubKey.Y = decompress(c, pubKey.X, uint(data[0]-sec1Even))
+		if nil == pubKey.Y {
+			return nil, ec.ErrNotOnCurve
+		}
+	default:
+		return nil, ec.ErrMalformedKey
+	}
+
+	if err := Validate(pubKey); nil != err {
+		return nil, err
+	}
+
+	return pubKey, nil
+}
+
+// HashToPoint maps to a point of c by try and increment, i.e. hash(ctr) for
+// the counters ctr = 0, 1, ..., 255 in turn until the digest is the X of a
+// point, which is taken with an even Y. The digests must be of the byte size
+// of the field of c, and a point of unknown discrete logarithm results as
+// long as hash is a random oracle
+func HashToPoint(c elliptic.Curve, hash func(ctr int) []byte) (*PublicKey, error) {
+	for ctr := 0; ctr < 256; ctr++ {
+		if p, err := UnmarshalSEC1(c, append([]byte{sec1Even}, hash(ctr)...)); nil == err {
+			return p, nil
+		}
+	}
+
+	return nil, ec.ErrNotOnCurve
+}
+
+// decompress solves the y of parity odd for x on c, returning nil if x
+// isn't the abscissa of any point
+func decompress(c elliptic.Curve, x *big.Int, odd uint) *big.Int {
+	params := c.Params()
+	p := params.P
+	if x.Cmp(p) >= 0 {
+		return nil
+	}
+
+	// a = (Gy^2 - Gx^3 - b) / Gx
+	a := new(big.Int).Mul(params.Gy, params.Gy)
+	a.Sub(a, new(big.Int).Exp(params.Gx, big.NewInt(3), p))
+	a.Sub(a, params.B)
+	a.Mul(a, new(big.Int).ModInverse(params.Gx, p))
+	a.Mod(a, p)
+
+	// y^2 = x^3 + ax + b
+	y2 := new(big.Int).Exp(x, big.NewInt(3), p)
+	y2.Add(y2, new(big.Int).Mul(a, x))
+	y2.Add(y2, params.B)
+	y2.Mod(y2, p)
+
+	y := new(big.Int).ModSqrt(y2, p)
+	if nil == y {
+		return nil
+	}
+	if y.Bit(0) != odd {
+		if 0 == y.Sign() {
+			return nil
+		}
+		y.Sub(p, y)
+	}
+
+	return y
+}
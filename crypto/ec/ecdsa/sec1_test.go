@@ -0,0 +1,176 @@
+package ecdsa_test
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+func TestSEC1(t *testing.T) {
+	workers := []ec.Worker{new(ecdsa.Worker256), new(ecdsa.Worker512)}
+
+	for i, worker := range workers {
+		for j := 0; j < 16; j++ {
+			priv, err := worker.GenerateKey(rand.Reader)
+			if nil != err {
+				t.Fatal(err)
+			}
+			pub := &priv.(*ecdsa.PrivateKey).PublicKey
+			size := (pub.Curve.Params().BitSize + 7) / 8
+
+			for _, compressed := range []bool{true, false} {
+				data, err := ecdsa.MarshalSEC1(pub, compressed)
+				if nil != err {
+					t.Fatal(err)
+				}
+
+				expect := 1 + 2*size
+				if compressed {
+					expect = 1 + size
+				}
+				if expect != len(data) {
+					t.Fatalf("#%d-%d: want %d bytes, got %d", i, j, expect, len(data))
+				}
+
+				got, err := ecdsa.UnmarshalSEC1(pub.Curve, data)
+				if nil != err {
+					t.Fatalf("#%d-%d: %v", i, j, err)
+				}
+				if (0 != pub.X.Cmp(got.X)) || (0 != pub.Y.Cmp(got.Y)) {
+					t.Fatalf("#%d-%d: the round trip changes the key", i, j)
+				}
+			}
+		}
+	}
+}
+
+func TestSEC1Invalid(t *testing.T) {
+	c := elliptic.P256()
+	pub := &ecdsa.PublicKey{Curve: c, X: c.Params().Gx, Y: c.Params().Gy}
+	compressed, err := ecdsa.MarshalSEC1(pub, true)
+	if nil != err {
+		t.Fatal(err)
+	}
+	uncompressed, err := ecdsa.MarshalSEC1(pub, false)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	// the smallest x which isn't the abscissa of any point of P-256
+	params := c.Params()
+	x := big.NewInt(0)
+	for ; ; x.Add(x, big.NewInt(1)) {
+		y2 := new(big.Int).Exp(x, big.NewInt(3), params.P)
+		y2.Sub(y2, new(big.Int).Mul(big.NewInt(3), x))
+		y2.Add(y2, params.B)
+		y2.Mod(y2, params.P)
+		if -1 == big.Jacobi(y2, params.P) {
+			break
+		}
+	}
+	offCurve := make([]byte, len(compressed))
+	offCurve[0] = 0x02
+	x.FillBytes(offCurve[1:])
+
+	// x = p is out of the field
+	outOfField := append([]byte{0x02}, c.Params().P.Bytes()...)
+
+	hybrid := append([]byte{0x06}, uncompressed[1:]...)
+
+	testCases := []struct {
+		data   []byte
+		expect error
+	}{
+		{nil, ec.ErrMalformedKey},
+		{[]byte{0x00}, ec.ErrPointAtInfinity},
+		{offCurve, ec.ErrNotOnCurve},
+		{outOfField, ec.ErrNotOnCurve},
+		{hybrid, ec.ErrMalformedKey},
+		{compressed[:len(compressed)-1], ec.ErrMalformedKey},
+		{uncompressed[:len(uncompressed)-1], ec.ErrMalformedKey},
+	}
+
+	for i, c := range testCases {
+		if _, err := ecdsa.UnmarshalSEC1(elliptic.P256(), c.data); c.expect != err {
+			t.Fatalf("#%d: want %v, got %v", i, c.expect, err)
+		}
+	}
+}
+
+func TestHashToPoint(t *testing.T) {
+	curves := []elliptic.Curve{elliptic.P256(), secp.S256()}
+
+	for i, c := range curves {
+		var tries []int
+		p, err := ecdsa.HashToPoint(c, func(ctr int) []byte {
+			tries = append(tries, ctr)
+			digest := sha256.Sum256([]byte{byte(i), byte(ctr)})
+			return digest[:]
+		})
+		if nil != err {
+			t.Fatal(err)
+		}
+		if (0 != p.Y.Bit(0)) || !c.IsOnCurve(p.X, p.Y) {
+			t.Fatalf("#%d: want a point of even Y, got (%x, %x)", i, p.X, p.Y)
+		}
+		for j, ctr := range tries {
+			if j != ctr {
+				t.Fatalf("#%d: want the counters in turn, got %v", i, tries)
+			}
+		}
+
+		// X >= P is never the X of a point
+		_, err = ecdsa.HashToPoint(c, func(int) []byte {
+			return bytes.Repeat([]byte{0xff}, 32)
+		})
+		if ec.ErrNotOnCurve != err {
+			t.Fatalf("#%d: want %v, got %v", i, ec.ErrNotOnCurve, err)
+		}
+	}
+}
+
+func TestUnmarshalPubKeyVersions(t *testing.T) {
+	worker := new(ecdsa.Worker256)
+	priv, err := worker.GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	pub := &priv.(*ecdsa.PrivateKey).PublicKey
+
+	current, err := worker.MarshalPubKey(pub)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if (2 != current[0]) || (ec.CurveP256 != current[1]) || (35 != len(current)) {
+		t.Fatalf("unexpected layout: %x", current)
+	}
+
+	// version 1 as version || asn1(X,X,Y)
+	legacy, err := asn1.Marshal(struct{ D, X, Y *big.Int }{pub.X, pub.X, pub.Y})
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	for i, data := range [][]byte{current, append([]byte{1}, legacy...)} {
+		got, err := worker.UnmarshalPubKey(data)
+		if nil != err {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if !pub.Equal(got) {
+			t.Fatalf("#%d: the round trip changes the key", i)
+		}
+	}
+
+	// a P-256 point isn't accepted as P-521
+	if _, err := new(ecdsa.Worker512).UnmarshalPubKey(current); ec.ErrECTypeUnsupported != err {
+		t.Fatalf("want %v, got %v", ec.ErrECTypeUnsupported, err)
+	}
+}
@@ -0,0 +1,60 @@
+package ecdsa
+
+import (
+	"crypto/elliptic"
+
+	"github.com/sammy00/gravity/crypto/ec"
+)
+
+// Validate checks pubKey is a point of its curve other than the point at
+// infinity, which is encoded as (0,0) by the standard library
+func Validate(pubKey *PublicKey) error {
+	if (nil == pubKey) || (nil == pubKey.Curve) || (nil == pubKey.X) || (nil == pubKey.Y) {
+		return ec.ErrKeyTampered
+	}
+
+	if (0 == pubKey.X.Sign()) && (0 == pubKey.Y.Sign()) {
+		return ec.ErrPointAtInfinity
+	}
+
+	// coordinates must be reduced modulo P
+	p := pubKey.Curve.Params().P
+	if (pubKey.X.Sign() < 0) || (pubKey.Y.Sign() < 0) || (pubKey.X.Cmp(p) >= 0) || (pubKey.Y.Cmp(p) >= 0) ||
+		!pubKey.Curve.IsOnCurve(pubKey.X, pubKey.Y) {
+		return ec.ErrNotOnCurve
+	}
+
+	return nil
+}
+
+// Validate checks pubKey is a valid public key of ecdsa-256
+func (ecdsa256 *Worker256) Validate(pubKey ec.PublicKey) error {
+	return validateOver(elliptic.P256(), pubKey)
+}
+
+// Validate checks pubKey is a valid public key of ecdsa-512
+func (ecdsa512 *Worker512) Validate(pubKey ec.PublicKey) error {
+	return validateOver(elliptic.P521(), pubKey)
+}
+
+// validateOver checks pubKey as Validate, refusing the keys over another
+// curve than c
+func validateOver(c elliptic.Curve, pubKey ec.PublicKey) error {
+	ecdsaPubKey, ok := pubKey.(*PublicKey)
+	if !ok {
+		return ec.ErrKeyTampered
+	}
+
+	if (nil != ecdsaPubKey) && (nil != ecdsaPubKey.Curve) && !sameCurve(c, ecdsaPubKey.Curve) {
+		return ec.ErrECTypeUnsupported
+	}
+
+	return Validate(ecdsaPubKey)
+}
+
+// sameCurve tells whether a and b are the same short Weierstrass curve
+func sameCurve(a, b elliptic.Curve) bool {
+	pa, pb := a.Params(), b.Params()
+
+	return (0 == pa.P.Cmp(pb.P)) && (0 == pa.N.Cmp(pb.N)) && (0 == pa.B.Cmp(pb.B))
+}
@@ -0,0 +1,132 @@
+package ecdsa_test
+
+import (
+	"crypto/rand"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestValidate(t *testing.T) {
+	workers := []interface {
+		ec.Worker
+		ec.Validator
+	}{new(ecdsa.Worker256), new(ecdsa.Worker512)}
+
+	for i, worker := range workers {
+		priv, err := worker.GenerateKey(rand.Reader)
+		if nil != err {
+			t.Fatal(err)
+		}
+		pub := priv.(*ecdsa.PrivateKey).PublicKey
+		if err := worker.Validate(&pub); nil != err {
+			t.Fatalf("#%d: %v", i, err)
+		}
+
+		p := pub.Curve.Params().P
+		testCases := []struct {
+			x, y   *big.Int
+			expect error
+		}{
+			{big.NewInt(0), big.NewInt(0), ec.ErrPointAtInfinity},
+			{pub.X, new(big.Int).Add(pub.Y, big.NewInt(1)), ec.ErrNotOnCurve},
+			// the same point with Y unreduced
+			{pub.X, new(big.Int).Add(pub.Y, p), ec.ErrNotOnCurve},
+			{pub.X, new(big.Int).Neg(pub.Y), ec.ErrNotOnCurve},
+		}
+
+		msller := worker.(interface {
+			UnmarshalPubKey(pubKeyBytes []byte) (ec.PublicKey, error)
+		})
+		for j, c := range testCases {
+			bad := &ecdsa.PublicKey{Curve: pub.Curve, X: c.x, Y: c.y}
+			if err := ecdsa.Validate(bad); c.expect != err {
+				t.Fatalf("#%d-%d: want %v, got %v", i, j, c.expect, err)
+			}
+
+			// the unmarshal path rejects invalid keys on its own, which are
+			// hand-crafted in version 1 as the marshallers refuse them
+			if c.y.Sign() < 0 {
+				continue
+			}
+			legacy, err := asn1.Marshal(struct{ D, X, Y *big.Int }{c.x, c.x, c.y})
+			if nil != err {
+				t.Fatal(err)
+			}
+			if _, err := msller.UnmarshalPubKey(append([]byte{1}, legacy...)); c.expect != err {
+				t.Fatalf("#%d-%d: want %v, got %v", i, j, c.expect, err)
+			}
+		}
+	}
+}
+
+func TestValidateCrossCurve(t *testing.T) {
+	digest := sha3.Sum256([]byte("Hello World"))
+
+	// Validate doesn't depend on Strict, which makes Verify check the curve
+	p256, p521, s256 := new(ecdsa.Worker256), new(ecdsa.Worker512), secp.New()
+	p256.Strict, p521.Strict = true, true
+	testCases := []struct {
+		worker, other interface {
+			ec.Worker
+			ec.Validator
+		}
+	}{
+		{p256, p521},
+		{p256, s256},
+		{p521, p256},
+		{p521, s256},
+	}
+
+	for i, c := range testCases {
+		priv, err := c.other.GenerateKey(rand.Reader)
+		if nil != err {
+			t.Fatal(err)
+		}
+		sig, err := c.other.Sign(priv, digest[:])
+		if nil != err {
+			t.Fatal(err)
+		}
+
+		// the key is valid over its own curve, but not over the worker's
+		if err := c.other.Validate(priv.Public()); nil != err {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if err := c.worker.Validate(priv.Public()); ec.ErrECTypeUnsupported != err {
+			t.Fatalf("#%d: want %v, got %v", i, ec.ErrECTypeUnsupported, err)
+		}
+
+		if c.worker.Verify(priv.Public(), digest[:], sig) {
+			t.Fatalf("#%d: the key of another curve is accepted", i)
+		}
+	}
+}
+
+func TestStrictVerify(t *testing.T) {
+	worker := new(ecdsa.Worker256)
+	priv, err := worker.GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	digest := sha3.Sum256([]byte("Hello World"))
+	sig, err := worker.Sign(priv, digest[:])
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	worker.Strict = true
+	if !worker.Verify(priv.Public(), digest[:], sig) {
+		t.Fatal("the verification shouldn't fail")
+	}
+
+	infinity := &ecdsa.PublicKey{Curve: priv.(*ecdsa.PrivateKey).Curve, X: big.NewInt(0), Y: big.NewInt(0)}
+	if worker.Verify(infinity, digest[:], sig) {
+		t.Fatal("the verification should fail")
+	}
+}
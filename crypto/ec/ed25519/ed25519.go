@@ -25,7 +25,12 @@ type PrivateKey struct {
 }
 
 // Worker works according to ed25519
-type Worker struct{}
+type Worker struct {
+	// Strict makes Verify refuse public keys failing Validate
+	Strict bool
+	// Mode selects the rules of Verify, defaulting to VerifyStdLib
+	Mode VerifyMode
+}
 
 // GenerateKey generates a (priv,pub) EC key pair
 func (ed *Worker) GenerateKey(rand io.Reader) (ec.PrivateKey, error) {
@@ -53,6 +58,9 @@ func (ed *Worker) Sign(privKey ec.PrivateKey, digest []byte) (ec.Sig, error) {
 // It returns value records whether the signature is valid.
 func (ed *Worker) Verify(pubKey ec.PublicKey, digest []byte, sig ec.Sig) bool {
 	pub, ok := pubKey.(PublicKey)
+	if ed.Strict && ok && (nil != Validate(pub)) {
+		return false
+	}
 
-	return ok && (len(pub) == stdEd25519.PublicKeySize) && stdEd25519.Verify(pub, digest, sig)
+	return ok && VerifyWithMode(ed.Mode, pub, digest, sig)
 }
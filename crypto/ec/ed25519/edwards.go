@@ -0,0 +1,67 @@
+package ed25519
+
+// Note:
+// + these helpers serve the protocols built over edwards25519 beside the
+//   signatures, e.g. vrf, ring, blind and pop, which need the secret scalar
+//   of a key and the strict decoding of points
+
+import (
+	"bytes"
+	"crypto/sha512"
+
+	"filippo.io/edwards25519"
+	"github.com/sammy00/gravity/crypto/ec"
+)
+
+// inv8 is the inverse of the cofactor modulo l
+var inv8 = func() *edwards25519.Scalar {
+	var eight [32]byte
+	eight[0] = 8
+
+	s, _ := edwards25519.NewScalar().SetCanonicalBytes(eight[:])
+
+	return s.Invert(s)
+}()
+
+// Expand derives the secret scalar of privKey and the prefix of its nonces
+// from the seed as RFC 8032
+func Expand(privKey PrivateKey) (*edwards25519.Scalar, []byte, error) {
+	if len(privKey.PrivateKey) != privKeySize {
+		return nil, nil, ec.ErrKeyTampered
+	}
+
+	h := sha512.Sum512(privKey.PrivateKey.Seed())
+	x, err := edwards25519.NewScalar().SetBytesWithClamping(h[:32])
+	if nil != err {
+		return nil, nil, err
+	}
+
+	return x, h[32:], nil
+}
+
+// Scalar derives the secret scalar of privKey, whose multiple of the base
+// point is the public key
+func Scalar(privKey PrivateKey) (*edwards25519.Scalar, error) {
+	x, _, err := Expand(privKey)
+
+	return x, err
+}
+
+// DecodePoint decodes the canonical encoding of a point, refusing the
+// encodings of y >= p tolerated by SetBytes
+func DecodePoint(data []byte) (*edwards25519.Point, bool) {
+	p, err := new(edwards25519.Point).SetBytes(data)
+	if (nil != err) || !bytes.Equal(p.Bytes(), data) {
+		return nil, false
+	}
+
+	return p, true
+}
+
+// IsTorsionFree tells if p is in the prime order subgroup, i.e. inv8*(8*p)
+// gives p back
+func IsTorsionFree(p *edwards25519.Point) bool {
+	cleared := new(edwards25519.Point).MultByCofactor(p)
+
+	return 1 == cleared.ScalarMult(inv8, cleared).Equal(p)
+}
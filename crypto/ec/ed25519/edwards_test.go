@@ -0,0 +1,69 @@
+package ed25519_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+)
+
+func TestScalar(t *testing.T) {
+	priv, err := new(ed25519.Worker).GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	x, err := ed25519.Scalar(priv.(ed25519.PrivateKey))
+	if nil != err {
+		t.Fatal(err)
+	}
+	if got := new(edwards25519.Point).ScalarBaseMult(x).Bytes(); !bytes.Equal(got, priv.Public().(ed25519.PublicKey)) {
+		t.Fatalf("want the public key %x, got %x", priv.Public(), got)
+	}
+
+	if _, err := ed25519.Scalar(ed25519.PrivateKey{}); ec.ErrKeyTampered != err {
+		t.Fatalf("want %v, got %v", ec.ErrKeyTampered, err)
+	}
+}
+
+func TestDecodePoint(t *testing.T) {
+	testCases := []struct {
+		point string
+		ok    bool
+	}{
+		{"5866666666666666666666666666666666666666666666666666666666666666", true},
+		// y = 2 has no x
+		{"0200000000000000000000000000000000000000000000000000000000000000", false},
+		// y = p is a non-canonical encoding of y = 0
+		{"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", false},
+		{"00", false},
+	}
+
+	for i, c := range testCases {
+		data, _ := hex.DecodeString(c.point)
+		if _, ok := ed25519.DecodePoint(data); c.ok != ok {
+			t.Fatalf("#%d: want %v, got %v", i, c.ok, ok)
+		}
+	}
+}
+
+func TestIsTorsionFree(t *testing.T) {
+	// a point of order 8
+	data, _ := hex.DecodeString("26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc05")
+	T, ok := ed25519.DecodePoint(data)
+	if !ok {
+		t.Fatal("the point of order 8 doesn't decode")
+	}
+
+	B := edwards25519.NewGeneratorPoint()
+	if !ed25519.IsTorsionFree(B) {
+		t.Fatal("the base point isn't torsion free")
+	}
+	if ed25519.IsTorsionFree(T) || ed25519.IsTorsionFree(new(edwards25519.Point).Add(B, T)) {
+		t.Fatal("a point of mixed order is torsion free")
+	}
+}
@@ -0,0 +1,118 @@
+package ed25519_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	"golang.org/x/crypto/sha3"
+)
+
+// checkErr fails unless err is one of the typed errors of the decoders
+func checkErr(t *testing.T, err error) {
+	switch err {
+	case ec.ErrWrongVersion, ec.ErrMalformedKey, ec.ErrInvalidKey,
+		ec.ErrNotOnCurve, ec.ErrPointAtInfinity, ec.ErrSmallOrder, ec.ErrMalformedSig,
+		ed25519.ErrWrongAlgorithm:
+	default:
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func FuzzUnmarshalPrivKey(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		msller := new(ed25519.Marshaller)
+
+		key, err := msller.UnmarshalPrivKey(data)
+		if nil != err {
+			checkErr(t, err)
+			return
+		}
+
+		// a decoded key must sign verifiable signatures
+		priv := key.(ed25519.PrivateKey)
+		digest := sha3.Sum256([]byte("Hello World"))
+		worker := new(ed25519.Worker)
+		sig, err := worker.Sign(priv, digest[:])
+		if nil != err {
+			t.Fatal(err)
+		}
+		if !worker.Verify(priv.Public(), digest[:], sig) {
+			t.Fatal("the verification shouldn't fail")
+		}
+
+		if out, err := msller.MarshalPrivKey(priv); nil != err || !bytes.Equal(data, out) {
+			t.Fatalf("the round trip changes the key: %v", err)
+		}
+	})
+}
+
+func FuzzUnmarshalPubKey(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		msller := new(ed25519.Marshaller)
+
+		pub, err := msller.UnmarshalPubKey(data)
+		if nil != err {
+			checkErr(t, err)
+			return
+		}
+
+		if out, err := msller.MarshalPubKey(pub); nil != err || !bytes.Equal(data, out) {
+			t.Fatalf("the round trip changes the key: %v", err)
+		}
+	})
+}
+
+func FuzzUnmarshalSig(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		msller := new(ed25519.Marshaller)
+
+		sig, err := msller.UnmarshalSig(data)
+		if nil != err {
+			checkErr(t, err)
+			return
+		}
+
+		if out, err := msller.MarshalSig(sig); nil != err || !bytes.Equal(data, out) {
+			t.Fatalf("the round trip changes the signature: %v", err)
+		}
+	})
+}
+
+func FuzzUnmarshalSigPh(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fuzzUnmarshalTaggedSig(t, new(ed25519.WorkerPh), data)
+	})
+}
+
+func FuzzUnmarshalSigCtx(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fuzzUnmarshalTaggedSig(t, &ed25519.WorkerCtx{Context: "fuzz"}, data)
+	})
+}
+
+// fuzzUnmarshalTaggedSig checks the signatures of a variant decode only
+// under its own tag and round trip
+func fuzzUnmarshalTaggedSig(t *testing.T, msller interface {
+	MarshalSig(sig ec.Sig) ([]byte, error)
+	UnmarshalSig(sigBytes []byte) (ec.Sig, error)
+}, data []byte) {
+	sig, err := msller.UnmarshalSig(data)
+	if nil != err {
+		checkErr(t, err)
+		return
+	}
+
+	if 64 != len(sig) {
+		t.Fatalf("want a signature of 64 bytes, got %d", len(sig))
+	}
+	// a tagged signature never passes for a pure Ed25519 one
+	if _, err := new(ed25519.Marshaller).UnmarshalSig(data); nil == err {
+		t.Fatal("the tagged signature decodes as a pure one")
+	}
+
+	if out, err := msller.MarshalSig(sig); nil != err || !bytes.Equal(data, out) {
+		t.Fatalf("the round trip changes the signature: %v", err)
+	}
+}
@@ -2,81 +2,207 @@
 package ed25519
 
 import (
+	"bytes"
 	"crypto"
 
 	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/codec"
 	stdEd25519 "golang.org/x/crypto/ed25519"
 )
 
 const (
-	marshalVersion   = 1
-	unmarshalVersion = 1
-	privKeySize      = stdEd25519.PrivateKeySize
-	pubKeySize       = stdEd25519.PublicKeySize
+	marshalVersion = 1
+	privKeySize    = stdEd25519.PrivateKeySize
+	pubKeySize     = stdEd25519.PublicKeySize
+	sigSize        = stdEd25519.SignatureSize
+)
+
+// signatures of Ed25519ph and Ed25519ctx are marshalled as
+// taggedVersion || tag || sig, so that they never pass for one another nor
+// for the pure Ed25519 signatures of version 1
+const (
+	taggedVersion = 2
+	tagEd25519ph  = 1
+	tagEd25519ctx = 2
 )
 
 // Marshaller works for ed25519 to marshal/unmarshal the privKey, pubKey and sig
 type Marshaller struct{}
 
+// codecs version the blobs of Worker, and codecsPh and codecsCtx those of
+// WorkerPh and WorkerCtx, which only differ by the signatures
+var (
+	codecs = &codec.Set{
+		PrivKey: codec.New(marshalVersion, encodePrivKey, decodePrivKey),
+		PubKey:  codec.New(marshalVersion, encodePubKey, decodePubKey),
+		Sig:     codec.New(marshalVersion, encodeSig, decodeSig),
+	}
+	codecsPh = &codec.Set{
+		PrivKey: codecs.PrivKey,
+		PubKey:  codecs.PubKey,
+		Sig:     codec.New(taggedVersion, encodeTaggedSig(tagEd25519ph), decodeTaggedSig(tagEd25519ph)),
+	}
+	codecsCtx = &codec.Set{
+		PrivKey: codecs.PrivKey,
+		PubKey:  codecs.PubKey,
+		Sig:     codec.New(taggedVersion, encodeTaggedSig(tagEd25519ctx), decodeTaggedSig(tagEd25519ctx)),
+	}
+)
+
+func init() {
+	codec.Register("ed25519", codecs)
+	codec.Register("ed25519ph", codecsPh)
+	codec.Register("ed25519ctx", codecsCtx)
+}
+
 // MarshalPrivKey marshal privKey to []byte where byte[0] records the marshal version
 func (msller *Marshaller) MarshalPrivKey(privKey crypto.PrivateKey) ([]byte, error) {
-	priv, ok := privKey.(PrivateKey)
+	return codecs.PrivKey.Marshal(privKey)
+}
+
+// UnmarshalPrivKey unmarshal privKeyBytes to privKey
+func (msller *Marshaller) UnmarshalPrivKey(privKeyBytes []byte) (crypto.PrivateKey, error) {
+	return codecs.PrivKey.Unmarshal(privKeyBytes)
+}
+
+// MarshalPubKey marshal pubKey to []byte where byte[0] records the marshal version
+func (msller *Marshaller) MarshalPubKey(pubKey crypto.PublicKey) ([]byte, error) {
+	return codecs.PubKey.Marshal(pubKey)
+}
+
+// UnmarshalPubKey unmarshal pubKeyBytes to pubKey
+func (msller *Marshaller) UnmarshalPubKey(pubKeyBytes []byte) (crypto.PublicKey, error) {
+	return codecs.PubKey.Unmarshal(pubKeyBytes)
+}
+
+// MarshalSig marshal sig to []byte where byte[0] records the marshal version
+func (msller *Marshaller) MarshalSig(sig ec.Sig) ([]byte, error) {
+	return codecs.Sig.Marshal(sig)
+}
+
+// UnmarshalSig unmarshal sigBytes to sig
+func (msller *Marshaller) UnmarshalSig(sigBytes []byte) (ec.Sig, error) {
+	sig, err := codecs.Sig.Unmarshal(sigBytes)
+	if nil != err {
+		return nil, err
+	}
+
+	return sig.(ec.Sig), nil
+}
+
+// Migrate upgrades a private key, public key or signature blob of any
+// version to the current one
+func (msller *Marshaller) Migrate(blob []byte) ([]byte, error) {
+	return codecs.Migrate(blob)
+}
+
+func encodePrivKey(v interface{}) ([]byte, error) {
+	priv, ok := v.(PrivateKey)
 	if !ok || (len(priv.PrivateKey) != privKeySize) || (len(priv.PublicKey) != pubKeySize) {
 		return nil, ec.ErrKeyTampered
 	}
-	result := make([]byte, 1)
-	result[0] = byte(marshalVersion)
-	result = append(result, priv.PrivateKey...)
-	return append(result, priv.PublicKey...), nil
+
+	return append(append([]byte(nil), priv.PrivateKey...), priv.PublicKey...), nil
 }
 
-// UnmarshalPrivKey unmarshal privKeyBytes to privKey
-func (msller *Marshaller) UnmarshalPrivKey(privKeyBytes []byte) (crypto.PrivateKey, error) {
-	if len(privKeyBytes) == 0 || int(privKeyBytes[0]) != unmarshalVersion {
-		return nil, ec.ErrWrongVersion
+// decodePrivKey decodes seed || pub || pub, whose public halves must be
+// derived from the seed
+func decodePrivKey(body []byte) (interface{}, error) {
+	if len(body) != privKeySize+pubKeySize {
+		return nil, ec.ErrMalformedKey
 	}
+
+	// copy out the bytes so that the key never aliases the input
 	var privKey PrivateKey
-	offset := 1
-	privKey.PrivateKey = privKeyBytes[offset : offset+privKeySize]
-	offset += privKeySize
-	privKey.PublicKey = privKeyBytes[offset:]
+	privKey.PrivateKey = append(stdEd25519.PrivateKey(nil), body[:privKeySize]...)
+	privKey.PublicKey = append(PublicKey(nil), body[privKeySize:]...)
+
+	// both public halves must be derived from the seed
+	derived := stdEd25519.NewKeyFromSeed(privKey.PrivateKey.Seed())
+	if !bytes.Equal(derived, privKey.PrivateKey) || !bytes.Equal(derived[32:], privKey.PublicKey) {
+		return nil, ec.ErrInvalidKey
+	}
+	if err := Validate(privKey.PublicKey); nil != err {
+		return nil, err
+	}
+
 	return privKey, nil
 }
 
-// MarshalPubKey marshal pubKey to []byte where byte[0] records the marshal version
-func (msller *Marshaller) MarshalPubKey(pubKey crypto.PublicKey) ([]byte, error) {
-	pub, ok := pubKey.(PublicKey)
+func encodePubKey(v interface{}) ([]byte, error) {
+	pub, ok := v.(PublicKey)
 	if !ok || (len(pub) != pubKeySize) {
 		return nil, ec.ErrKeyTampered
 	}
-	result := make([]byte, 1)
-	result[0] = byte(marshalVersion)
-	return append(result, pub...), nil
+
+	return append([]byte(nil), pub...), nil
 }
 
-// UnmarshalPrivKey unmarshal privKeyBytes to privKey
-func (msller *Marshaller) UnmarshalPubKey(pubKeyBytes []byte) (crypto.PublicKey, error) {
-	if len(pubKeyBytes) == 0 || int(pubKeyBytes[0]) != unmarshalVersion {
-		return nil, ec.ErrWrongVersion
+func decodePubKey(body []byte) (interface{}, error) {
+	if len(body) != pubKeySize {
+		return nil, ec.ErrMalformedKey
 	}
-	var pubKey PublicKey
-	pubKey = pubKeyBytes[1:]
+
+	pubKey := append(PublicKey(nil), body...)
+	if err := Validate(pubKey); nil != err {
+		return nil, err
+	}
+
 	return pubKey, nil
 }
 
-// MarshalSig marshal sig to []byte where byte[0] records the marshal version
-func (msller *Marshaller) MarshalSig(sig ec.Sig) ([]byte, error) {
-	result := make([]byte, 1)
-	result[0] = byte(marshalVersion)
-	return append(result, sig...), nil
+func encodeSig(v interface{}) ([]byte, error) {
+	sig, ok := v.(ec.Sig)
+	if !ok {
+		return nil, ec.ErrMalformedSig
+	}
+
+	return append([]byte(nil), sig...), nil
 }
 
-// UnmarshalSig unmarshal sigBytes to sig
-func (msller *Marshaller) UnmarshalSig(sigBytes []byte) (ec.Sig, error) {
-	if len(sigBytes) == 0 || int(sigBytes[0]) != unmarshalVersion {
-		return nil, ec.ErrWrongVersion
+func decodeSig(body []byte) (interface{}, error) {
+	if len(body) != sigSize {
+		return nil, ec.ErrMalformedSig
 	}
 
-	return sigBytes[1:], nil
+	return append(ec.Sig(nil), body...), nil
+}
+
+func marshalTaggedSig(codecs *codec.Set, sig ec.Sig) ([]byte, error) {
+	return codecs.Sig.Marshal(sig)
+}
+
+func unmarshalTaggedSig(codecs *codec.Set, sigBytes []byte) (ec.Sig, error) {
+	sig, err := codecs.Sig.Unmarshal(sigBytes)
+	if nil != err {
+		return nil, err
+	}
 
+	return sig.(ec.Sig), nil
+}
+
+// encodeTaggedSig encodes the signatures as tag || sig
+func encodeTaggedSig(tag byte) codec.Encoder {
+	return func(v interface{}) ([]byte, error) {
+		sig, ok := v.(ec.Sig)
+		if !ok || (len(sig) != sigSize) {
+			return nil, ec.ErrMalformedSig
+		}
+
+		return append([]byte{tag}, sig...), nil
+	}
+}
+
+// decodeTaggedSig decodes tag || sig, refusing the signatures of the other tags
+func decodeTaggedSig(tag byte) codec.Decoder {
+	return func(body []byte) (interface{}, error) {
+		if len(body) != 1+sigSize {
+			return nil, ec.ErrMalformedSig
+		}
+		if body[0] != tag {
+			return nil, ErrWrongAlgorithm
+		}
+
+		return append(ec.Sig(nil), body[1:]...), nil
+	}
 }
@@ -0,0 +1,38 @@
+package ed25519
+
+import (
+	"github.com/sammy00/gravity/crypto/ec"
+)
+
+// Validate checks pubKey is the canonical encoding of a point of the curve
+// outside the small subgroup, whose 8 points include the identity and would
+// let one key verify signatures for many messages
+func Validate(pubKey PublicKey) error {
+	if len(pubKey) != pubKeySize {
+		return ec.ErrKeyTampered
+	}
+
+	p, ok := DecodePoint(pubKey)
+	if !ok {
+		return ec.ErrNotOnCurve
+	}
+
+	if isIdentity(p) {
+		return ec.ErrPointAtInfinity
+	}
+	if isSmallOrder(p) {
+		return ec.ErrSmallOrder
+	}
+
+	return nil
+}
+
+// Validate checks pubKey is a valid public key of ed25519
+func (ed *Worker) Validate(pubKey ec.PublicKey) error {
+	pub, ok := pubKey.(PublicKey)
+	if !ok {
+		return ec.ErrKeyTampered
+	}
+
+	return Validate(pub)
+}
@@ -0,0 +1,85 @@
+package ed25519_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		pub    string
+		expect error
+	}{
+		// the identity
+		{"0100000000000000000000000000000000000000000000000000000000000000", ec.ErrPointAtInfinity},
+		// points of order 2, 4 and 8
+		{"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", ec.ErrSmallOrder},
+		{"0000000000000000000000000000000000000000000000000000000000000000", ec.ErrSmallOrder},
+		{"0000000000000000000000000000000000000000000000000000000000000080", ec.ErrSmallOrder},
+		{"26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc05", ec.ErrSmallOrder},
+		{"c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a", ec.ErrSmallOrder},
+		// y = 2 has no x
+		{"0200000000000000000000000000000000000000000000000000000000000000", ec.ErrNotOnCurve},
+		// y = p is a non-canonical encoding of y = 0
+		{"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", ec.ErrNotOnCurve},
+		{"00", ec.ErrKeyTampered},
+	}
+
+	msller := new(ed25519.Marshaller)
+	for i, c := range testCases {
+		pub, _ := hex.DecodeString(c.pub)
+		if err := ed25519.Validate(pub); c.expect != err {
+			t.Fatalf("#%d: want %v, got %v", i, c.expect, err)
+		}
+
+		// the unmarshal path rejects invalid keys on its own
+		if 32 == len(pub) {
+			if _, err := msller.UnmarshalPubKey(append([]byte{1}, pub...)); c.expect != err {
+				t.Fatalf("#%d: want %v, got %v", i, c.expect, err)
+			}
+		}
+	}
+}
+
+func TestStrictVerify(t *testing.T) {
+	worker := new(ed25519.Worker)
+	priv, err := worker.GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	digest := sha3.Sum256([]byte("Hello World"))
+	sig, err := worker.Sign(priv, digest[:])
+	if nil != err {
+		t.Fatal(err)
+	}
+	if err := worker.Validate(priv.Public()); nil != err {
+		t.Fatal(err)
+	}
+
+	worker.Strict = true
+	if !worker.Verify(priv.Public(), digest[:], sig) {
+		t.Fatal("the verification shouldn't fail")
+	}
+
+	// the identity with the signature (R, S) = (identity, 0) verifies any
+	// message unless the key is refused
+	identity := make(ed25519.PublicKey, 32)
+	identity[0] = 1
+	forged := make([]byte, 64)
+	copy(forged, identity)
+
+	worker.Strict = false
+	if !worker.Verify(identity, digest[:], forged) {
+		t.Fatal("the forgery is expected to pass the loose verification")
+	}
+	worker.Strict = true
+	if worker.Verify(identity, digest[:], forged) {
+		t.Fatal("the verification should fail")
+	}
+}
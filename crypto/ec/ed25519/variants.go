@@ -0,0 +1,140 @@
+package ed25519
+
+// Note:
+// + Ed25519ph signs the SHA-512 digest of the message, which is what the
+//   digest-based ec.Worker contract expects, while Worker signs the bytes it's
+//   handed as the message itself
+// + Ed25519ctx binds the signature to a non-empty context string
+// + both share the keys of Worker
+
+import (
+	"crypto"
+	goEd25519 "crypto/ed25519"
+	"crypto/sha512"
+	"errors"
+	"io"
+
+	"github.com/sammy00/gravity/crypto/ec"
+)
+
+const maxContextSize = 255
+
+var (
+	// ErrDigestSize means the digest handed to WorkerPh isn't a SHA-512 digest
+	ErrDigestSize = errors.New("ed25519ph: the digest must be of 64 bytes")
+	// ErrContextSize means the context is too long, or empty for Ed25519ctx
+	ErrContextSize = errors.New("ed25519: the context must be of 1 to 255 bytes")
+	// ErrWrongAlgorithm means the marshalled signature is of another variant
+	ErrWrongAlgorithm = errors.New("ed25519: the signature is of another algorithm")
+)
+
+// WorkerPh works according to Ed25519ph, whose digests are SHA-512 digests
+type WorkerPh struct {
+	// Context is the optional context of at most 255 bytes
+	Context string
+	// Strict makes Verify refuse public keys failing Validate
+	Strict bool
+	// Mode selects the rules of Verify, defaulting to VerifyStdLib
+	Mode VerifyMode
+}
+
+// GenerateKey generates a (priv,pub) EC key pair
+func (ed *WorkerPh) GenerateKey(rand io.Reader) (ec.PrivateKey, error) {
+	return new(Worker).GenerateKey(rand)
+}
+
+// Sign signs the SHA-512 digest with privKey.
+func (ed *WorkerPh) Sign(privKey ec.PrivateKey, digest []byte) (ec.Sig, error) {
+	if sha512.Size != len(digest) {
+		return nil, ErrDigestSize
+	}
+	if len(ed.Context) > maxContextSize {
+		return nil, ErrContextSize
+	}
+
+	return signWithOptions(privKey, digest, &goEd25519.Options{Hash: crypto.SHA512, Context: ed.Context})
+}
+
+// Verify verifies the signature in sig of the SHA-512 digest using the public key, pubKey.
+// It returns value records whether the signature is valid.
+func (ed *WorkerPh) Verify(pubKey ec.PublicKey, digest []byte, sig ec.Sig) bool {
+	return verifyWithOptions(ed.Strict, ed.Mode, pubKey, digest, sig, &goEd25519.Options{Hash: crypto.SHA512, Context: ed.Context})
+}
+
+// Validate checks pubKey is a valid public key of ed25519
+func (ed *WorkerPh) Validate(pubKey ec.PublicKey) error {
+	return new(Worker).Validate(pubKey)
+}
+
+// MarshalSig marshal sig to []byte tagged as an Ed25519ph signature
+func (ed *WorkerPh) MarshalSig(sig ec.Sig) ([]byte, error) {
+	return marshalTaggedSig(codecsPh, sig)
+}
+
+// UnmarshalSig unmarshal sigBytes to sig, which must be tagged as an Ed25519ph signature
+func (ed *WorkerPh) UnmarshalSig(sigBytes []byte) (ec.Sig, error) {
+	return unmarshalTaggedSig(codecsPh, sigBytes)
+}
+
+// WorkerCtx works according to Ed25519ctx
+type WorkerCtx struct {
+	// Context is the mandatory context of 1 to 255 bytes
+	Context string
+	// Strict makes Verify refuse public keys failing Validate
+	Strict bool
+	// Mode selects the rules of Verify, defaulting to VerifyStdLib
+	Mode VerifyMode
+}
+
+// GenerateKey generates a (priv,pub) EC key pair
+func (ed *WorkerCtx) GenerateKey(rand io.Reader) (ec.PrivateKey, error) {
+	return new(Worker).GenerateKey(rand)
+}
+
+// Sign signs msg with privKey under the context.
+func (ed *WorkerCtx) Sign(privKey ec.PrivateKey, msg []byte) (ec.Sig, error) {
+	if (0 == len(ed.Context)) || (len(ed.Context) > maxContextSize) {
+		return nil, ErrContextSize
+	}
+
+	return signWithOptions(privKey, msg, &goEd25519.Options{Context: ed.Context})
+}
+
+// Verify verifies the signature in sig of msg under the context using the public key, pubKey.
+// It returns value records whether the signature is valid.
+func (ed *WorkerCtx) Verify(pubKey ec.PublicKey, msg []byte, sig ec.Sig) bool {
+	return (0 != len(ed.Context)) && verifyWithOptions(ed.Strict, ed.Mode, pubKey, msg, sig, &goEd25519.Options{Context: ed.Context})
+}
+
+// Validate checks pubKey is a valid public key of ed25519
+func (ed *WorkerCtx) Validate(pubKey ec.PublicKey) error {
+	return new(Worker).Validate(pubKey)
+}
+
+// MarshalSig marshal sig to []byte tagged as an Ed25519ctx signature
+func (ed *WorkerCtx) MarshalSig(sig ec.Sig) ([]byte, error) {
+	return marshalTaggedSig(codecsCtx, sig)
+}
+
+// UnmarshalSig unmarshal sigBytes to sig, which must be tagged as an Ed25519ctx signature
+func (ed *WorkerCtx) UnmarshalSig(sigBytes []byte) (ec.Sig, error) {
+	return unmarshalTaggedSig(codecsCtx, sigBytes)
+}
+
+func signWithOptions(privKey ec.PrivateKey, msg []byte, opts *goEd25519.Options) (ec.Sig, error) {
+	priv, ok := privKey.(PrivateKey)
+	if !ok || (len(priv.PrivateKey) != privKeySize) {
+		return nil, ec.ErrKeyTampered
+	}
+
+	return priv.PrivateKey.Sign(nil, msg, opts)
+}
+
+func verifyWithOptions(strict bool, mode VerifyMode, pubKey ec.PublicKey, msg []byte, sig ec.Sig, opts *goEd25519.Options) bool {
+	pub, ok := pubKey.(PublicKey)
+	if strict && ok && (nil != Validate(pub)) {
+		return false
+	}
+
+	return ok && verifyWithMode(mode, pub, msg, sig, opts)
+}
@@ -0,0 +1,234 @@
+package ed25519_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	stdEd25519 "golang.org/x/crypto/ed25519"
+)
+
+type rfc8032Case struct {
+	seed, pub, msg, ctx, sig string
+}
+
+func (c *rfc8032Case) keys(t *testing.T) (ed25519.PrivateKey, ed25519.PublicKey) {
+	priv := stdEd25519.NewKeyFromSeed(mustHex(t, c.seed))
+	pub := ed25519.PublicKey(mustHex(t, c.pub))
+	if !bytes.Equal(priv[32:], pub) {
+		t.Fatal("mismatched key pair")
+	}
+
+	return ed25519.PrivateKey{PrivateKey: priv, PublicKey: pub}, pub
+}
+
+type variantWorker interface {
+	ec.Worker
+	MarshalSig(sig ec.Sig) ([]byte, error)
+	UnmarshalSig(sigBytes []byte) (ec.Sig, error)
+}
+
+func runRFC8032(t *testing.T, worker variantWorker, c *rfc8032Case, digest []byte) {
+	priv, pub := c.keys(t)
+
+	sig, err := worker.Sign(priv, digest)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if want := mustHex(t, c.sig); !bytes.Equal(want, sig) {
+		t.Fatalf("want %x, got %x", want, sig)
+	}
+	if !worker.Verify(pub, digest, sig) {
+		t.Fatal("the verification shouldn't fail")
+	}
+
+	// the same signature is invalid for the pure ed25519
+	if new(ed25519.Worker).Verify(pub, digest, sig) {
+		t.Fatal("the verification should fail")
+	}
+}
+
+// TestEd25519ph checks the vector of RFC 8032 section 7.3
+func TestEd25519ph(t *testing.T) {
+	c := &rfc8032Case{
+		seed: "833fe62409237b9d62ec77587520911e9a759cec1d19755b7da901b96dca3d42",
+		pub:  "ec172b93ad5e563bf4932c70e1245034c35467ef2efd4d64ebf819683467e2bf",
+		msg:  "616263",
+		sig: "98a70222f0b8121aa9d30f813d683f809e462b469c7ff87639499bb94e6dae41" +
+			"31f85042463c2a355a2003d062adf5aaa10b8c61e636062aaad11c2a26083406",
+	}
+
+	digest := sha512.Sum512(mustHex(t, c.msg))
+	runRFC8032(t, new(ed25519.WorkerPh), c, digest[:])
+
+	if _, err := new(ed25519.WorkerPh).Sign(ed25519.PrivateKey{}, digest[:32]); ed25519.ErrDigestSize != err {
+		t.Fatalf("want %v, got %v", ed25519.ErrDigestSize, err)
+	}
+}
+
+// TestEd25519ctx checks the vectors of RFC 8032 section 7.2
+func TestEd25519ctx(t *testing.T) {
+	testCases := []rfc8032Case{
+		{
+			seed: "0305334e381af78f141cb666f6199f57bc3495335a256a95bd2a55bf546663f6",
+			pub:  "dfc9425e4f968f7f0c29f0259cf5f9aed6851c2bb4ad8bfb860cfee0ab248292",
+			msg:  "f726936d19c800494e3fdaff20b276a8",
+			ctx:  "foo",
+			sig: "55a4cc2f70a54e04288c5f4cd1e45a7bb520b36292911876cada7323198dd87a" +
+				"8b36950b95130022907a7fb7c4e9b2d5f6cca685a587b4b21f4b888e4e7edb0d",
+		},
+		{
+			seed: "0305334e381af78f141cb666f6199f57bc3495335a256a95bd2a55bf546663f6",
+			pub:  "dfc9425e4f968f7f0c29f0259cf5f9aed6851c2bb4ad8bfb860cfee0ab248292",
+			msg:  "f726936d19c800494e3fdaff20b276a8",
+			ctx:  "bar",
+			sig: "fc60d5872fc46b3aa69f8b5b4351d5808f92bcc044606db097abab6dbcb1aee3" +
+				"216c48e8b3b66431b5b186d1d28f8ee15a5ca2df6668346291c2043d4eb3e90d",
+		},
+		{
+			seed: "0305334e381af78f141cb666f6199f57bc3495335a256a95bd2a55bf546663f6",
+			pub:  "dfc9425e4f968f7f0c29f0259cf5f9aed6851c2bb4ad8bfb860cfee0ab248292",
+			msg:  "508e9e6882b979fea900f62adceaca35",
+			ctx:  "foo",
+			sig: "8b70c1cc8310e1de20ac53ce28ae6e7207f33c3295e03bb5c0732a1d20dc6490" +
+				"8922a8b052cf99b7c4fe107a5abb5b2c4085ae75890d02df26269d8945f84b0b",
+		},
+		{
+			seed: "ab9c2853ce297ddab85c993b3ae14bcad39b2c682beabc27d6d4eb20711d6560",
+			pub:  "0f1d1274943b91415889152e893d80e93275a1fc0b65fd71b4b0dda10ad7d772",
+			msg:  "f726936d19c800494e3fdaff20b276a8",
+			ctx:  "foo",
+			sig: "21655b5f1aa965996b3f97b3c849eafba922a0a62992f73b3d1b73106a84ad85" +
+				"e9b86a7b6005ea868337ff2d20a7f5fbd4cd10b0be49a68da2b2e0dc0ad8960f",
+		},
+	}
+
+	for i, c := range testCases {
+		worker := &ed25519.WorkerCtx{Context: c.ctx}
+		runRFC8032(t, worker, &c, mustHex(t, c.msg))
+
+		// another context makes another signature
+		_, pub := c.keys(t)
+		other := &ed25519.WorkerCtx{Context: c.ctx + "!"}
+		if other.Verify(pub, mustHex(t, c.msg), mustHex(t, c.sig)) {
+			t.Fatalf("#%d: the verification should fail", i)
+		}
+	}
+
+	if _, err := new(ed25519.WorkerCtx).Sign(ed25519.PrivateKey{}, nil); ed25519.ErrContextSize != err {
+		t.Fatalf("want %v, got %v", ed25519.ErrContextSize, err)
+	}
+}
+
+func TestTaggedSig(t *testing.T) {
+	ph, ctx := new(ed25519.WorkerPh), &ed25519.WorkerCtx{Context: "gravity"}
+	priv, err := ph.GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	digest := sha512.Sum512([]byte("Hello World"))
+	sig, err := ph.Sign(priv, digest[:])
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	data, err := ph.MarshalSig(sig)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if got, err := ph.UnmarshalSig(data); nil != err || !bytes.Equal(sig, got) {
+		t.Fatalf("the round trip changes the signature: %v", err)
+	}
+
+	// the tagged signature is refused by the other variants
+	if _, err := ctx.UnmarshalSig(data); ed25519.ErrWrongAlgorithm != err {
+		t.Fatalf("want %v, got %v", ed25519.ErrWrongAlgorithm, err)
+	}
+	if _, err := new(ed25519.Marshaller).UnmarshalSig(data); ec.ErrWrongVersion != err {
+		t.Fatalf("want %v, got %v", ec.ErrWrongVersion, err)
+	}
+
+	pure, _ := new(ed25519.Marshaller).MarshalSig(sig)
+	if _, err := ph.UnmarshalSig(pure); ec.ErrWrongVersion != err {
+		t.Fatalf("want %v, got %v", ec.ErrWrongVersion, err)
+	}
+}
+
+func TestVariantsVerifyModes(t *testing.T) {
+	modes := []ed25519.VerifyMode{ed25519.VerifyStdLib, ed25519.VerifyStrict,
+		ed25519.VerifyCofactored, ed25519.VerifyZIP215}
+
+	priv, err := new(ed25519.Worker).GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	pub := priv.Public()
+	digest := sha512.Sum512([]byte("Hello World"))
+
+	for _, mode := range modes {
+		ph := &ed25519.WorkerPh{Context: "gravity", Mode: mode}
+		ctx := &ed25519.WorkerCtx{Context: "gravity", Mode: mode}
+
+		// the challenges are prefixed by dom2 in every mode
+		sigPh, _ := ph.Sign(priv, digest[:])
+		sigCtx, _ := ctx.Sign(priv, digest[:])
+		if !ph.Verify(pub, digest[:], sigPh) || !ctx.Verify(pub, digest[:], sigCtx) {
+			t.Fatalf("mode %d: the verification shouldn't fail", mode)
+		}
+		if ph.Verify(pub, digest[:], sigCtx) || ctx.Verify(pub, digest[:], sigPh) {
+			t.Fatalf("mode %d: the signature of another variant is accepted", mode)
+		}
+		if (&ed25519.WorkerCtx{Context: "other", Mode: mode}).Verify(pub, digest[:], sigCtx) {
+			t.Fatalf("mode %d: the signature of another context is accepted", mode)
+		}
+		if ph.Verify(pub, digest[:32], sigPh) {
+			t.Fatalf("mode %d: the truncated digest is accepted", mode)
+		}
+	}
+
+	// the identity makes [S]B - [k]A = R hold for any challenge, letting the
+	// edge cases of verify_test.go carry over to the variants
+	//  0: small order A, small order R
+	//  1: non-canonical small order A, accepted if A is hashed as is
+	//  2: non-canonical small order R, accepted if R is hashed as is
+	identity := "0100000000000000000000000000000000000000000000000000000000000000"
+	nonCanonical := "eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f"
+	zero := "0000000000000000000000000000000000000000000000000000000000000000"
+	cases := [][2]string{
+		{identity, identity + zero},
+		{nonCanonical, identity + zero},
+		{identity, nonCanonical + zero},
+	}
+	expects := map[ed25519.VerifyMode][]bool{
+		ed25519.VerifyStdLib:     {true, true, false},
+		ed25519.VerifyStrict:     {false, false, false},
+		ed25519.VerifyCofactored: {true, false, false},
+		ed25519.VerifyZIP215:     {true, true, true},
+	}
+
+	for _, mode := range modes {
+		for _, strict := range []bool{false, true} {
+			workers := []ec.Worker{
+				&ed25519.WorkerPh{Mode: mode, Strict: strict},
+				&ed25519.WorkerCtx{Context: "gravity", Mode: mode, Strict: strict},
+			}
+
+			for i, c := range cases {
+				pub, sig := ed25519.PublicKey(mustHex(t, c[0])), mustHex(t, c[1])
+
+				// Strict refuses the small order keys in every mode
+				want := expects[mode][i] && !strict
+				for j, worker := range workers {
+					if got := worker.Verify(pub, digest[:], sig); want != got {
+						t.Fatalf("mode %d, strict %v, case %d, worker %d: want %v, got %v",
+							mode, strict, i, j, want, got)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package ed25519
+
+// Note:
+// + implementations disagree on the validity of the edge cases listed in
+//   "Taming the many EdDSAs", which splits consensus among peers verifying
+//   the same signatures with different libraries
+// + every mode other than VerifyStdLib requires a canonical S < L
+// + the modes apply to Ed25519ph and Ed25519ctx as well, whose challenges are
+//   prefixed by dom2 of RFC 8032
+
+import (
+	"bytes"
+	"crypto"
+	goEd25519 "crypto/ed25519"
+	"crypto/sha512"
+
+	"filippo.io/edwards25519"
+	stdEd25519 "golang.org/x/crypto/ed25519"
+)
+
+// VerifyMode selects the rules to accept an ed25519 signature
+type VerifyMode int
+
+const (
+	// VerifyStdLib defers to x/crypto, i.e. the cofactorless equation over
+	// canonical S without any check on A and R
+	VerifyStdLib VerifyMode = iota
+	// VerifyStrict follows RFC 8032 with the cofactorless equation, requiring
+	// canonical encodings of A and R and rejecting small order A and R
+	VerifyStrict
+	// VerifyCofactored follows RFC 8032 with the cofactored equation,
+	// requiring canonical encodings of A and R
+	VerifyCofactored
+	// VerifyZIP215 follows ZIP 215 with the cofactored equation, accepting
+	// non-canonical encodings of A and R, which are hashed as is
+	VerifyZIP215
+)
+
+// VerifyWithMode verifies sig of msg by pubKey under the rules of mode
+func VerifyWithMode(mode VerifyMode, pubKey PublicKey, msg, sig []byte) bool {
+	return verifyWithMode(mode, pubKey, msg, sig, nil)
+}
+
+// verifyWithMode verifies sig of msg by pubKey under the rules of mode, as
+// Ed25519ph or Ed25519ctx if opts asks for a prehash or a context
+func verifyWithMode(mode VerifyMode, pubKey PublicKey, msg, sig []byte, opts *goEd25519.Options) bool {
+	if (len(pubKey) != pubKeySize) || (len(sig) != sigSize) {
+		return false
+	}
+	if VerifyStdLib == mode {
+		if nil == opts {
+			return stdEd25519.Verify(pubKey, msg, sig)
+		}
+		return nil == goEd25519.VerifyWithOptions(goEd25519.PublicKey(pubKey), msg, sig, opts)
+	}
+
+	prefix, ok := dom2(opts)
+	if !ok || ((nil != opts) && (crypto.SHA512 == opts.Hash) && (sha512.Size != len(msg))) {
+		return false
+	}
+
+	s, err := new(edwards25519.Scalar).SetCanonicalBytes(sig[32:])
+	if nil != err {
+		return false
+	}
+
+	A, err := new(edwards25519.Point).SetBytes(pubKey)
+	if nil != err {
+		return false
+	}
+	R, err := new(edwards25519.Point).SetBytes(sig[:32])
+	if nil != err {
+		return false
+	}
+
+	if VerifyZIP215 != mode {
+		if !bytes.Equal(A.Bytes(), pubKey) || !bytes.Equal(R.Bytes(), sig[:32]) {
+			return false
+		}
+	}
+	if (VerifyStrict == mode) && (isSmallOrder(A) || isSmallOrder(R)) {
+		return false
+	}
+
+	// k = SHA512(dom2 || R || A || msg)
+	h := sha512.New()
+	h.Write(prefix)
+	h.Write(sig[:32])
+	h.Write(pubKey)
+	h.Write(msg)
+	k, err := new(edwards25519.Scalar).SetUniformBytes(h.Sum(nil))
+	if nil != err {
+		return false
+	}
+
+	// checks [S]B - [k]A == R, or its multiple by the cofactor, where A is
+	// negated rather than k since [L]A isn't the identity for mixed order A
+	minusA := new(edwards25519.Point).Negate(A)
+	diff := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(k, minusA, s)
+	if VerifyStrict == mode {
+		return 1 == diff.Equal(R)
+	}
+
+	diff.Subtract(diff, R)
+	return isIdentity(diff.MultByCofactor(diff))
+}
+
+// dom2 makes the prefix of the challenge as RFC 8032, which is empty for the
+// pure Ed25519, failing on a context over 255 bytes
+func dom2(opts *goEd25519.Options) ([]byte, bool) {
+	if (nil == opts) || ((0 == opts.Hash) && (0 == len(opts.Context))) {
+		return nil, true
+	}
+	if len(opts.Context) > maxContextSize {
+		return nil, false
+	}
+
+	phflag := byte(0)
+	if crypto.SHA512 == opts.Hash {
+		phflag = 1
+	}
+
+	prefix := append([]byte("SigEd25519 no Ed25519 collisions"), phflag, byte(len(opts.Context)))
+	return append(prefix, opts.Context...), true
+}
+
+func isIdentity(p *edwards25519.Point) bool {
+	return 1 == p.Equal(edwards25519.NewIdentityPoint())
+}
+
+// isSmallOrder tells whether p lies in the subgroup of order 8
+func isSmallOrder(p *edwards25519.Point) bool {
+	return isIdentity(new(edwards25519.Point).MultByCofactor(p))
+}
@@ -0,0 +1,88 @@
+package ed25519_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+)
+
+type speccheckCase struct {
+	Message string `json:"message"`
+	PubKey  string `json:"pub_key"`
+	Sig     string `json:"signature"`
+}
+
+func readJSON(t *testing.T, path string, v interface{}) {
+	data, err := ioutil.ReadFile(path)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func TestVerifyModesSpeccheck(t *testing.T) {
+	var cases []speccheckCase
+	readJSON(t, "testdata/speccheck.json", &cases)
+
+	// expected results of the 12 cases as numbered in "Taming the many EdDSAs"
+	//  0: small order A, small order R
+	//  1: small order A, mixed order R
+	//  2: mixed order A, small order R
+	//  3: mixed order A, mixed order R
+	//  4: valid under the cofactored equation only
+	//  5: valid if 8(hA) is computed instead of (8h mod L)A
+	//  6: non-canonical S (S > L)
+	//  7: non-canonical S (S >> L)
+	//  8: non-canonical small order R, accepted if R is reduced before hashing
+	//  9: non-canonical small order R, accepted if R is hashed as is
+	// 10: non-canonical small order A, accepted if A is reduced before hashing
+	// 11: non-canonical small order A, accepted if A is hashed as is
+	expects := map[ed25519.VerifyMode][]bool{
+		ed25519.VerifyStdLib:     {true, true, true, true, false, false, false, false, false, false, false, true},
+		ed25519.VerifyStrict:     {false, false, false, true, false, false, false, false, false, false, false, false},
+		ed25519.VerifyCofactored: {true, true, true, true, true, true, false, false, false, false, false, false},
+		ed25519.VerifyZIP215:     {true, true, true, true, true, true, false, false, false, true, true, true},
+	}
+
+	for mode, expect := range expects {
+		worker := &ed25519.Worker{Mode: mode}
+
+		for i, c := range cases {
+			pub := ed25519.PublicKey(mustHex(t, c.PubKey))
+			if got := worker.Verify(pub, mustHex(t, c.Message), mustHex(t, c.Sig)); expect[i] != got {
+				t.Fatalf("mode %d, case %d: want %v, got %v", mode, i, expect[i], got)
+			}
+		}
+	}
+}
+
+func TestVerifyModesZIP215(t *testing.T) {
+	var cases [][2]string
+	readJSON(t, "testdata/zip215.json", &cases)
+
+	msg := []byte("Zcash")
+	for i, c := range cases {
+		pub, sig := ed25519.PublicKey(mustHex(t, c[0])), mustHex(t, c[1])
+
+		if !ed25519.VerifyWithMode(ed25519.VerifyZIP215, pub, msg, sig) {
+			t.Fatalf("#%d: the verification shouldn't fail", i)
+		}
+		if ed25519.VerifyWithMode(ed25519.VerifyStrict, pub, msg, sig) {
+			t.Fatalf("#%d: the verification should fail", i)
+		}
+	}
+}
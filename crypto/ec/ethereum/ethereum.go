@@ -0,0 +1,85 @@
+// Package ethereum makes and verifies the wallet signatures of Ethereum, i.e.
+// the personal messages of EIP-191 and the typed structured data of EIP-712,
+// as the recoverable signatures of secp.Worker
+package ethereum
+
+// Note:
+// + wallets sign r||s||v, where v is 27 plus the recovery id, and the bare
+//   recovery ids 0 and 1 are accepted as well
+// + the signers are recovered as their EIP-55 addresses, which is what the
+//   dApps compare against, and the high s is refused as by EIP-2
+
+import (
+	"errors"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/address"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	// ErrUnknownType indicates a type of the typed data is neither atomic,
+	// dynamic, an array nor a declared struct
+	ErrUnknownType = errors.New("ethereum: unknown type")
+	// ErrTypeMismatch indicates a value of the typed data doesn't match its type
+	ErrTypeMismatch = errors.New("ethereum: value doesn't match its type")
+)
+
+// SigSize is the byte size of the wallet signatures
+const SigSize = secp.RecoverableSigSize
+
+// the offset of v in the wallet signatures
+const vOffset = 27
+
+var worker = newWorker()
+
+func newWorker() *secp.Worker {
+	w := secp.New()
+	w.Strict = true
+
+	return w
+}
+
+// sign signs digest with priv as r||s||v
+func sign(priv *secp.PrivateKey, digest []byte) (ec.Sig, error) {
+	sig, err := worker.SignRecoverable(priv, digest)
+	if nil != err {
+		return nil, err
+	}
+	sig[SigSize-1] += vOffset
+
+	return sig, nil
+}
+
+// recoverSigner recovers the address of the signer of digest from the
+// r||s||v signature sig
+func recoverSigner(digest []byte, sig ec.Sig) (string, error) {
+	if SigSize != len(sig) {
+		return "", ec.ErrMalformedSig
+	}
+
+	recoverable := append(ec.Sig(nil), sig...)
+	if vOffset <= recoverable[SigSize-1] {
+		recoverable[SigSize-1] -= vOffset
+	}
+	if 1 < recoverable[SigSize-1] {
+		return "", ec.ErrMalformedSig
+	}
+
+	pub, err := worker.RecoverPubKey(digest, recoverable)
+	if nil != err {
+		return "", err
+	}
+
+	return address.Ethereum(pub.(*secp.PublicKey))
+}
+
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,28 @@
+package ethereum
+
+import (
+	"strconv"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// the version 0x45 of EIP-191 prefixing the messages of personal_sign
+const personalPrefix = "\x19Ethereum Signed Message:\n"
+
+// PersonalHash computes the digest personal_sign signs for msg, i.e. the
+// Keccak-256 of the prefix, the decimal length of msg and msg
+func PersonalHash(msg []byte) []byte {
+	return keccak256([]byte(personalPrefix+strconv.Itoa(len(msg))), msg)
+}
+
+// SignPersonal signs msg with priv as personal_sign does
+func SignPersonal(priv *secp.PrivateKey, msg []byte) (ec.Sig, error) {
+	return sign(priv, PersonalHash(msg))
+}
+
+// RecoverPersonalSigner recovers the address which signed msg into sig by
+// personal_sign
+func RecoverPersonalSigner(msg []byte, sig ec.Sig) (string, error) {
+	return recoverSigner(PersonalHash(msg), sig)
+}
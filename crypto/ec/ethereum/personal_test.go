@@ -0,0 +1,134 @@
+package ethereum_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/address"
+	"github.com/sammy00/gravity/crypto/ec/ethereum"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+	"golang.org/x/crypto/sha3"
+)
+
+// cowKey returns keccak256("cow")
+func cowKey() *secp.PrivateKey {
+	d := sha3.NewLegacyKeccak256()
+	d.Write([]byte("cow"))
+
+	priv := new(secp.PrivateKey)
+	priv.Curve = secp.S256()
+	priv.D = new(big.Int).SetBytes(d.Sum(nil))
+	priv.X, priv.Y = priv.Curve.ScalarBaseMult(priv.D.Bytes())
+
+	return priv
+}
+
+// cowAddress is the address of keccak256("cow"), the signer of the examples
+// of EIP-712
+const cowAddress = "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"
+
+func TestPersonalHash(t *testing.T) {
+	testCases := []struct {
+		msg    string
+		digest string
+	}{
+		{"", "5f35dce98ba4fba25530a026ed80b2cecdaa31091ba4958b99b52ea1d068adad"},
+		{"Hello World", "a1de988600a42c4b4ab089b619297c17d53cffae5d5120d82d8a92d0bb3b78f2"},
+	}
+
+	for i, c := range testCases {
+		if got := hex.EncodeToString(ethereum.PersonalHash([]byte(c.msg))); c.digest != got {
+			t.Fatalf("#%d: want %s, got %s", i, c.digest, got)
+		}
+	}
+}
+
+// signatures of personal_sign by keccak256("cow")
+func TestRecoverPersonalSigner(t *testing.T) {
+	testCases := []struct {
+		msg string
+		sig string
+	}{
+		{"", "68c36703cfae77b264e66cf9587aa39dd76b66ff1317e563b4566d9ea5d8d60e" +
+			"5b9be8c58a324e1dbb424365aa778a2faec2d3f922bf0339cda43d76c492a5ab1c"},
+		{"Hello World", "b48cbb7331343e0d831876892f1eac6b4fbec2e3f5177a4790f4bed0a9e586af" +
+			"246b639027d0ade24c0219595b5fa30857b3b75dc64b6d52017b6e39365892071b"},
+	}
+
+	for i, c := range testCases {
+		sig, _ := hex.DecodeString(c.sig)
+
+		got, err := ethereum.RecoverPersonalSigner([]byte(c.msg), sig)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if cowAddress != got {
+			t.Fatalf("#%d: want %s, got %s", i, cowAddress, got)
+		}
+
+		// the bare recovery id is accepted as well
+		sig[ethereum.SigSize-1] -= 27
+		if got, err := ethereum.RecoverPersonalSigner([]byte(c.msg), sig); (nil != err) || (cowAddress != got) {
+			t.Fatalf("#%d: want %s, got %s (%v)", i, cowAddress, got, err)
+		}
+
+		// another message recovers another address
+		if got, _ := ethereum.RecoverPersonalSigner([]byte(c.msg+"!"), sig); cowAddress == got {
+			t.Fatalf("#%d: the recovery should fail", i)
+		}
+	}
+}
+
+func TestSignPersonal(t *testing.T) {
+	priv, err := secp.New().GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	want, err := address.Ethereum(priv.Public().(*secp.PublicKey))
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	msg := []byte("Hello World")
+	sig, err := ethereum.SignPersonal(priv.(*secp.PrivateKey), msg)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if v := sig[ethereum.SigSize-1]; (27 != v) && (28 != v) {
+		t.Fatalf("want v of 27 or 28, got %d", v)
+	}
+
+	got, err := ethereum.RecoverPersonalSigner(msg, sig)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if want != got {
+		t.Fatalf("want %s, got %s", want, got)
+	}
+}
+
+func TestRecoverPersonalSignerMalformed(t *testing.T) {
+	msg := []byte("Hello World")
+	sig, err := ethereum.SignPersonal(cowKey(), msg)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	testCases := []ec.Sig{
+		nil,
+		sig[:64],
+		append(sig[:64:64], 29),
+		append(sig[:64:64], 2),
+		append(bytes.Repeat([]byte{0xff}, 32), sig[32:]...),
+	}
+
+	for i, c := range testCases {
+		if _, err := ethereum.RecoverPersonalSigner(msg, c); ec.ErrMalformedSig != err {
+			t.Fatalf("#%d: want %v, got %v", i, ec.ErrMalformedSig, err)
+		}
+	}
+}
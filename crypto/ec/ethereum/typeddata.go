@@ -0,0 +1,504 @@
+package ethereum
+
+// Note:
+// + encodeType writes the struct followed by the structs it references
+//   sorted by name, as Name(type1 name1,type2 name2)
+// + the atomic values are encoded in 32 bytes, the dynamic bytes and string
+//   as their Keccak-256, the structs as their hashStruct, and the arrays as
+//   the Keccak-256 of the concatenated encodings of their items
+// + the numbers are accepted as JSON numbers, decimal or 0x-prefixed hex
+//   strings and *big.Int, and the bytes as 0x-prefixed hex strings
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/address"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// domainType is the struct type of the domain
+const domainType = "EIP712Domain"
+
+// TypedField is a member of a struct type
+type TypedField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TypedData is the typed structured data of EIP-712 in the JSON accepted by
+// eth_signTypedData_v4
+type TypedData struct {
+	Types       map[string][]TypedField `json:"types"`
+	PrimaryType string                  `json:"primaryType"`
+	Domain      map[string]interface{}  `json:"domain"`
+	Message     map[string]interface{}  `json:"message"`
+}
+
+// the members of the domain in their order of EIP-712, used whenever the
+// EIP712Domain type isn't declared
+var domainFields = []TypedField{
+	{Name: "name", Type: "string"},
+	{Name: "version", Type: "string"},
+	{Name: "chainId", Type: "uint256"},
+	{Name: "verifyingContract", Type: "address"},
+	{Name: "salt", Type: "bytes32"},
+}
+
+// EncodeType encodes the struct type name along with the structs it
+// references
+func (td *TypedData) EncodeType(name string) (string, error) {
+	if _, ok := td.fields(name); !ok {
+		return "", ErrUnknownType
+	}
+
+	deps := make(map[string]bool)
+	if err := td.dependencies(name, deps); nil != err {
+		return "", err
+	}
+	delete(deps, name)
+
+	names := make([]string, 0, len(deps))
+	for dep := range deps {
+		names = append(names, dep)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, s := range append([]string{name}, names...) {
+		fields, _ := td.fields(s)
+
+		sb.WriteString(s)
+		sb.WriteByte('(')
+		for i, f := range fields {
+			if 0 < i {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(f.Type)
+			sb.WriteByte(' ')
+			sb.WriteString(f.Name)
+		}
+		sb.WriteByte(')')
+	}
+
+	return sb.String(), nil
+}
+
+// TypeHash computes the Keccak-256 of the encoding of the struct type name
+func (td *TypedData) TypeHash(name string) ([]byte, error) {
+	enc, err := td.EncodeType(name)
+	if nil != err {
+		return nil, err
+	}
+
+	return keccak256([]byte(enc)), nil
+}
+
+// EncodeData encodes data as the struct type name, i.e. its type hash
+// followed by the encoding of every member
+func (td *TypedData) EncodeData(name string, data map[string]interface{}) ([]byte, error) {
+	fields, ok := td.fields(name)
+	if !ok {
+		return nil, ErrUnknownType
+	}
+	typeHash, err := td.TypeHash(name)
+	if nil != err {
+		return nil, err
+	}
+
+	members := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		members[f.Name] = true
+	}
+	for k := range data {
+		if !members[k] {
+			return nil, ErrTypeMismatch
+		}
+	}
+
+	out := make([]byte, 0, 32*(1+len(fields)))
+	out = append(out, typeHash...)
+	for _, f := range fields {
+		v, ok := data[f.Name]
+		if !ok {
+			return nil, ErrTypeMismatch
+		}
+
+		enc, err := td.encodeValue(f.Type, v)
+		if nil != err {
+			return nil, err
+		}
+		out = append(out, enc...)
+	}
+
+	return out, nil
+}
+
+// HashStruct computes the Keccak-256 of the encoding of data as the struct
+// type name
+func (td *TypedData) HashStruct(name string, data map[string]interface{}) ([]byte, error) {
+	enc, err := td.EncodeData(name, data)
+	if nil != err {
+		return nil, err
+	}
+
+	return keccak256(enc), nil
+}
+
+// DomainSeparator computes the hashStruct of the domain
+func (td *TypedData) DomainSeparator() ([]byte, error) {
+	return td.HashStruct(domainType, td.Domain)
+}
+
+// Hash computes the digest signed for the typed data, i.e. the Keccak-256 of
+// 0x1901, the domain separator and the hashStruct of the message, which is
+// left out if the primary type is the domain itself
+func (td *TypedData) Hash() ([]byte, error) {
+	domainSeparator, err := td.DomainSeparator()
+	if nil != err {
+		return nil, err
+	}
+	if domainType == td.PrimaryType {
+		return keccak256([]byte{0x19, 0x01}, domainSeparator), nil
+	}
+
+	message, err := td.HashStruct(td.PrimaryType, td.Message)
+	if nil != err {
+		return nil, err
+	}
+
+	return keccak256([]byte{0x19, 0x01}, domainSeparator, message), nil
+}
+
+// SignTypedData signs td with priv as eth_signTypedData_v4 does
+func SignTypedData(priv *secp.PrivateKey, td *TypedData) (ec.Sig, error) {
+	digest, err := td.Hash()
+	if nil != err {
+		return nil, err
+	}
+
+	return sign(priv, digest)
+}
+
+// RecoverTypedDataSigner recovers the address which signed td into sig by
+// eth_signTypedData_v4
+func RecoverTypedDataSigner(td *TypedData, sig ec.Sig) (string, error) {
+	digest, err := td.Hash()
+	if nil != err {
+		return "", err
+	}
+
+	return recoverSigner(digest, sig)
+}
+
+// fields looks up the members of the struct type name
+func (td *TypedData) fields(name string) ([]TypedField, bool) {
+	fields, ok := td.Types[name]
+	if !ok && (domainType == name) {
+		return td.domainFields(), true
+	}
+
+	return fields, ok
+}
+
+// domainFields picks the members of the domain in use, in their order
+func (td *TypedData) domainFields() []TypedField {
+	var fields []TypedField
+	for _, f := range domainFields {
+		if _, ok := td.Domain[f.Name]; ok {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
+
+// dependencies collects into deps the struct type name and those it
+// references, checking every type along the way
+func (td *TypedData) dependencies(name string, deps map[string]bool) error {
+	if deps[name] {
+		return nil
+	}
+	deps[name] = true
+
+	fields, _ := td.fields(name)
+	for _, f := range fields {
+		if ("" == f.Name) || ("" == f.Type) {
+			return ErrUnknownType
+		}
+
+		// strip every dimension of the arrays
+		base := f.Type
+		for {
+			item, _, err := splitArray(base)
+			if nil != err {
+				return err
+			}
+			if "" == item {
+				break
+			}
+			base = item
+		}
+
+		if _, ok := td.Types[base]; ok {
+			if err := td.dependencies(base, deps); nil != err {
+				return err
+			}
+		} else if !isAtomic(base) {
+			return ErrUnknownType
+		}
+	}
+
+	return nil
+}
+
+// splitArray splits the array type typ into the type of its items and its
+// fixed length, which is -1 for the dynamic arrays, or returns an empty item
+// type if typ isn't an array
+func splitArray(typ string) (string, int, error) {
+	if !strings.HasSuffix(typ, "]") {
+		return "", 0, nil
+	}
+
+	i := strings.LastIndexByte(typ, '[')
+	if 1 > i {
+		return "", 0, ErrUnknownType
+	}
+	if i+2 == len(typ) {
+		return typ[:i], -1, nil
+	}
+
+	n, err := strconv.Atoi(typ[i+1 : len(typ)-1])
+	if (nil != err) || (0 > n) || ('+' == typ[i+1]) {
+		return "", 0, ErrUnknownType
+	}
+
+	return typ[:i], n, nil
+}
+
+// isAtomic tells whether typ is an atomic or dynamic type other than arrays
+func isAtomic(typ string) bool {
+	switch typ {
+	case "address", "bool", "string", "bytes", "int", "uint":
+		return true
+	}
+
+	if n, ok := typeSize(typ, "bytes"); ok {
+		return (1 <= n) && (n <= 32)
+	}
+	if n, ok := intSize(typ); ok {
+		return (8 <= n) && (n <= 256) && (0 == n%8)
+	}
+
+	return false
+}
+
+// typeSize parses the size N suffixing prefix in typ
+func typeSize(typ, prefix string) (int, bool) {
+	if !strings.HasPrefix(typ, prefix) || (len(prefix) == len(typ)) || ('0' == typ[len(prefix)]) {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(typ[len(prefix):])
+	if (nil != err) || ('+' == typ[len(prefix)]) || ('-' == typ[len(prefix)]) {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// intSize parses the bit size of the integer type typ, where int and uint
+// are aliases of int256 and uint256
+func intSize(typ string) (int, bool) {
+	switch typ {
+	case "int", "uint":
+		return 256, true
+	}
+	if n, ok := typeSize(typ, "uint"); ok {
+		return n, true
+	}
+
+	return typeSize(typ, "int")
+}
+
+// encodeValue encodes v as typ into 32 bytes
+func (td *TypedData) encodeValue(typ string, v interface{}) ([]byte, error) {
+	item, n, err := splitArray(typ)
+	if nil != err {
+		return nil, err
+	}
+	if "" != item {
+		items, ok := v.([]interface{})
+		if !ok || ((0 <= n) && (n != len(items))) {
+			return nil, ErrTypeMismatch
+		}
+
+		var enc []byte
+		for _, x := range items {
+			e, err := td.encodeValue(item, x)
+			if nil != err {
+				return nil, err
+			}
+			enc = append(enc, e...)
+		}
+
+		return keccak256(enc), nil
+	}
+
+	if _, ok := td.Types[typ]; ok {
+		data, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, ErrTypeMismatch
+		}
+
+		return td.HashStruct(typ, data)
+	}
+
+	return encodeAtomic(typ, v)
+}
+
+// encodeAtomic encodes v as the atomic or dynamic type typ
+func encodeAtomic(typ string, v interface{}) ([]byte, error) {
+	out := make([]byte, 32)
+
+	switch typ {
+	case "address":
+		var addr []byte
+		switch x := v.(type) {
+		case string:
+			var err error
+			if addr, err = address.ParseEthereum(x); nil != err {
+				return nil, ErrTypeMismatch
+			}
+		case []byte:
+			addr = x
+		}
+		if address.EthereumAddressSize != len(addr) {
+			return nil, ErrTypeMismatch
+		}
+		copy(out[12:], addr)
+		return out, nil
+	case "bool":
+		b, ok := v.(bool)
+		if !ok {
+			return nil, ErrTypeMismatch
+		}
+		if b {
+			out[31] = 1
+		}
+		return out, nil
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return nil, ErrTypeMismatch
+		}
+		return keccak256([]byte(s)), nil
+	case "bytes":
+		b, err := parseBytes(v)
+		if nil != err {
+			return nil, err
+		}
+		return keccak256(b), nil
+	}
+
+	if size, ok := typeSize(typ, "bytes"); ok {
+		b, err := parseBytes(v)
+		if (nil != err) || (size != len(b)) || (32 < size) {
+			return nil, ErrTypeMismatch
+		}
+		copy(out, b)
+		return out, nil
+	}
+
+	bits, ok := intSize(typ)
+	if !ok {
+		return nil, ErrUnknownType
+	}
+	x, err := parseInt(v)
+	if nil != err {
+		return nil, err
+	}
+
+	// unsigned in [0, 2^bits) and signed in [-2^(bits-1), 2^(bits-1))
+	lo, hi := new(big.Int), new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	if strings.HasPrefix(typ, "int") {
+		hi.Rsh(hi, 1)
+		lo.Neg(hi)
+	}
+	if (x.Cmp(lo) < 0) || (x.Cmp(hi) >= 0) {
+		return nil, ErrTypeMismatch
+	}
+
+	// two's complement over 256 bits
+	if 0 > x.Sign() {
+		x = new(big.Int).Add(x, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	x.FillBytes(out)
+
+	return out, nil
+}
+
+// parseBytes parses v as bytes, given as is or in 0x-prefixed hex
+func parseBytes(v interface{}) ([]byte, error) {
+	switch x := v.(type) {
+	case []byte:
+		return x, nil
+	case string:
+		if !strings.HasPrefix(x, "0x") && !strings.HasPrefix(x, "0X") {
+			return nil, ErrTypeMismatch
+		}
+		b, err := hex.DecodeString(x[2:])
+		if nil != err {
+			return nil, ErrTypeMismatch
+		}
+		return b, nil
+	}
+
+	return nil, ErrTypeMismatch
+}
+
+// parseInt parses v as an integer, given as a JSON number, a decimal or
+// 0x-prefixed hex string or a Go integer
+func parseInt(v interface{}) (*big.Int, error) {
+	switch x := v.(type) {
+	case *big.Int:
+		if nil != x {
+			return new(big.Int).Set(x), nil
+		}
+	case int:
+		return big.NewInt(int64(x)), nil
+	case int64:
+		return big.NewInt(x), nil
+	case uint64:
+		return new(big.Int).SetUint64(x), nil
+	case float64:
+		// JSON numbers beyond 2^53 have lost their precision already
+		if (x == math.Trunc(x)) && (math.Abs(x) <= 1<<53) {
+			return big.NewInt(int64(x)), nil
+		}
+	case json.Number:
+		return parseInt(string(x))
+	case string:
+		base, digits := 10, strings.TrimPrefix(x, "-")
+		if strings.HasPrefix(x, "0x") || strings.HasPrefix(x, "0X") {
+			base, digits = 16, x[2:]
+		}
+		if ("" == digits) || ('+' == digits[0]) || ('-' == digits[0]) {
+			break
+		}
+		if z, ok := new(big.Int).SetString(digits, base); ok {
+			if strings.HasPrefix(x, "-") {
+				z.Neg(z)
+			}
+			return z, nil
+		}
+	}
+
+	return nil, ErrTypeMismatch
+}
@@ -0,0 +1,224 @@
+package ethereum_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec/ethereum"
+)
+
+func readTypedData(t *testing.T, name string) *ethereum.TypedData {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	td := new(ethereum.TypedData)
+	if err := json.Unmarshal(data, td); nil != err {
+		t.Fatal(err)
+	}
+
+	return td
+}
+
+// the Mail example of EIP-712
+func TestTypedDataMail(t *testing.T) {
+	td := readTypedData(t, "mail.json")
+
+	enc, err := td.EncodeType("Mail")
+	if nil != err {
+		t.Fatal(err)
+	}
+	if want := "Mail(Person from,Person to,string contents)Person(string name,address wallet)"; want != enc {
+		t.Fatalf("want %s, got %s", want, enc)
+	}
+
+	typeHash, err := td.TypeHash("Mail")
+	if nil != err {
+		t.Fatal(err)
+	}
+	domainSeparator, err := td.DomainSeparator()
+	if nil != err {
+		t.Fatal(err)
+	}
+	message, err := td.HashStruct("Mail", td.Message)
+	if nil != err {
+		t.Fatal(err)
+	}
+	digest, err := td.Hash()
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		got  []byte
+		want string
+	}{
+		{typeHash, "a0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2"},
+		{domainSeparator, "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"},
+		{message, "c52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e"},
+		{digest, "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"},
+	}
+
+	for i, c := range testCases {
+		if got := hex.EncodeToString(c.got); c.want != got {
+			t.Fatalf("#%d: want %s, got %s", i, c.want, got)
+		}
+	}
+}
+
+func TestRecoverTypedDataSigner(t *testing.T) {
+	testCases := []struct {
+		file   string
+		digest string
+		sig    string
+	}{
+		{
+			"mail.json",
+			"be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2",
+			"4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d" +
+				"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b915621c",
+		},
+		{
+			// arrays of structs
+			"mail_array.json",
+			"528c9e0892b9ae24cf1dd215db6a9ea1910b0b2472cd2c95101214601a5add53",
+			"3dd28dd0c402c5fc2454abb8e86f6163a9c3d36a1ef6eb168087dd05d636f768" +
+				"41999f6dbdf60380ea89a7f272b9acd424e356085f6fe1c84fd05da31557f5f91b",
+		},
+		{
+			// negative integers, bytesN, fixed and nested arrays and nested
+			// structs
+			"order.json",
+			"2942c66d1899d9b19b0766883459a2d272d66086175b7141a2a8201538233640",
+			"de761aafd5db1855d3f47052eeaf537a2f53a8c71f307d8a35eda207565e84fc" +
+				"117bbb468489a15478e10073eb34ab4c0e2ce0101aec94c2cc548b4d2d0214c91b",
+		},
+	}
+
+	for i, c := range testCases {
+		td := readTypedData(t, c.file)
+
+		digest, err := td.Hash()
+		if nil != err {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(digest); c.digest != got {
+			t.Fatalf("#%d: want %s, got %s", i, c.digest, got)
+		}
+
+		sig, _ := hex.DecodeString(c.sig)
+		got, err := ethereum.RecoverTypedDataSigner(td, sig)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if cowAddress != got {
+			t.Fatalf("#%d: want %s, got %s", i, cowAddress, got)
+		}
+	}
+}
+
+func TestSignTypedData(t *testing.T) {
+	td := readTypedData(t, "order.json")
+
+	sig, err := ethereum.SignTypedData(cowKey(), td)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	got, err := ethereum.RecoverTypedDataSigner(td, sig)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if cowAddress != got {
+		t.Fatalf("want %s, got %s", cowAddress, got)
+	}
+
+	// any change of the message changes the signer
+	td.Message["delta"] = "-6"
+	if got, _ := ethereum.RecoverTypedDataSigner(td, sig); cowAddress == got {
+		t.Fatal("the recovery should fail")
+	}
+}
+
+// the domain type may be left out, and derived from the domain then
+func TestTypedDataImplicitDomain(t *testing.T) {
+	td := readTypedData(t, "mail.json")
+	delete(td.Types, "EIP712Domain")
+
+	domainSeparator, err := td.DomainSeparator()
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	const want = "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"
+	if got := hex.EncodeToString(domainSeparator); want != got {
+		t.Fatalf("want %s, got %s", want, got)
+	}
+}
+
+func TestTypedDataMalformed(t *testing.T) {
+	testCases := []struct {
+		file   string
+		mutate func(td *ethereum.TypedData)
+		err    error
+	}{
+		{"mail.json", func(td *ethereum.TypedData) {
+			td.PrimaryType = "Letter"
+		}, ethereum.ErrUnknownType},
+		{"mail.json", func(td *ethereum.TypedData) {
+			td.Types["Mail"][2].Type = "text"
+		}, ethereum.ErrUnknownType},
+		{"mail.json", func(td *ethereum.TypedData) {
+			td.Types["Mail"][2].Type = "uint7"
+		}, ethereum.ErrUnknownType},
+		{"mail.json", func(td *ethereum.TypedData) {
+			td.Types["Mail"][2].Type = "bytes33"
+		}, ethereum.ErrUnknownType},
+		{"mail.json", func(td *ethereum.TypedData) {
+			td.Types["Mail"][1].Type = "Person[x]"
+		}, ethereum.ErrUnknownType},
+		{"mail.json", func(td *ethereum.TypedData) {
+			td.Message["cc"] = "Alice"
+		}, ethereum.ErrTypeMismatch},
+		{"mail.json", func(td *ethereum.TypedData) {
+			delete(td.Message, "contents")
+		}, ethereum.ErrTypeMismatch},
+		{"mail.json", func(td *ethereum.TypedData) {
+			td.Domain["chainId"] = 1.5
+		}, ethereum.ErrTypeMismatch},
+		{"mail.json", func(td *ethereum.TypedData) {
+			td.Domain["chainId"] = "-1"
+		}, ethereum.ErrTypeMismatch},
+		{"mail.json", func(td *ethereum.TypedData) {
+			td.Domain["verifyingContract"] = "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccc"
+		}, ethereum.ErrTypeMismatch},
+		{"mail.json", func(td *ethereum.TypedData) {
+			td.Message["from"] = "Cow"
+		}, ethereum.ErrTypeMismatch},
+		{"order.json", func(td *ethereum.TypedData) {
+			td.Message["flags"] = []interface{}{true}
+		}, ethereum.ErrTypeMismatch},
+		{"order.json", func(td *ethereum.TypedData) {
+			td.Message["tag"] = "0xdead"
+		}, ethereum.ErrTypeMismatch},
+		{"order.json", func(td *ethereum.TypedData) {
+			td.Message["grid"] = []interface{}{[]interface{}{256.0}}
+		}, ethereum.ErrTypeMismatch},
+		{"order.json", func(td *ethereum.TypedData) {
+			td.Message["delta"] = "-9223372036854775809"
+		}, ethereum.ErrTypeMismatch},
+	}
+
+	for i, c := range testCases {
+		td := readTypedData(t, c.file)
+		c.mutate(td)
+
+		if _, err := td.Hash(); c.err != err {
+			t.Fatalf("#%d: want %v, got %v", i, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package keyring
+
+import (
+	stdEd25519 "crypto/ed25519"
+	"crypto/elliptic"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// the algorithms of the keys, named after the codecs of their workers
+const (
+	AlgEd25519    = "ed25519"
+	AlgEd25519ph  = "ed25519ph"
+	AlgEd25519ctx = "ed25519ctx"
+	AlgECDSA256   = "ecdsa256"
+	AlgECDSA512   = "ecdsa512"
+	AlgSecp256k1  = "secp256k1"
+)
+
+// algorithmOf names the algorithm worker works by
+func algorithmOf(worker ec.Worker) (string, error) {
+	switch worker.(type) {
+	case *ed25519.Worker:
+		return AlgEd25519, nil
+	case *ed25519.WorkerPh:
+		return AlgEd25519ph, nil
+	case *ed25519.WorkerCtx:
+		return AlgEd25519ctx, nil
+	case *ecdsa.Worker256:
+		return AlgECDSA256, nil
+	case *ecdsa.Worker512:
+		return AlgECDSA512, nil
+	case *secp.Worker:
+		return AlgSecp256k1, nil
+	}
+
+	return "", ec.ErrECTypeUnsupported
+}
+
+// keyTypeOf names the algorithm whose keys pubKey is of, where the Ed25519
+// variants share the keys of Ed25519
+func keyTypeOf(pubKey ec.PublicKey) string {
+	switch k := pubKey.(type) {
+	case ed25519.PublicKey:
+		return AlgEd25519
+	case *ecdsa.PublicKey:
+		switch {
+		case elliptic.P256() == k.Curve:
+			return AlgECDSA256
+		case elliptic.P521() == k.Curve:
+			return AlgECDSA512
+		case secp.IsS256(k.Curve):
+			return AlgSecp256k1
+		}
+	}
+
+	return ""
+}
+
+// keyTypeOfAlgorithm names the algorithm whose keys the algorithm name signs
+// with
+func keyTypeOfAlgorithm(name string) string {
+	switch name {
+	case AlgEd25519ph, AlgEd25519ctx:
+		return AlgEd25519
+	}
+
+	return name
+}
+
+// rawPubKey encodes pubKey independently of the codecs, i.e. the 32 bytes of
+// an Ed25519 key or the compressed SEC1 point of an ECDSA one
+func rawPubKey(pubKey ec.PublicKey) ([]byte, error) {
+	switch k := pubKey.(type) {
+	case ed25519.PublicKey:
+		if stdEd25519.PublicKeySize != len(k) {
+			return nil, ec.ErrMalformedKey
+		}
+		return k, nil
+	case *ecdsa.PublicKey:
+		return ecdsa.MarshalSEC1(k, true)
+	}
+
+	return nil, ec.ErrECTypeUnsupported
+}
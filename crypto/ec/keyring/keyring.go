@@ -0,0 +1,360 @@
+// Package keyring implements a keyring identifying the keys of any worker by
+// stable key IDs, with their metadata and lifecycle
+package keyring
+
+// Note:
+// + the ID of a key is the SHA-256 of its algorithm and its raw public key,
+//   so it doesn't change across processes, imports nor codec versions
+// + every key keeps the worker it was added with, so the variant and the
+//   settings of the worker, e.g. the context of Ed25519ctx or the encoding of
+//   the ECDSA signatures, apply to all its signatures
+// + a key is active, verify-only or revoked, and each usage has at most one
+//   active key, which is the one signing for it
+// + rotating a usage promotes a new key to active and demotes the former one
+//   to verify-only, so the signatures made before keep verifying
+// + revoked keys neither sign nor verify
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sammy00/gravity/crypto/ec"
+)
+
+var (
+	// ErrUnknownKey indicates no key of the keyring has the ID
+	ErrUnknownKey = errors.New("keyring: unknown key")
+	// ErrDuplicateKey indicates the key is in the keyring already
+	ErrDuplicateKey = errors.New("keyring: duplicate key")
+	// ErrNoActiveKey indicates the usage has no key to sign with
+	ErrNoActiveKey = errors.New("keyring: no active key for the usage")
+	// ErrRevoked indicates the key has been revoked
+	ErrRevoked = errors.New("keyring: the key has been revoked")
+	// ErrKeyMismatch indicates the key isn't of the algorithm of the worker
+	ErrKeyMismatch = errors.New("keyring: the key doesn't fit the worker")
+)
+
+// State is the lifecycle state of a key
+type State int
+
+// the lifecycle states of the keys
+const (
+	// StateActive keys sign for their usage and verify
+	StateActive State = iota + 1
+	// StateVerifyOnly keys only verify
+	StateVerifyOnly
+	// StateRevoked keys neither sign nor verify
+	StateRevoked
+)
+
+func (s State) String() string {
+	switch s {
+	case StateActive:
+		return "active"
+	case StateVerifyOnly:
+		return "verify-only"
+	case StateRevoked:
+		return "revoked"
+	}
+
+	return "unknown"
+}
+
+// KeyID identifies a key by the hash of its algorithm and public key
+type KeyID [sha256.Size]byte
+
+// NewKeyID computes the ID of pubKey of the algorithm name, i.e. the SHA-256
+// of name || 0x00 || the raw public key
+func NewKeyID(name string, pubKey ec.PublicKey) (KeyID, error) {
+	raw, err := rawPubKey(pubKey)
+	if nil != err {
+		return KeyID{}, err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(name))
+	h.Write([]byte{0x00})
+	h.Write(raw)
+
+	var id KeyID
+	h.Sum(id[:0])
+
+	return id, nil
+}
+
+func (id KeyID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// Metadata describes a key of the keyring
+type Metadata struct {
+	ID        KeyID
+	Algorithm string
+	CreatedAt time.Time
+	Labels    map[string]string
+	Usage     string
+	State     State
+}
+
+type entry struct {
+	meta   Metadata
+	priv   ec.PrivateKey
+	pub    ec.PublicKey
+	worker ec.Worker
+}
+
+// metadata returns a copy of the metadata of e
+func (e *entry) metadata() Metadata {
+	meta := e.meta
+	meta.Labels = copyLabels(e.meta.Labels)
+
+	return meta
+}
+
+// Keyring stores keys under their IDs, and is safe for concurrent use
+type Keyring struct {
+	mu     sync.RWMutex
+	keys   map[KeyID]*entry
+	active map[string]KeyID
+}
+
+// New makes an empty keyring
+func New() *Keyring {
+	return &Keyring{keys: make(map[KeyID]*entry), active: make(map[string]KeyID)}
+}
+
+// Generate generates a key by worker, and imports it as Import
+func (kr *Keyring) Generate(rand io.Reader, worker ec.Worker, usage string, labels map[string]string) (Metadata, error) {
+	if _, err := algorithmOf(worker); nil != err {
+		return Metadata{}, err
+	}
+
+	priv, err := worker.GenerateKey(rand)
+	if nil != err {
+		return Metadata{}, err
+	}
+
+	return kr.Import(worker, priv, usage, labels)
+}
+
+// Import adds privKey signing by worker for usage, which is active if the
+// usage has no active key yet and verify-only otherwise
+func (kr *Keyring) Import(worker ec.Worker, privKey ec.PrivateKey, usage string,
+	labels map[string]string) (Metadata, error) {
+	return kr.add(worker, privKey, usage, labels, false)
+}
+
+// Rotate adds privKey signing by worker as the active key of usage, demoting
+// the former one to verify-only
+func (kr *Keyring) Rotate(worker ec.Worker, privKey ec.PrivateKey, usage string,
+	labels map[string]string) (Metadata, error) {
+	return kr.add(worker, privKey, usage, labels, true)
+}
+
+func (kr *Keyring) add(worker ec.Worker, privKey ec.PrivateKey, usage string,
+	labels map[string]string, promote bool) (Metadata, error) {
+	name, err := algorithmOf(worker)
+	if nil != err {
+		return Metadata{}, err
+	}
+	if nil == privKey {
+		return Metadata{}, ec.ErrKeyTampered
+	}
+	pub := privKey.Public()
+
+	if keyTypeOf(pub) != keyTypeOfAlgorithm(name) {
+		return Metadata{}, ErrKeyMismatch
+	}
+	id, err := NewKeyID(name, pub)
+	if nil != err {
+		return Metadata{}, err
+	}
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	if _, ok := kr.keys[id]; ok {
+		return Metadata{}, ErrDuplicateKey
+	}
+
+	e := &entry{
+		meta: Metadata{
+			ID:        id,
+			Algorithm: name,
+			CreatedAt: time.Now(),
+			Labels:    copyLabels(labels),
+			Usage:     usage,
+			State:     StateVerifyOnly,
+		},
+		priv:   privKey,
+		pub:    pub,
+		worker: worker,
+	}
+	kr.keys[id] = e
+
+	if _, ok := kr.active[usage]; promote || !ok {
+		kr.promote(e)
+	}
+
+	return e.metadata(), nil
+}
+
+// Promote makes the key id the active key of its usage, demoting the former
+// one to verify-only
+func (kr *Keyring) Promote(id KeyID) error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	e, ok := kr.keys[id]
+	if !ok {
+		return ErrUnknownKey
+	}
+	if StateRevoked == e.meta.State {
+		return ErrRevoked
+	}
+	kr.promote(e)
+
+	return nil
+}
+
+// promote must be called with the lock held
+func (kr *Keyring) promote(e *entry) {
+	if former, ok := kr.active[e.meta.Usage]; ok {
+		kr.keys[former].meta.State = StateVerifyOnly
+	}
+
+	e.meta.State = StateActive
+	kr.active[e.meta.Usage] = e.meta.ID
+}
+
+// Revoke revokes the key id, leaving its usage without active key if it was
+// the active one
+func (kr *Keyring) Revoke(id KeyID) error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	e, ok := kr.keys[id]
+	if !ok {
+		return ErrUnknownKey
+	}
+
+	if StateActive == e.meta.State {
+		delete(kr.active, e.meta.Usage)
+	}
+	e.meta.State = StateRevoked
+
+	return nil
+}
+
+// Get returns the metadata of the key id
+func (kr *Keyring) Get(id KeyID) (Metadata, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	e, ok := kr.keys[id]
+	if !ok {
+		return Metadata{}, ErrUnknownKey
+	}
+
+	return e.metadata(), nil
+}
+
+// Active returns the metadata of the active key of usage
+func (kr *Keyring) Active(usage string) (Metadata, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	id, ok := kr.active[usage]
+	if !ok {
+		return Metadata{}, ErrNoActiveKey
+	}
+
+	return kr.keys[id].metadata(), nil
+}
+
+// List returns the metadata of all the keys, the oldest first
+func (kr *Keyring) List() []Metadata {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	out := make([]Metadata, 0, len(kr.keys))
+	for _, e := range kr.keys {
+		out = append(out, e.metadata())
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].ID.String() < out[j].ID.String()
+	})
+
+	return out
+}
+
+// PublicKey returns the public key of the key id
+func (kr *Keyring) PublicKey(id KeyID) (ec.PublicKey, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	e, ok := kr.keys[id]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return e.pub, nil
+}
+
+// Sign signs digest with the active key of usage
+func (kr *Keyring) Sign(usage string, digest []byte) (*Signature, error) {
+	kr.mu.RLock()
+	id, ok := kr.active[usage]
+	var e *entry
+	if ok {
+		e = kr.keys[id]
+	}
+	kr.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrNoActiveKey
+	}
+
+	sig, err := e.worker.Sign(e.priv, digest)
+	if nil != err {
+		return nil, err
+	}
+
+	return &Signature{KeyID: id, Sig: sig}, nil
+}
+
+// Verify verifies sig of digest by the key it names, which must be active
+// or verify-only
+func (kr *Keyring) Verify(digest []byte, sig *Signature) bool {
+	if nil == sig {
+		return false
+	}
+
+	kr.mu.RLock()
+	e, ok := kr.keys[sig.KeyID]
+	usable := ok && (StateRevoked != e.meta.State)
+	kr.mu.RUnlock()
+
+	return usable && e.worker.Verify(e.pub, digest, sig.Sig)
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	if nil == labels {
+		return nil
+	}
+
+	out := make(map[string]string, len(labels))
+	for k, v := range labels {
+		out[k] = v
+	}
+
+	return out
+}
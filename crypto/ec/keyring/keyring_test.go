@@ -0,0 +1,283 @@
+package keyring_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/codec"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	"github.com/sammy00/gravity/crypto/ec/keyring"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// p1363 signs with the fixed-width encoding rather than the default DER
+var p1363 = new(ecdsa.Worker256)
+
+func init() {
+	p1363.Encoding = ecdsa.EncodingP1363
+}
+
+var workers = []struct {
+	name   string
+	worker ec.Worker
+}{
+	{keyring.AlgEd25519, new(ed25519.Worker)},
+	{keyring.AlgEd25519ph, &ed25519.WorkerPh{Context: "payments"}},
+	{keyring.AlgEd25519ctx, &ed25519.WorkerCtx{Context: "payments"}},
+	{keyring.AlgECDSA256, p1363},
+	{keyring.AlgECDSA512, new(ecdsa.Worker512)},
+	{keyring.AlgSecp256k1, secp.New()},
+}
+
+func TestKeyringSignVerify(t *testing.T) {
+	kr := keyring.New()
+	digest := sha512.Sum512([]byte("hello world"))
+
+	for _, c := range workers {
+		name := c.name
+		meta, err := kr.Generate(rand.Reader, c.worker, "usage:"+name, map[string]string{"team": "payments"})
+		if nil != err {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if (name != meta.Algorithm) || (keyring.StateActive != meta.State) || ("payments" != meta.Labels["team"]) {
+			t.Fatalf("%s: unexpected metadata %+v", name, meta)
+		}
+
+		// the ID survives a round trip of the public key through its codec
+		pub, err := kr.PublicKey(meta.ID)
+		if nil != err {
+			t.Fatal(err)
+		}
+		set, _ := codec.Lookup(name)
+		blob, err := set.PubKey.Marshal(pub)
+		if nil != err {
+			t.Fatal(err)
+		}
+		decoded, err := set.PubKey.Unmarshal(blob)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if want, err := keyring.NewKeyID(name, decoded); (nil != err) || (want != meta.ID) {
+			t.Fatalf("%s: want ID %s, got %s (%v)", name, want, meta.ID, err)
+		}
+
+		sig, err := kr.Sign("usage:"+name, digest[:])
+		if nil != err {
+			t.Fatal(err)
+		}
+		if meta.ID != sig.KeyID {
+			t.Fatalf("%s: want key ID %s, got %s", name, meta.ID, sig.KeyID)
+		}
+		// the signature is made and verifiable by the worker of the key
+		if !c.worker.Verify(pub, digest[:], sig.Sig) {
+			t.Fatalf("%s: the signature isn't one of the worker", name)
+		}
+		if (p1363 == c.worker) && (64 != len(sig.Sig)) {
+			t.Fatalf("%s: want a P1363 signature of 64 bytes, got %d", name, len(sig.Sig))
+		}
+
+		parsed, err := keyring.ParseSignature(sig.Bytes())
+		if nil != err {
+			t.Fatal(err)
+		}
+		if !kr.Verify(digest[:], parsed) {
+			t.Fatalf("%s: the verification shouldn't fail", name)
+		}
+		if kr.Verify([]byte("another digest"), parsed) {
+			t.Fatalf("%s: the signature of another digest is accepted", name)
+		}
+	}
+
+	if got := len(kr.List()); len(workers) != got {
+		t.Fatalf("want %d keys, got %d", len(workers), got)
+	}
+}
+
+func TestNewKeyIDGolden(t *testing.T) {
+	// the public key of the test 1 of RFC 8032
+	edPub, _ := hex.DecodeString("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+	// the generator of secp256k1
+	G, _ := hex.DecodeString("0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	secpPub, err := ecdsa.UnmarshalSEC1(secp.S256(), G)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name   string
+		pubKey ec.PublicKey
+		want   string
+	}{
+		{keyring.AlgEd25519, ed25519.PublicKey(edPub), "40302329e41f3cc765c446cc3902ec77056e35ec0b89ffff383ed45214d7c5b0"},
+		{keyring.AlgSecp256k1, secpPub, "b92b54f950c5f0a267aa4042ef4376741b09375b0984375daf07815b284acb55"},
+	}
+
+	for i, c := range testCases {
+		id, err := keyring.NewKeyID(c.name, c.pubKey)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if c.want != id.String() {
+			t.Fatalf("#%d: want %s, got %s", i, c.want, id)
+		}
+	}
+}
+
+func TestKeyringRotate(t *testing.T) {
+	kr := keyring.New()
+	digest := []byte("block #1")
+	worker := new(ed25519.Worker)
+
+	old, err := kr.Generate(rand.Reader, worker, "validator", nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+	oldSig, err := kr.Sign("validator", digest)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	priv, err := worker.GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	rotated, err := kr.Rotate(worker, priv, "validator", nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	if meta, _ := kr.Get(old.ID); keyring.StateVerifyOnly != meta.State {
+		t.Fatalf("want %v, got %v", keyring.StateVerifyOnly, meta.State)
+	}
+	if meta, _ := kr.Active("validator"); rotated.ID != meta.ID {
+		t.Fatalf("want active key %s, got %s", rotated.ID, meta.ID)
+	}
+
+	newSig, err := kr.Sign("validator", digest)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if rotated.ID != newSig.KeyID {
+		t.Fatalf("want key ID %s, got %s", rotated.ID, newSig.KeyID)
+	}
+
+	// the signatures of the former key keep verifying
+	if !kr.Verify(digest, oldSig) || !kr.Verify(digest, newSig) {
+		t.Fatal("the verification shouldn't fail")
+	}
+
+	// until it's revoked
+	if err := kr.Revoke(old.ID); nil != err {
+		t.Fatal(err)
+	}
+	if kr.Verify(digest, oldSig) {
+		t.Fatal("the signature of a revoked key is accepted")
+	}
+	if err := kr.Promote(old.ID); keyring.ErrRevoked != err {
+		t.Fatalf("want %v, got %v", keyring.ErrRevoked, err)
+	}
+
+	// revoking the active key leaves the usage without signer
+	if err := kr.Revoke(rotated.ID); nil != err {
+		t.Fatal(err)
+	}
+	if _, err := kr.Sign("validator", digest); keyring.ErrNoActiveKey != err {
+		t.Fatalf("want %v, got %v", keyring.ErrNoActiveKey, err)
+	}
+}
+
+func TestKeyringImport(t *testing.T) {
+	kr := keyring.New()
+
+	first, err := kr.Generate(rand.Reader, secp.New(), "tx", nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	// an imported key doesn't take over the active one
+	priv, err := new(ed25519.Worker).GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	second, err := kr.Import(new(ed25519.Worker), priv, "tx", nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if keyring.StateVerifyOnly != second.State {
+		t.Fatalf("want %v, got %v", keyring.StateVerifyOnly, second.State)
+	}
+
+	if err := kr.Promote(second.ID); nil != err {
+		t.Fatal(err)
+	}
+	if meta, _ := kr.Get(first.ID); keyring.StateVerifyOnly != meta.State {
+		t.Fatalf("want %v, got %v", keyring.StateVerifyOnly, meta.State)
+	}
+
+	if _, err := kr.Import(new(ed25519.Worker), priv, "other", nil); keyring.ErrDuplicateKey != err {
+		t.Fatalf("want %v, got %v", keyring.ErrDuplicateKey, err)
+	}
+}
+
+func TestKeyringLabelsCopied(t *testing.T) {
+	kr := keyring.New()
+
+	labels := map[string]string{"env": "prod"}
+	meta, err := kr.Generate(rand.Reader, new(ecdsa.Worker256), "tls", labels)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	labels["env"] = "dev"
+	meta.Labels["env"] = "dev"
+	if got, _ := kr.Get(meta.ID); "prod" != got.Labels["env"] {
+		t.Fatalf("want %s, got %s", "prod", got.Labels["env"])
+	}
+}
+
+func TestKeyringErrors(t *testing.T) {
+	kr := keyring.New()
+	var unknown keyring.KeyID
+
+	if _, err := kr.Generate(rand.Reader, nil, "tx", nil); ec.ErrECTypeUnsupported != err {
+		t.Fatalf("want %v, got %v", ec.ErrECTypeUnsupported, err)
+	}
+	if _, err := kr.Import(new(ed25519.Worker), nil, "tx", nil); ec.ErrKeyTampered != err {
+		t.Fatalf("want %v, got %v", ec.ErrKeyTampered, err)
+	}
+
+	priv, err := new(ecdsa.Worker256).GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	for _, worker := range []ec.Worker{new(ed25519.WorkerPh), new(ecdsa.Worker512), secp.New()} {
+		if _, err := kr.Import(worker, priv, "tx", nil); keyring.ErrKeyMismatch != err {
+			t.Fatalf("want %v, got %v", keyring.ErrKeyMismatch, err)
+		}
+	}
+
+	if _, err := kr.Get(unknown); keyring.ErrUnknownKey != err {
+		t.Fatalf("want %v, got %v", keyring.ErrUnknownKey, err)
+	}
+	if err := kr.Revoke(unknown); keyring.ErrUnknownKey != err {
+		t.Fatalf("want %v, got %v", keyring.ErrUnknownKey, err)
+	}
+	if _, err := kr.Sign("tx", nil); keyring.ErrNoActiveKey != err {
+		t.Fatalf("want %v, got %v", keyring.ErrNoActiveKey, err)
+	}
+	if kr.Verify(nil, &keyring.Signature{KeyID: unknown, Sig: make([]byte, 64)}) {
+		t.Fatal("the signature of an unknown key is accepted")
+	}
+
+	if _, err := keyring.ParseSignature(unknown[:]); ec.ErrMalformedSig != err {
+		t.Fatalf("want %v, got %v", ec.ErrMalformedSig, err)
+	}
+	if data := (&keyring.Signature{KeyID: unknown, Sig: []byte{1}}).Bytes(); !bytes.Equal(data[32:], []byte{1}) {
+		t.Fatalf("want the signature after the key ID, got %x", data)
+	}
+}
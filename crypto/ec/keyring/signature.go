@@ -0,0 +1,31 @@
+package keyring
+
+import (
+	"github.com/sammy00/gravity/crypto/ec"
+)
+
+// Signature is a signature tagged by the ID of the key making it
+type Signature struct {
+	KeyID KeyID
+	Sig   ec.Sig
+}
+
+// Bytes encodes sig as KeyID || Sig
+func (sig *Signature) Bytes() []byte {
+	out := make([]byte, 0, len(sig.KeyID)+len(sig.Sig))
+
+	return append(append(out, sig.KeyID[:]...), sig.Sig...)
+}
+
+// ParseSignature decodes the signature data encoded by Bytes
+func ParseSignature(data []byte) (*Signature, error) {
+	sig := new(Signature)
+	if len(data) <= len(sig.KeyID) {
+		return nil, ec.ErrMalformedSig
+	}
+
+	copy(sig.KeyID[:], data)
+	sig.Sig = append(ec.Sig(nil), data[len(sig.KeyID):]...)
+
+	return sig, nil
+}
@@ -0,0 +1,226 @@
+package keystore_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec/keystore"
+)
+
+// fastParams keep the derivations of the encrypted stores cheap in tests
+var fastParams = keystore.ScryptParams{LogN: 10, R: 8, P: 1}
+
+// backends makes a fresh store of every backend
+var backends = []struct {
+	name     string
+	newStore func(t *testing.T) keystore.KeyStore
+}{
+	{"mem", func(t *testing.T) keystore.KeyStore {
+		return keystore.NewMemStore()
+	}},
+	{"dir", func(t *testing.T) keystore.KeyStore {
+		return newDirStore(t)
+	}},
+	{"encrypted/mem", func(t *testing.T) keystore.KeyStore {
+		return newEncryptedStore(t, keystore.NewMemStore(), []byte("passphrase"), fastParams)
+	}},
+	{"encrypted/dir", func(t *testing.T) keystore.KeyStore {
+		return newEncryptedStore(t, newDirStore(t), []byte("passphrase"), fastParams)
+	}},
+}
+
+func newEncryptedStore(t *testing.T, inner keystore.KeyStore, passphrase []byte,
+	params keystore.ScryptParams) *keystore.EncryptedStore {
+	s, err := keystore.NewEncryptedStoreWithParams(inner, passphrase, params)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func newDirStore(t *testing.T) *keystore.DirStore {
+	s, err := keystore.NewDirStore(t.TempDir())
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestConformance(t *testing.T) {
+	suite := []struct {
+		name string
+		run  func(t *testing.T, s keystore.KeyStore)
+	}{
+		{"PutGet", testPutGet},
+		{"Overwrite", testOverwrite},
+		{"ListDelete", testListDelete},
+		{"NotFound", testNotFound},
+		{"InvalidName", testInvalidName},
+		{"Concurrent", testConcurrent},
+	}
+
+	for _, b := range backends {
+		for _, c := range suite {
+			t.Run(b.name+"/"+c.name, func(t *testing.T) {
+				c.run(t, b.newStore(t))
+			})
+		}
+	}
+}
+
+func testPutGet(t *testing.T, s keystore.KeyStore) {
+	testCases := map[string][]byte{
+		"ed25519-1":     bytes.Repeat([]byte{0xab}, 65),
+		"secp256k1.key": {0x01, 0x02, 0x03},
+		"empty_blob":    {},
+	}
+
+	for name, blob := range testCases {
+		if err := s.Put(name, blob); nil != err {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+	}
+
+	for name, want := range testCases {
+		got, err := s.Get(name)
+		if nil != err {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if !bytes.Equal(want, got) {
+			t.Fatalf("%s: want %x, got %x", name, want, got)
+		}
+
+		// the returned blob isn't the stored one
+		if 0 < len(got) {
+			got[0] ^= 0xff
+			if again, _ := s.Get(name); !bytes.Equal(want, again) {
+				t.Fatalf("%s: the store shares its blob", name)
+			}
+		}
+	}
+}
+
+func testOverwrite(t *testing.T, s keystore.KeyStore) {
+	if err := s.Put("key", []byte("old")); nil != err {
+		t.Fatal(err)
+	}
+	if err := s.Put("key", []byte("new")); nil != err {
+		t.Fatal(err)
+	}
+
+	if got, err := s.Get("key"); (nil != err) || ("new" != string(got)) {
+		t.Fatalf("want %s, got %s (%v)", "new", got, err)
+	}
+	if names, err := s.List(); (nil != err) || !reflect.DeepEqual([]string{"key"}, names) {
+		t.Fatalf("want %v, got %v (%v)", []string{"key"}, names, err)
+	}
+}
+
+func testListDelete(t *testing.T, s keystore.KeyStore) {
+	if names, err := s.List(); (nil != err) || (0 != len(names)) {
+		t.Fatalf("want no names, got %v (%v)", names, err)
+	}
+
+	for _, name := range []string{"c", "a", "b"} {
+		if err := s.Put(name, []byte(name)); nil != err {
+			t.Fatal(err)
+		}
+	}
+	if names, err := s.List(); (nil != err) || !reflect.DeepEqual([]string{"a", "b", "c"}, names) {
+		t.Fatalf("want %v, got %v (%v)", []string{"a", "b", "c"}, names, err)
+	}
+
+	if err := s.Delete("b"); nil != err {
+		t.Fatal(err)
+	}
+	if names, err := s.List(); (nil != err) || !reflect.DeepEqual([]string{"a", "c"}, names) {
+		t.Fatalf("want %v, got %v (%v)", []string{"a", "c"}, names, err)
+	}
+	if _, err := s.Get("b"); keystore.ErrNotFound != err {
+		t.Fatalf("want %v, got %v", keystore.ErrNotFound, err)
+	}
+}
+
+func testNotFound(t *testing.T, s keystore.KeyStore) {
+	if _, err := s.Get("missing"); keystore.ErrNotFound != err {
+		t.Fatalf("want %v, got %v", keystore.ErrNotFound, err)
+	}
+	if err := s.Delete("missing"); keystore.ErrNotFound != err {
+		t.Fatalf("want %v, got %v", keystore.ErrNotFound, err)
+	}
+}
+
+func testInvalidName(t *testing.T, s keystore.KeyStore) {
+	names := []string{"", ".hidden", "../escape", "a/b", `a\b`, "white space", string(make([]byte, 129))}
+
+	for i, name := range names {
+		if err := s.Put(name, nil); keystore.ErrInvalidName != err {
+			t.Fatalf("#%d: want %v, got %v", i, keystore.ErrInvalidName, err)
+		}
+		if _, err := s.Get(name); keystore.ErrInvalidName != err {
+			t.Fatalf("#%d: want %v, got %v", i, keystore.ErrInvalidName, err)
+		}
+		if err := s.Delete(name); keystore.ErrInvalidName != err {
+			t.Fatalf("#%d: want %v, got %v", i, keystore.ErrInvalidName, err)
+		}
+	}
+}
+
+func testConcurrent(t *testing.T, s keystore.KeyStore) {
+	const workers, rounds = 8, 10
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			own := fmt.Sprintf("key-%d", w)
+			for i := 0; i < rounds; i++ {
+				blob := []byte(fmt.Sprintf("%d/%d", w, i))
+
+				// every worker writes its own key and fights over a shared one
+				if err := s.Put(own, blob); nil != err {
+					errs <- err
+					return
+				}
+				if err := s.Put("shared", blob); nil != err {
+					errs <- err
+					return
+				}
+				if got, err := s.Get(own); (nil != err) || !bytes.Equal(blob, got) {
+					errs <- fmt.Errorf("%s: want %s, got %s (%v)", own, blob, got, err)
+					return
+				}
+				if _, err := s.Get("shared"); nil != err {
+					errs <- err
+					return
+				}
+				if _, err := s.List(); nil != err {
+					errs <- err
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	names, err := s.List()
+	if nil != err {
+		t.Fatal(err)
+	}
+	if workers+1 != len(names) {
+		t.Fatalf("want %d names, got %v", workers+1, names)
+	}
+}
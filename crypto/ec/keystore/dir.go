@@ -0,0 +1,185 @@
+package keystore
+
+// Note:
+// + a key is the file <name>.key of mode 0600 in a directory of mode 0700,
+//   to which NewDirStore restricts an existing directory too
+// + Put writes a temporary file and renames it over the key, so readers see
+//   either the former blob or the new one but never a partial write, then
+//   syncs the directory so the rename survives a crash
+// + the operations lock the file .lock of the directory, exclusively for the
+//   writes and shared for the reads, so several processes can share it
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	keySuffix = ".key"
+	lockName  = ".lock"
+	dirMode   = 0700
+	fileMode  = 0600
+)
+
+// DirStore keeps each key in a file of a directory
+type DirStore struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+// NewDirStore makes a store in dir, creating the directory if missing
+func NewDirStore(dir string) (*DirStore, error) {
+	if err := os.MkdirAll(dir, dirMode); nil != err {
+		return nil, err
+	}
+	// MkdirAll leaves the mode of an existing directory as is
+	if err := os.Chmod(dir, dirMode); nil != err {
+		return nil, err
+	}
+
+	return &DirStore{dir: dir}, nil
+}
+
+// Put writes blob atomically as the file of name
+func (s *DirStore) Put(name string, blob []byte) error {
+	if err := checkName(name); nil != err {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock(true)
+	if nil != err {
+		return err
+	}
+	defer unlock()
+
+	tmp, err := os.CreateTemp(s.dir, ".tmp-"+name+"-*")
+	if nil != err {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(fileMode); nil != err {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(blob); nil != err {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); nil != err {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); nil != err {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), s.path(name)); nil != err {
+		return err
+	}
+
+	return syncDir(s.dir)
+}
+
+// Get reads the file of name
+func (s *DirStore) Get(name string) ([]byte, error) {
+	if err := checkName(name); nil != err {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	unlock, err := s.lock(false)
+	if nil != err {
+		return nil, err
+	}
+	defer unlock()
+
+	blob, err := os.ReadFile(s.path(name))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+
+	return blob, err
+}
+
+// List returns the names of the key files in ascending order
+func (s *DirStore) List() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	unlock, err := s.lock(false)
+	if nil != err {
+		return nil, err
+	}
+	defer unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if nil != err {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), keySuffix)
+		if entry.Type().IsRegular() && (name != entry.Name()) && (nil == checkName(name)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// Delete removes the file of name
+func (s *DirStore) Delete(name string) error {
+	if err := checkName(name); nil != err {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock(true)
+	if nil != err {
+		return err
+	}
+	defer unlock()
+
+	if err := os.Remove(s.path(name)); os.IsNotExist(err) {
+		return ErrNotFound
+	} else if nil != err {
+		return err
+	}
+
+	return nil
+}
+
+func (s *DirStore) path(name string) string {
+	return filepath.Join(s.dir, name+keySuffix)
+}
+
+// lock locks the lock file of the directory, returning its unlock
+func (s *DirStore) lock(exclusive bool) (func(), error) {
+	f, err := os.OpenFile(filepath.Join(s.dir, lockName), os.O_RDWR|os.O_CREATE, fileMode)
+	if nil != err {
+		return nil, err
+	}
+
+	if err := lockFile(f, exclusive); nil != err {
+		f.Close()
+		return nil, err
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,101 @@
+package keystore_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec/keystore"
+)
+
+func TestDirStoreFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keys")
+	s, err := keystore.NewDirStore(dir)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	if err := s.Put("validator", []byte("blob")); nil != err {
+		t.Fatal(err)
+	}
+	if err := s.Put("validator", []byte("rotated blob")); nil != err {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "validator.key"))
+	if nil != err {
+		t.Fatal(err)
+	}
+	if ("windows" != runtime.GOOS) && (0600 != info.Mode().Perm()) {
+		t.Fatalf("want mode %o, got %o", 0600, info.Mode().Perm())
+	}
+
+	// no temporary file is left behind by the atomic writes
+	entries, err := os.ReadDir(dir)
+	if nil != err {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if ("validator.key" != entry.Name()) && (".lock" != entry.Name()) {
+			t.Fatalf("unexpected file %s", entry.Name())
+		}
+	}
+
+	// another store over the directory sees the same keys
+	other, err := keystore.NewDirStore(dir)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if got, err := other.Get("validator"); (nil != err) || ("rotated blob" != string(got)) {
+		t.Fatalf("want %s, got %s (%v)", "rotated blob", got, err)
+	}
+}
+
+func TestDirStoreRestrictsDirMode(t *testing.T) {
+	if "windows" == runtime.GOOS {
+		t.Skip("no permission bits on windows")
+	}
+
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0755); nil != err {
+		t.Fatal(err)
+	}
+
+	if _, err := keystore.NewDirStore(dir); nil != err {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(dir)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if 0700 != info.Mode().Perm() {
+		t.Fatalf("want mode %o, got %o", 0700, info.Mode().Perm())
+	}
+}
+
+func TestDirStoreListSkipsStrayFiles(t *testing.T) {
+	dir := t.TempDir()
+	s, err := keystore.NewDirStore(dir)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"notes.txt", ".tmp-a-123"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); nil != err {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub.key"), 0700); nil != err {
+		t.Fatal(err)
+	}
+
+	names, err := s.List()
+	if nil != err {
+		t.Fatal(err)
+	}
+	if 0 != len(names) {
+		t.Fatalf("want no names, got %v", names)
+	}
+}
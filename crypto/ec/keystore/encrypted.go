@@ -0,0 +1,184 @@
+package keystore
+
+// Note:
+// + an entry is version || logN || r || p || salt || nonce || sealed, the
+//   key of AES-256-GCM being derived from the passphrase and the salt of the
+//   entry by scrypt of the parameters (2^logN, r, p)
+// + the name is the additional data of the sealing, so an entry moved under
+//   another name fails to decrypt
+// + the parameters travel with every entry, so raising them later leaves
+//   the former entries readable
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	sealVersion = 1
+	saltSize    = 16
+	aesKeySize  = 32
+	// headerSize is the length of version || logN || r || p || salt
+	headerSize = 4 + saltSize
+	// maxCachedKeys bounds the cache of derived keys
+	maxCachedKeys = 64
+)
+
+// ScryptParams are the costs of the derivation of the key of an entry
+type ScryptParams struct {
+	LogN, R, P uint8
+}
+
+// DefaultScryptParams are the interactive costs recommended for scrypt,
+// i.e. N = 2^15, r = 8 and p = 1
+var DefaultScryptParams = ScryptParams{LogN: 15, R: 8, P: 1}
+
+// maxScryptParams bound the costs read from the unauthenticated header of an
+// entry, i.e. 128*r*N = 64 MiB of memory repeated at most p = 4 times, so a
+// forged entry can't exhaust the memory nor the CPU
+var maxScryptParams = ScryptParams{LogN: 16, R: 8, P: 4}
+
+// valid tells if the params are within maxScryptParams
+func (params ScryptParams) valid() bool {
+	return (params.LogN >= 1) && (params.LogN <= maxScryptParams.LogN) &&
+		(params.R >= 1) && (params.R <= maxScryptParams.R) &&
+		(params.P >= 1) && (params.P <= maxScryptParams.P)
+}
+
+// EncryptedStore seals the blobs of another store with a key derived from a
+// passphrase
+type EncryptedStore struct {
+	inner      KeyStore
+	passphrase []byte
+	params     ScryptParams
+
+	// keys caches the keys derived per header, which would be derived again
+	// at each Get otherwise
+	mu   sync.Mutex
+	keys map[string][]byte
+}
+
+// NewEncryptedStore seals the blobs stored in inner with passphrase, deriving
+// the keys by DefaultScryptParams
+func NewEncryptedStore(inner KeyStore, passphrase []byte) *EncryptedStore {
+	s, _ := NewEncryptedStoreWithParams(inner, passphrase, DefaultScryptParams)
+
+	return s
+}
+
+// NewEncryptedStoreWithParams is NewEncryptedStore with the scrypt params,
+// which can't cost more than N = 2^16, r = 8 and p = 4
+func NewEncryptedStoreWithParams(inner KeyStore, passphrase []byte, params ScryptParams) (*EncryptedStore, error) {
+	if !params.valid() {
+		return nil, ErrInvalidParams
+	}
+
+	return &EncryptedStore{
+		inner:      inner,
+		passphrase: append([]byte(nil), passphrase...),
+		params:     params,
+		keys:       make(map[string][]byte),
+	}, nil
+}
+
+// Put seals blob under a fresh salt and nonce, and stores it under name
+func (s *EncryptedStore) Put(name string, blob []byte) error {
+	if err := checkName(name); nil != err {
+		return err
+	}
+
+	header := make([]byte, headerSize)
+	header[0], header[1], header[2], header[3] = sealVersion, s.params.LogN, s.params.R, s.params.P
+	if _, err := rand.Read(header[4:]); nil != err {
+		return err
+	}
+
+	aead, err := s.aead(header)
+	if nil != err {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); nil != err {
+		return err
+	}
+
+	entry := append(header, nonce...)
+	entry = aead.Seal(entry, nonce, blob, []byte(name))
+
+	return s.inner.Put(name, entry)
+}
+
+// Get opens the entry stored under name
+func (s *EncryptedStore) Get(name string) ([]byte, error) {
+	entry, err := s.inner.Get(name)
+	if nil != err {
+		return nil, err
+	}
+
+	if (len(entry) < headerSize) || (sealVersion != entry[0]) {
+		return nil, ErrDecrypt
+	}
+	aead, err := s.aead(entry[:headerSize])
+	if nil != err {
+		return nil, ErrDecrypt
+	}
+
+	body := entry[headerSize:]
+	if len(body) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrDecrypt
+	}
+	blob, err := aead.Open(nil, body[:aead.NonceSize()], body[aead.NonceSize():], []byte(name))
+	if nil != err {
+		return nil, ErrDecrypt
+	}
+
+	return blob, nil
+}
+
+// List returns the names of the stored keys in ascending order
+func (s *EncryptedStore) List() ([]string, error) {
+	return s.inner.List()
+}
+
+// Delete removes the entry stored under name
+func (s *EncryptedStore) Delete(name string) error {
+	return s.inner.Delete(name)
+}
+
+// aead derives the key of the header, i.e. version || logN || r || p || salt
+func (s *EncryptedStore) aead(header []byte) (cipher.AEAD, error) {
+	s.mu.Lock()
+	key, ok := s.keys[string(header)]
+	s.mu.Unlock()
+
+	if !ok {
+		params := ScryptParams{LogN: header[1], R: header[2], P: header[3]}
+		if !params.valid() {
+			return nil, ErrDecrypt
+		}
+
+		var err error
+		key, err = scrypt.Key(s.passphrase, header[4:], 1<<params.LogN, int(params.R), int(params.P), aesKeySize)
+		if nil != err {
+			return nil, err
+		}
+
+		s.mu.Lock()
+		if len(s.keys) >= maxCachedKeys {
+			s.keys = make(map[string][]byte)
+		}
+		s.keys[string(header)] = key
+		s.mu.Unlock()
+	}
+
+	block, err := aes.NewCipher(key)
+	if nil != err {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,97 @@
+package keystore_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec/keystore"
+)
+
+func TestEncryptedStoreSeals(t *testing.T) {
+	inner := keystore.NewMemStore()
+	s := newEncryptedStore(t, inner, []byte("correct horse"), fastParams)
+
+	secret := []byte("marshalled private key")
+	if err := s.Put("validator", secret); nil != err {
+		t.Fatal(err)
+	}
+
+	sealed, err := inner.Get("validator")
+	if nil != err {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, secret) {
+		t.Fatal("the blob is stored in the clear")
+	}
+
+	// a fresh salt and nonce per entry
+	if err := s.Put("validator", secret); nil != err {
+		t.Fatal(err)
+	}
+	if resealed, _ := inner.Get("validator"); bytes.Equal(sealed, resealed) {
+		t.Fatal("the entry is sealed twice the same")
+	}
+
+	// another store with the passphrase reads it
+	reader := newEncryptedStore(t, inner, []byte("correct horse"), keystore.DefaultScryptParams)
+	if got, err := reader.Get("validator"); (nil != err) || !bytes.Equal(secret, got) {
+		t.Fatalf("want %s, got %s (%v)", secret, got, err)
+	}
+}
+
+func TestEncryptedStoreDecryptFails(t *testing.T) {
+	inner := keystore.NewMemStore()
+	s := newEncryptedStore(t, inner, []byte("correct horse"), fastParams)
+	if err := s.Put("a", []byte("blob of a")); nil != err {
+		t.Fatal(err)
+	}
+	sealed, _ := inner.Get("a")
+
+	wrong := newEncryptedStore(t, inner, []byte("battery staple"), fastParams)
+	if _, err := wrong.Get("a"); keystore.ErrDecrypt != err {
+		t.Fatalf("want %v, got %v", keystore.ErrDecrypt, err)
+	}
+
+	// an entry moved under another name
+	if err := inner.Put("b", sealed); nil != err {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("b"); keystore.ErrDecrypt != err {
+		t.Fatalf("want %v, got %v", keystore.ErrDecrypt, err)
+	}
+
+	testCases := [][]byte{
+		nil,
+		sealed[:10],
+		append([]byte{0x02}, sealed[1:]...),
+		append(append([]byte(nil), sealed[:len(sealed)-1]...), sealed[len(sealed)-1]^0x01),
+		// headers asking for N = 2^17, r = 9 or p = 5
+		append(append([]byte{sealed[0], 17}, sealed[2:4]...), sealed[4:]...),
+		append(append([]byte{sealed[0], sealed[1], 9}, sealed[3:4]...), sealed[4:]...),
+		append([]byte{sealed[0], sealed[1], sealed[2], 5}, sealed[4:]...),
+	}
+	for i, entry := range testCases {
+		if err := inner.Put("c", entry); nil != err {
+			t.Fatal(err)
+		}
+		if _, err := s.Get("c"); keystore.ErrDecrypt != err {
+			t.Fatalf("#%d: want %v, got %v", i, keystore.ErrDecrypt, err)
+		}
+	}
+}
+
+func TestEncryptedStoreInvalidParams(t *testing.T) {
+	testCases := []keystore.ScryptParams{
+		{},
+		{LogN: 17, R: 8, P: 1},
+		{LogN: 15, R: 16, P: 1},
+		{LogN: 15, R: 8, P: 5},
+	}
+
+	for i, params := range testCases {
+		_, err := keystore.NewEncryptedStoreWithParams(keystore.NewMemStore(), nil, params)
+		if keystore.ErrInvalidParams != err {
+			t.Fatalf("#%d: want %v, got %v", i, keystore.ErrInvalidParams, err)
+		}
+	}
+}
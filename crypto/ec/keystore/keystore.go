@@ -0,0 +1,60 @@
+// Package keystore persists the marshalled keys under names, with backends
+// in memory, in a directory and encrypted by a passphrase over any of them
+package keystore
+
+// Note:
+// + the stores keep opaque blobs, e.g. those of the marshallers of the
+//   workers, and never parse them
+// + Put overwrites the blob of a name already stored
+// + every backend is safe for concurrent use
+
+import (
+	"errors"
+)
+
+var (
+	// ErrNotFound indicates no blob is stored under the name
+	ErrNotFound = errors.New("keystore: key not found")
+	// ErrInvalidName indicates the name can't name a stored key
+	ErrInvalidName = errors.New("keystore: invalid key name")
+	// ErrDecrypt indicates the entry can't be decrypted, because of a wrong
+	// passphrase or a tampered entry
+	ErrDecrypt = errors.New("keystore: decryption failed")
+	// ErrInvalidParams indicates the scrypt params cost more than
+	// N = 2^16, r = 8 and p = 4
+	ErrInvalidParams = errors.New("keystore: invalid scrypt params")
+)
+
+// maxNameLen bounds the names, which must fit in a file name
+const maxNameLen = 128
+
+// KeyStore stores the marshalled keys under their names
+type KeyStore interface {
+	// Put stores blob under name, replacing any former one
+	Put(name string, blob []byte) error
+	// Get returns the blob stored under name
+	Get(name string) ([]byte, error)
+	// List returns the names of the stored keys in ascending order
+	List() ([]string, error)
+	// Delete removes the blob stored under name
+	Delete(name string) error
+}
+
+// checkName checks name is made of letters, digits, '.', '_' and '-' and
+// doesn't start with '.', so that it's safe as a file name
+func checkName(name string) error {
+	if (0 == len(name)) || (len(name) > maxNameLen) || ('.' == name[0]) {
+		return ErrInvalidName
+	}
+
+	for _, c := range name {
+		switch {
+		case ('a' <= c) && (c <= 'z'), ('A' <= c) && (c <= 'Z'), ('0' <= c) && (c <= '9'):
+		case ('.' == c) || ('_' == c) || ('-' == c):
+		default:
+			return ErrInvalidName
+		}
+	}
+
+	return nil
+}
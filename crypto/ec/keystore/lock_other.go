@@ -0,0 +1,22 @@
+//go:build !unix
+
+package keystore
+
+import (
+	"os"
+)
+
+// lockFile is a no-op where flock is missing, so the store is only safe
+// within a process there
+func lockFile(f *os.File, exclusive bool) error {
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
+
+// syncDir is a no-op where a directory can't be synced, e.g. on Windows
+func syncDir(dir string) error {
+	return nil
+}
@@ -0,0 +1,79 @@
+package keystore
+
+import (
+	"sort"
+	"sync"
+)
+
+// MemStore keeps the keys in memory, e.g. for tests
+type MemStore struct {
+	mu    sync.RWMutex
+	blobs map[string][]byte
+}
+
+// NewMemStore makes an empty store in memory
+func NewMemStore() *MemStore {
+	return &MemStore{blobs: make(map[string][]byte)}
+}
+
+// Put stores a copy of blob under name
+func (s *MemStore) Put(name string, blob []byte) error {
+	if err := checkName(name); nil != err {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blobs[name] = append([]byte(nil), blob...)
+
+	return nil
+}
+
+// Get returns a copy of the blob stored under name
+func (s *MemStore) Get(name string) ([]byte, error) {
+	if err := checkName(name); nil != err {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	blob, ok := s.blobs[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return append([]byte(nil), blob...), nil
+}
+
+// List returns the names of the stored keys in ascending order
+func (s *MemStore) List() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.blobs))
+	for name := range s.blobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// Delete removes the blob stored under name
+func (s *MemStore) Delete(name string) error {
+	if err := checkName(name); nil != err {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.blobs[name]; !ok {
+		return ErrNotFound
+	}
+	delete(s.blobs, name)
+
+	return nil
+}
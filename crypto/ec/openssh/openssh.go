@@ -0,0 +1,145 @@
+// Package openssh implements the OpenSSH encodings of the ecdsa and ed25519
+// keys, and the SSHSIG detached signatures made by `ssh-keygen -Y sign`
+package openssh
+
+// Note:
+// + public keys are encoded as the lines of authorized_keys
+// + private keys are encoded as openssh-key-v1 PEM blocks, optionally
+//   encrypted by aes256-ctr under a bcrypt-pbkdf derived key
+
+import (
+	stdEcdsa "crypto/ecdsa"
+	"encoding/pem"
+	"errors"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	stdEd25519 "golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	// ErrKeyUnsupported means the key isn't an ecdsa or ed25519 key
+	ErrKeyUnsupported = errors.New("openssh: unsupported key type")
+	// ErrPassphraseMissing means the private key is encrypted
+	ErrPassphraseMissing = errors.New("openssh: private key is passphrase protected")
+)
+
+// MarshalAuthorizedKey encodes pubKey as a line of authorized_keys
+func MarshalAuthorizedKey(pubKey ec.PublicKey, comment string) ([]byte, error) {
+	pub, err := ssh.NewPublicKey(pubKey)
+	if nil != err {
+		return nil, ErrKeyUnsupported
+	}
+
+	line := ssh.MarshalAuthorizedKey(pub)
+	if "" != comment {
+		// replace the trailing newline by the comment
+		line = append(line[:len(line)-1], ' ')
+		line = append(append(line, comment...), '\n')
+	}
+
+	return line, nil
+}
+
+// ParseAuthorizedKey decodes the first key in the authorized_keys content
+func ParseAuthorizedKey(in []byte) (ec.PublicKey, string, error) {
+	pub, comment, _, _, err := ssh.ParseAuthorizedKey(in)
+	if nil != err {
+		return nil, "", err
+	}
+
+	pubKey, err := FromSSHPublicKey(pub)
+	if nil != err {
+		return nil, "", err
+	}
+
+	return pubKey, comment, nil
+}
+
+// FromSSHPublicKey converts pub into the key verified by the workers
+func FromSSHPublicKey(pub ssh.PublicKey) (ec.PublicKey, error) {
+	cpub, ok := pub.(ssh.CryptoPublicKey)
+	if !ok {
+		return nil, ErrKeyUnsupported
+	}
+
+	switch key := cpub.CryptoPublicKey().(type) {
+	case *stdEcdsa.PublicKey:
+		if err := ecdsa.Validate(key); nil != err {
+			return nil, err
+		}
+		return key, nil
+	case stdEd25519.PublicKey:
+		if err := ed25519.Validate(key); nil != err {
+			return nil, err
+		}
+		return ed25519.PublicKey(key), nil
+	}
+
+	return nil, ErrKeyUnsupported
+}
+
+// MarshalPrivKey encodes privKey as an openssh-key-v1 PEM block, which is
+// encrypted when passphrase isn't empty
+func MarshalPrivKey(privKey ec.PrivateKey, comment string, passphrase []byte) ([]byte, error) {
+	var key interface{}
+	switch priv := privKey.(type) {
+	case *stdEcdsa.PrivateKey:
+		key = priv
+	case ed25519.PrivateKey:
+		if len(priv.PrivateKey) != stdEd25519.PrivateKeySize {
+			return nil, ec.ErrKeyTampered
+		}
+		key = priv.PrivateKey
+	default:
+		return nil, ErrKeyUnsupported
+	}
+
+	var (
+		block *pem.Block
+		err   error
+	)
+	if 0 == len(passphrase) {
+		block, err = ssh.MarshalPrivateKey(key, comment)
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(key, comment, passphrase)
+	}
+	if nil != err {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(block), nil
+}
+
+// UnmarshalPrivKey decodes an openssh-key-v1 PEM block, and passphrase is
+// only needed by encrypted keys
+func UnmarshalPrivKey(pemBytes, passphrase []byte) (ec.PrivateKey, error) {
+	var (
+		key interface{}
+		err error
+	)
+	if 0 == len(passphrase) {
+		key, err = ssh.ParseRawPrivateKey(pemBytes)
+		if _, ok := err.(*ssh.PassphraseMissingError); ok {
+			return nil, ErrPassphraseMissing
+		}
+	} else {
+		key, err = ssh.ParseRawPrivateKeyWithPassphrase(pemBytes, passphrase)
+	}
+	if nil != err {
+		return nil, err
+	}
+
+	switch priv := key.(type) {
+	case *stdEcdsa.PrivateKey:
+		return priv, nil
+	case *stdEd25519.PrivateKey:
+		return ed25519.PrivateKey{PrivateKey: *priv, PublicKey: priv.Public().(ed25519.PublicKey)}, nil
+	case stdEd25519.PrivateKey:
+		return ed25519.PrivateKey{PrivateKey: priv, PublicKey: priv.Public().(ed25519.PublicKey)}, nil
+	}
+
+	return nil, ErrKeyUnsupported
+}
@@ -0,0 +1,203 @@
+package openssh
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/sshagent"
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHSIG as specified by PROTOCOL.sshsig of OpenSSH
+const (
+	sigMagic   = "SSHSIG"
+	sigVersion = 1
+	armorBegin = "-----BEGIN SSH SIGNATURE-----"
+	armorEnd   = "-----END SSH SIGNATURE-----"
+	// ssh-keygen wraps the base64 lines at 70 columns
+	armorWidth = 70
+)
+
+// hash algorithms allowed in SSHSIG
+const (
+	HashSHA256 = "sha256"
+	HashSHA512 = "sha512"
+)
+
+var (
+	// ErrMalformedSig means the armored signature can't be decoded
+	ErrMalformedSig = errors.New("openssh: malformed SSH signature")
+	// ErrNamespaceMismatch means the signature was made for another namespace
+	ErrNamespaceMismatch = errors.New("openssh: mismatching signature namespace")
+	// ErrHashUnsupported means the hash algorithm isn't sha256 or sha512
+	ErrHashUnsupported = errors.New("openssh: unsupported hash algorithm")
+)
+
+// sigBlob is the wire format of the signature
+type sigBlob struct {
+	Magic     [6]byte
+	Version   uint32
+	PublicKey []byte
+	Namespace string
+	Reserved  string
+	HashAlg   string
+	Signature []byte
+}
+
+// signedData is what the key really signs
+type signedData struct {
+	Magic     [6]byte
+	Namespace string
+	Reserved  string
+	HashAlg   string
+	Hash      []byte
+}
+
+func newHash(hashAlg string) (hash.Hash, error) {
+	switch hashAlg {
+	case HashSHA256:
+		return sha256.New(), nil
+	case HashSHA512:
+		return sha512.New(), nil
+	}
+
+	return nil, ErrHashUnsupported
+}
+
+func digestOf(hashAlg string, message io.Reader) ([]byte, error) {
+	h, err := newHash(hashAlg)
+	if nil != err {
+		return nil, err
+	}
+	if _, err := io.Copy(h, message); nil != err {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
+
+func toSign(namespace, hashAlg string, digest []byte) []byte {
+	data := signedData{Namespace: namespace, HashAlg: hashAlg, Hash: digest}
+	copy(data.Magic[:], sigMagic)
+
+	return ssh.Marshal(data)
+}
+
+// Sign makes the armored SSHSIG signature over message with privKey under
+// namespace (e.g. "file" or "git"), hashing the message by sha512 as ssh-keygen does
+func Sign(privKey ec.PrivateKey, namespace string, message io.Reader) ([]byte, error) {
+	return SignWithHash(privKey, namespace, HashSHA512, message)
+}
+
+// SignWithHash works as Sign with the given hash algorithm
+func SignWithHash(privKey ec.PrivateKey, namespace, hashAlg string, message io.Reader) ([]byte, error) {
+	if "" == namespace {
+		return nil, errors.New("openssh: empty namespace")
+	}
+
+	signer, err := sshagent.NewSigner(privKey)
+	if nil != err {
+		return nil, err
+	}
+
+	digest, err := digestOf(hashAlg, message)
+	if nil != err {
+		return nil, err
+	}
+
+	sig, err := signer.Sign(rand.Reader, toSign(namespace, hashAlg, digest))
+	if nil != err {
+		return nil, err
+	}
+
+	blob := sigBlob{
+		Version:   sigVersion,
+		PublicKey: signer.PublicKey().Marshal(),
+		Namespace: namespace,
+		HashAlg:   hashAlg,
+		Signature: ssh.Marshal(sig),
+	}
+	copy(blob.Magic[:], sigMagic)
+
+	return armor(ssh.Marshal(blob)), nil
+}
+
+// Verify checks the armored SSHSIG signature over message under namespace,
+// and returns the public key of the signer, which the caller must check
+// against its allowed signers
+func Verify(armored []byte, namespace string, message io.Reader) (ec.PublicKey, error) {
+	raw, err := dearmor(armored)
+	if nil != err {
+		return nil, err
+	}
+
+	var blob sigBlob
+	if err := ssh.Unmarshal(raw, &blob); nil != err {
+		return nil, ErrMalformedSig
+	}
+	if sigMagic != string(blob.Magic[:]) || sigVersion != blob.Version {
+		return nil, ErrMalformedSig
+	}
+	if namespace != blob.Namespace {
+		return nil, ErrNamespaceMismatch
+	}
+
+	pub, err := ssh.ParsePublicKey(blob.PublicKey)
+	if nil != err {
+		return nil, err
+	}
+
+	sig := new(ssh.Signature)
+	if err := ssh.Unmarshal(blob.Signature, sig); nil != err {
+		return nil, ErrMalformedSig
+	}
+
+	digest, err := digestOf(blob.HashAlg, message)
+	if nil != err {
+		return nil, err
+	}
+
+	if err := pub.Verify(toSign(blob.Namespace, blob.HashAlg, digest), sig); nil != err {
+		return nil, err
+	}
+
+	return FromSSHPublicKey(pub)
+}
+
+func armor(raw []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(raw)
+
+	buf := new(bytes.Buffer)
+	buf.WriteString(armorBegin + "\n")
+	for len(encoded) > armorWidth {
+		buf.WriteString(encoded[:armorWidth] + "\n")
+		encoded = encoded[armorWidth:]
+	}
+	buf.WriteString(encoded + "\n")
+	buf.WriteString(armorEnd + "\n")
+
+	return buf.Bytes()
+}
+
+func dearmor(armored []byte) ([]byte, error) {
+	text := strings.TrimSpace(string(armored))
+	if !strings.HasPrefix(text, armorBegin) || !strings.HasSuffix(text, armorEnd) {
+		return nil, ErrMalformedSig
+	}
+
+	body := strings.Join(strings.Fields(text[len(armorBegin):len(text)-len(armorEnd)]), "")
+	raw, err := base64.StdEncoding.DecodeString(body)
+	if nil != err {
+		return nil, ErrMalformedSig
+	}
+
+	return raw, nil
+}
@@ -0,0 +1,76 @@
+package openssh_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	"github.com/sammy00/gravity/crypto/ec/openssh"
+)
+
+func TestSSHSIG(t *testing.T) {
+	message := []byte("Hello World")
+
+	for name, w := range workers {
+		for _, hashAlg := range []string{openssh.HashSHA256, openssh.HashSHA512} {
+			priv, err := w.GenerateKey(rand.Reader)
+			if nil != err {
+				t.Fatal(err)
+			}
+
+			armored, err := openssh.SignWithHash(priv, "file", hashAlg, bytes.NewReader(message))
+			if nil != err {
+				t.Fatalf("%s: %v", name, err)
+			}
+
+			pub, err := openssh.Verify(armored, "file", bytes.NewReader(message))
+			if nil != err {
+				t.Fatalf("%s: %v", name, err)
+			}
+			want, _ := openssh.MarshalAuthorizedKey(priv.Public(), "")
+			got, _ := openssh.MarshalAuthorizedKey(pub, "")
+			if !bytes.Equal(want, got) {
+				t.Fatalf("%s: mismatched signer", name)
+			}
+
+			if _, err := openssh.Verify(armored, "git", bytes.NewReader(message)); openssh.ErrNamespaceMismatch != err {
+				t.Fatalf("%s: want %v, got %v", name, openssh.ErrNamespaceMismatch, err)
+			}
+			if _, err := openssh.Verify(armored, "file", bytes.NewReader([]byte("Hello Gravity"))); nil == err {
+				t.Fatalf("%s: the verification should fail", name)
+			}
+		}
+	}
+}
+
+// the signature is made by `ssh-keygen -Y sign -f id_ed25519 -n file message.txt`
+func TestSSHSIGFixture(t *testing.T) {
+	message := readFile(t, "message.txt")
+
+	pub, err := openssh.Verify(readFile(t, "message.txt.sig"), "file", bytes.NewReader(message))
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	want, _, err := openssh.ParseAuthorizedKey(readFile(t, "id_ed25519.pub"))
+	if nil != err {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(want.(ed25519.PublicKey), pub.(ed25519.PublicKey)) {
+		t.Fatal("mismatched signer")
+	}
+
+	// the signature made by us equals the one by ssh-keygen, as ed25519 is deterministic
+	priv, err := openssh.UnmarshalPrivKey(readFile(t, "id_ed25519"), nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+	armored, err := openssh.Sign(priv, "file", bytes.NewReader(message))
+	if nil != err {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(readFile(t, "message.txt.sig"), armored) {
+		t.Fatalf("want %s, got %s", readFile(t, "message.txt.sig"), armored)
+	}
+}
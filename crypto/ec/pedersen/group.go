@@ -0,0 +1,316 @@
+package pedersen
+
+// Note:
+// + the points are kept in Jacobian coordinates (X/Z^2, Y/Z^3), Z being 0 for
+//   the point at infinity, which the sums of commitments and the
+//   verification equations need to handle
+// + the formulas are those of a = 0 from the Explicit-Formulas Database,
+//   i.e. dbl-2009-l and add-2007-bl
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"math/bits"
+
+	localECDSA "github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// the parameters of the curve secp256k1 of secp.New256
+var (
+	curve  = secp.S256()
+	params = curve.Params()
+	fieldP = params.P
+	order  = params.N
+)
+
+// point is a point of secp256k1 in Jacobian coordinates
+type point struct {
+	x, y, z *big.Int
+}
+
+func identity() *point {
+	return &point{new(big.Int), new(big.Int), new(big.Int)}
+}
+
+func fromAffine(x, y *big.Int) *point {
+	return &point{new(big.Int).Set(x), new(big.Int).Set(y), big.NewInt(1)}
+}
+
+// basePoint returns the generator G of the curve
+func basePoint() *point {
+	return fromAffine(params.Gx, params.Gy)
+}
+
+func (p *point) isIdentity() bool {
+	return 0 == p.z.Sign()
+}
+
+// affine converts p back to (X, Y), which is (0, 0) at infinity
+func (p *point) affine() (*big.Int, *big.Int) {
+	if p.isIdentity() {
+		return new(big.Int), new(big.Int)
+	}
+
+	zInv := new(big.Int).ModInverse(p.z, fieldP)
+	zInv2 := new(big.Int).Mul(zInv, zInv)
+	zInv2.Mod(zInv2, fieldP)
+
+	x := new(big.Int).Mul(p.x, zInv2)
+	x.Mod(x, fieldP)
+	y := zInv2.Mul(zInv2, zInv)
+	y.Mul(y, p.y).Mod(y, fieldP)
+
+	return x, y
+}
+
+func (p *point) equal(q *point) bool {
+	if p.isIdentity() || q.isIdentity() {
+		return p.isIdentity() == q.isIdentity()
+	}
+
+	// X1*Z2^2 = X2*Z1^2 and Y1*Z2^3 = Y2*Z1^3
+	z1z1 := mulMod(p.z, p.z)
+	z2z2 := mulMod(q.z, q.z)
+	if 0 != mulMod(p.x, z2z2).Cmp(mulMod(q.x, z1z1)) {
+		return false
+	}
+
+	return 0 == mulMod(p.y, mulMod(z2z2, q.z)).Cmp(mulMod(q.y, mulMod(z1z1, p.z)))
+}
+
+func (p *point) neg() *point {
+	y := new(big.Int).Sub(fieldP, p.y)
+	return &point{new(big.Int).Set(p.x), y.Mod(y, fieldP), new(big.Int).Set(p.z)}
+}
+
+func (p *point) double() *point {
+	if p.isIdentity() || (0 == p.y.Sign()) {
+		return identity()
+	}
+
+	a := mulMod(p.x, p.x)
+	b := mulMod(p.y, p.y)
+	c := mulMod(b, b)
+
+	// D = 2((X+B)^2 - A - C), E = 3A, F = E^2
+	d := new(big.Int).Add(p.x, b)
+	d = mulMod(d, d)
+	d.Sub(d, a).Sub(d, c).Lsh(d, 1).Mod(d, fieldP)
+	e := new(big.Int).Lsh(a, 1)
+	e.Add(e, a).Mod(e, fieldP)
+	f := mulMod(e, e)
+
+	x3 := new(big.Int).Lsh(d, 1)
+	x3.Sub(f, x3).Mod(x3, fieldP)
+
+	y3 := new(big.Int).Sub(d, x3)
+	y3 = mulMod(e, y3)
+	y3.Sub(y3, new(big.Int).Lsh(c, 3)).Mod(y3, fieldP)
+
+	z3 := mulMod(p.y, p.z)
+	z3.Lsh(z3, 1).Mod(z3, fieldP)
+
+	return &point{x3, y3, z3}
+}
+
+func (p *point) add(q *point) *point {
+	switch {
+	case p.isIdentity():
+		return q
+	case q.isIdentity():
+		return p
+	}
+
+	z1z1 := mulMod(p.z, p.z)
+	z2z2 := mulMod(q.z, q.z)
+	u1 := mulMod(p.x, z2z2)
+	u2 := mulMod(q.x, z1z1)
+	s1 := mulMod(mulMod(p.y, q.z), z2z2)
+	s2 := mulMod(mulMod(q.y, p.z), z1z1)
+
+	h := new(big.Int).Sub(u2, u1)
+	h.Mod(h, fieldP)
+	r := new(big.Int).Sub(s2, s1)
+	r.Lsh(r, 1).Mod(r, fieldP)
+	if 0 == h.Sign() {
+		if 0 == r.Sign() {
+			return p.double()
+		}
+		return identity()
+	}
+
+	i := new(big.Int).Lsh(h, 1)
+	i = mulMod(i, i)
+	j := mulMod(h, i)
+	v := mulMod(u1, i)
+
+	x3 := mulMod(r, r)
+	x3.Sub(x3, j).Sub(x3, new(big.Int).Lsh(v, 1)).Mod(x3, fieldP)
+
+	y3 := new(big.Int).Sub(v, x3)
+	y3 = mulMod(r, y3)
+	y3.Sub(y3, new(big.Int).Lsh(mulMod(s1, j), 1)).Mod(y3, fieldP)
+
+	z3 := new(big.Int).Add(p.z, q.z)
+	z3 = mulMod(z3, z3)
+	z3.Sub(z3, z1z1).Sub(z3, z2z2)
+	z3 = mulMod(z3, h)
+
+	return &point{x3, y3, z3}
+}
+
+// mul computes k*p
+func (p *point) mul(k *big.Int) *point {
+	return multiExp([]*big.Int{k}, []*point{p})
+}
+
+// bytes compresses p as SEC1, failing on the point at infinity
+func (p *point) bytes() ([]byte, error) {
+	x, y := p.affine()
+
+	return localECDSA.MarshalSEC1(&secp.PublicKey{Curve: curve, X: x, Y: y}, true)
+}
+
+// decodePoint decompresses the SEC1 point data
+func decodePoint(data []byte) (*point, bool) {
+	if (pointSize != len(data)) || ((0x02 != data[0]) && (0x03 != data[0])) {
+		return nil, false
+	}
+
+	pub, err := localECDSA.UnmarshalSEC1(curve, data)
+	if nil != err {
+		return nil, false
+	}
+
+	return fromAffine(pub.X, pub.Y), true
+}
+
+// hashToPoint derives a point of unknown discrete logarithm from label, by
+// hashing it with a counter until the digest is the X of a point
+func hashToPoint(label []byte) *point {
+	H, err := localECDSA.HashToPoint(curve, func(ctr int) []byte {
+		h := sha256.New()
+		h.Write([]byte(domain))
+		h.Write(label)
+		h.Write([]byte{byte(ctr >> 8), byte(ctr)})
+
+		return h.Sum(nil)
+	})
+	if nil != err {
+		// no label fails the 256 tries but with a chance of 2^-256
+		panic(err)
+	}
+
+	return fromAffine(H.X, H.Y)
+}
+
+// multiExp computes sum(scalars[i]*points[i]), by the interleaved
+// double-and-add of Straus for a few terms and the buckets of Pippenger for
+// many
+func multiExp(scalars []*big.Int, points []*point) *point {
+	if len(scalars) < 16 {
+		return straus(scalars, points)
+	}
+
+	return pippenger(scalars, points)
+}
+
+func straus(scalars []*big.Int, points []*point) *point {
+	ks := reduceAll(scalars)
+
+	bits := 0
+	for _, k := range ks {
+		if k.BitLen() > bits {
+			bits = k.BitLen()
+		}
+	}
+
+	acc := identity()
+	for b := bits - 1; b >= 0; b-- {
+		acc = acc.double()
+		for i, k := range ks {
+			if 1 == k.Bit(b) {
+				acc = acc.add(points[i])
+			}
+		}
+	}
+
+	return acc
+}
+
+func pippenger(scalars []*big.Int, points []*point) *point {
+	ks := reduceAll(scalars)
+
+	// the window grows with the number of terms
+	c := 4
+	for n := len(ks); n >= 64; n >>= 1 {
+		c++
+	}
+
+	acc := identity()
+	for w := (order.BitLen() + c - 1) / c; w >= 0; w-- {
+		for i := 0; i < c; i++ {
+			acc = acc.double()
+		}
+
+		buckets := make([]*point, 1<<uint(c))
+		for i, k := range ks {
+			digit := 0
+			for b := c - 1; b >= 0; b-- {
+				digit = digit<<1 | int(k.Bit(w*c+b))
+			}
+			if 0 == digit {
+				continue
+			}
+			if nil == buckets[digit] {
+				buckets[digit] = points[i]
+			} else {
+				buckets[digit] = buckets[digit].add(points[i])
+			}
+		}
+
+		// sum(d*bucket[d]) by running sums from the top bucket
+		running, window := identity(), identity()
+		for d := len(buckets) - 1; d > 0; d-- {
+			if nil != buckets[d] {
+				running = running.add(buckets[d])
+			}
+			window = window.add(running)
+		}
+		acc = acc.add(window)
+	}
+
+	return acc
+}
+
+func reduceAll(scalars []*big.Int) []*big.Int {
+	out := make([]*big.Int, len(scalars))
+	for i, k := range scalars {
+		out[i] = new(big.Int).Mod(k, order)
+	}
+
+	return out
+}
+
+// fieldC is 2^256 - P = 2^32 + 977, by which the bits of a product beyond
+// 2^256 fold back into its lower half, sparing the division of Mod
+var fieldC = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), fieldP)
+
+func mulMod(a, b *big.Int) *big.Int {
+	out := new(big.Int).Mul(a, b)
+	if out.Sign() < 0 {
+		return out.Mod(out, fieldP)
+	}
+
+	hi := new(big.Int)
+	for out.BitLen() > 256 {
+		hi.Rsh(out, 256)
+		out.SetBits(out.Bits()[:256/bits.UintSize]).Add(out, hi.Mul(hi, fieldC))
+	}
+	if out.Cmp(fieldP) >= 0 {
+		out.Sub(out, fieldP)
+	}
+
+	return out
+}
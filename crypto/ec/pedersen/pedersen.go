@@ -0,0 +1,167 @@
+// Package pedersen implements the Pedersen commitments over secp256k1, with
+// the Bulletproofs range proofs of committed values in [0, 2^64)
+package pedersen
+
+// Note:
+// + a commitment to v with the blinding r is C = vG + rH, G being the base
+//   point of the Koblitz curve of secp.New256 and H a second generator of
+//   unknown discrete logarithm hashed from G
+// + commitments add up homomorphically, i.e. C(v1, r1) + C(v2, r2) is
+//   C(v1 + v2, r1 + r2)
+// + the generators of the range proofs are hashed likewise, so nobody knows
+//   any relation between them
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"sync"
+)
+
+var (
+	// ErrInvalidCommitment indicates the commitment is malformed
+	ErrInvalidCommitment = errors.New("pedersen: invalid commitment")
+	// ErrInvalidBlinding indicates the blinding factor isn't a scalar
+	ErrInvalidBlinding = errors.New("pedersen: invalid blinding factor")
+	// ErrInvalidProof indicates the range proof is malformed
+	ErrInvalidProof = errors.New("pedersen: invalid range proof")
+)
+
+const (
+	domain = "gravity/pedersen"
+	// pointSize is the length of a compressed SEC1 point
+	pointSize = 33
+	// scalarSize is the length of a scalar
+	scalarSize = 32
+)
+
+var (
+	genOnce sync.Once
+	// G, H and the vectors of the range proofs
+	gens struct {
+		G, H   *point
+		Gs, Hs []*point
+	}
+)
+
+// generators derives the generators once
+func generators() {
+	genOnce.Do(func() {
+		gens.G = basePoint()
+
+		gx, _ := gens.G.bytes()
+		gens.H = hashToPoint(append([]byte("H"), gx...))
+
+		gens.Gs = make([]*point, rangeBits)
+		gens.Hs = make([]*point, rangeBits)
+		for i := 0; i < rangeBits; i++ {
+			gens.Gs[i] = hashToPoint([]byte{'G', byte(i)})
+			gens.Hs[i] = hashToPoint([]byte{'H', byte(i)})
+		}
+	})
+}
+
+// Commitment is a Pedersen commitment, where the zero Commitment and nil are
+// invalid ones, which never equal nor open to anything
+type Commitment struct {
+	p *point
+}
+
+// Commit commits to value with the blinding factor, which must be in
+// [0, N) for the order N of the curve
+func Commit(value, blinding *big.Int) (*Commitment, error) {
+	if (blinding.Sign() < 0) || (blinding.Cmp(order) >= 0) {
+		return nil, ErrInvalidBlinding
+	}
+	generators()
+
+	return &Commitment{
+		p: multiExp([]*big.Int{value, blinding}, []*point{gens.G, gens.H}),
+	}, nil
+}
+
+// NewBlinding draws a random blinding factor
+func NewBlinding() (*big.Int, error) {
+	return randomScalar()
+}
+
+// ParseCommitment decodes the compressed commitment data
+func ParseCommitment(data []byte) (*Commitment, error) {
+	p, ok := decodePoint(data)
+	if !ok {
+		return nil, ErrInvalidCommitment
+	}
+
+	return &Commitment{p: p}, nil
+}
+
+// Bytes encodes c as a compressed point, failing for the commitment at
+// infinity
+func (c *Commitment) Bytes() ([]byte, error) {
+	if !c.valid() {
+		return nil, ErrInvalidCommitment
+	}
+
+	out, err := c.p.bytes()
+	if nil != err {
+		return nil, ErrInvalidCommitment
+	}
+
+	return out, nil
+}
+
+// Add returns the commitment c + d, to the sums of their values and
+// blindings, which is invalid if c or d is
+func (c *Commitment) Add(d *Commitment) *Commitment {
+	if !c.valid() || !d.valid() {
+		return new(Commitment)
+	}
+
+	return &Commitment{p: c.p.add(d.p)}
+}
+
+// Sub returns the commitment c - d, to the differences of their values and
+// blindings, which is invalid if c or d is
+func (c *Commitment) Sub(d *Commitment) *Commitment {
+	if !c.valid() || !d.valid() {
+		return new(Commitment)
+	}
+
+	return &Commitment{p: c.p.add(d.p.neg())}
+}
+
+// Equal tells if c and d commit to the same value and blinding
+func (c *Commitment) Equal(d *Commitment) bool {
+	return c.valid() && d.valid() && c.p.equal(d.p)
+}
+
+// Open tells if c commits to value with the blinding factor
+func (c *Commitment) Open(value, blinding *big.Int) bool {
+	d, err := Commit(value, blinding)
+
+	return (nil == err) && c.Equal(d)
+}
+
+// valid tells if c holds a point
+func (c *Commitment) valid() bool {
+	return (nil != c) && (nil != c.p)
+}
+
+// Order returns the order N of the curve, modulo which the blinding factors
+// add up
+func Order() *big.Int {
+	return new(big.Int).Set(order)
+}
+
+// randomScalar draws a uniform scalar in [1, N)
+func randomScalar() (*big.Int, error) {
+	for {
+		k, err := rand.Int(rand.Reader, order)
+		if nil != err {
+			return nil, err
+		}
+		if 0 != k.Sign() {
+			return k, nil
+		}
+	}
+}
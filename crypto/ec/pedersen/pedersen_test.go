@@ -0,0 +1,125 @@
+package pedersen_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec/pedersen"
+)
+
+func TestCommitHomomorphic(t *testing.T) {
+	values := []int64{0, 1, 42, 1 << 40}
+
+	var sum *pedersen.Commitment
+	total, blindings := new(big.Int), new(big.Int)
+	for i, v := range values {
+		r, err := pedersen.NewBlinding()
+		if nil != err {
+			t.Fatal(err)
+		}
+
+		c, err := pedersen.Commit(big.NewInt(v), r)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if !c.Open(big.NewInt(v), r) {
+			t.Fatalf("#%d: the opening shouldn't fail", i)
+		}
+		if c.Open(big.NewInt(v+1), r) {
+			t.Fatalf("#%d: the commitment opens to another value", i)
+		}
+
+		if nil == sum {
+			sum = c
+		} else {
+			sum = sum.Add(c)
+		}
+		total.Add(total, big.NewInt(v))
+		blindings.Add(blindings, r)
+	}
+
+	// the blindings add up modulo the order of the curve
+	blindings.Mod(blindings, pedersen.Order())
+	if !sum.Open(total, blindings) {
+		t.Fatal("the sum doesn't open to the sum of the values")
+	}
+
+	// the balance of the inputs and outputs of a transfer
+	r, _ := pedersen.NewBlinding()
+	out, _ := pedersen.Commit(total, r)
+	if !sum.Sub(out).Open(new(big.Int), new(big.Int).Mod(new(big.Int).Sub(blindings, r), pedersen.Order())) {
+		t.Fatal("the difference doesn't commit to zero")
+	}
+}
+
+func TestCommitmentBytes(t *testing.T) {
+	r, err := pedersen.NewBlinding()
+	if nil != err {
+		t.Fatal(err)
+	}
+	c, err := pedersen.Commit(big.NewInt(7), r)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	data, err := c.Bytes()
+	if nil != err {
+		t.Fatal(err)
+	}
+	parsed, err := pedersen.ParseCommitment(data)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if !parsed.Equal(c) {
+		t.Fatal("the parsed commitment differs")
+	}
+
+	// the commitment at infinity has no encoding
+	if _, err := c.Sub(c).Bytes(); pedersen.ErrInvalidCommitment != err {
+		t.Fatalf("want %v, got %v", pedersen.ErrInvalidCommitment, err)
+	}
+
+	malformed := append([]byte{0x04}, data[1:]...)
+	if _, err := pedersen.ParseCommitment(malformed); pedersen.ErrInvalidCommitment != err {
+		t.Fatalf("want %v, got %v", pedersen.ErrInvalidCommitment, err)
+	}
+}
+
+func TestCommitmentInvalid(t *testing.T) {
+	r, err := pedersen.NewBlinding()
+	if nil != err {
+		t.Fatal(err)
+	}
+	c, err := pedersen.Commit(big.NewInt(7), r)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	for i, invalid := range []*pedersen.Commitment{nil, new(pedersen.Commitment)} {
+		if _, err := invalid.Bytes(); pedersen.ErrInvalidCommitment != err {
+			t.Fatalf("#%d: want %v, got %v", i, pedersen.ErrInvalidCommitment, err)
+		}
+		if invalid.Equal(invalid) || invalid.Equal(c) || c.Equal(invalid) {
+			t.Fatalf("#%d: an invalid commitment equals a commitment", i)
+		}
+		if invalid.Open(big.NewInt(7), r) {
+			t.Fatalf("#%d: an invalid commitment opens", i)
+		}
+		if _, err := c.Add(invalid).Bytes(); pedersen.ErrInvalidCommitment != err {
+			t.Fatalf("#%d: want %v, got %v", i, pedersen.ErrInvalidCommitment, err)
+		}
+		if _, err := invalid.Sub(c).Bytes(); pedersen.ErrInvalidCommitment != err {
+			t.Fatalf("#%d: want %v, got %v", i, pedersen.ErrInvalidCommitment, err)
+		}
+	}
+}
+
+func TestCommitInvalidBlinding(t *testing.T) {
+	testCases := []*big.Int{big.NewInt(-1), pedersen.Order()}
+
+	for i, c := range testCases {
+		if _, err := pedersen.Commit(big.NewInt(1), c); pedersen.ErrInvalidBlinding != err {
+			t.Fatalf("#%d: want %v, got %v", i, pedersen.ErrInvalidBlinding, err)
+		}
+	}
+}
@@ -0,0 +1,433 @@
+package pedersen
+
+// Note:
+// + the range proof follows the section 4.2 of the Bulletproofs paper, the
+//   inner product argument being compressed as its section 3.1 into
+//   log2(64) = 6 rounds
+// + the verifier checks the whole proof as one multi-exponentiation, the
+//   rounds of the inner product argument being unrolled as the section 6.2,
+//   so a batch of proofs merges into a single one under random weights
+// + the prover likewise never folds the generators, but keeps their
+//   coefficients over Gs and Hs, so each round is two multi-exponentiations
+
+import (
+	"math/big"
+)
+
+const (
+	// rangeBits is the bit length of the proven values
+	rangeBits = 64
+	// rounds is the number of rounds of the inner product argument
+	rounds = 6
+
+	// RangeProofSize is the length of an encoded range proof, i.e. A, S, T1,
+	// T2, taux, mu, t, the rounds L || R, a and b
+	RangeProofSize = 4*pointSize + 3*scalarSize + 2*rounds*pointSize + 2*scalarSize
+)
+
+// RangeProof proves a commitment is to a value in [0, 2^64)
+type RangeProof struct {
+	vecA, vecS, polyT1, polyT2 *point
+	taux, mu, t                *big.Int
+	left, right                []*point
+	a, b                       *big.Int
+}
+
+// ProveRange commits to value with the blinding factor, proving the
+// commitment is to a value in [0, 2^64)
+func ProveRange(value uint64, blinding *big.Int) (*RangeProof, *Commitment, error) {
+	V, err := Commit(new(big.Int).SetUint64(value), blinding)
+	if nil != err {
+		return nil, nil, err
+	}
+
+	ts := newTranscript()
+	if err := ts.appendPoints("V", V.p); nil != err {
+		return nil, nil, ErrInvalidCommitment
+	}
+
+	// aL are the bits of value and aR = aL - 1
+	aL, aR := make([]*big.Int, rangeBits), make([]*big.Int, rangeBits)
+	for i := range aL {
+		bit := int64(value >> uint(i) & 1)
+		aL[i] = big.NewInt(bit)
+		aR[i] = modN(big.NewInt(bit - 1))
+	}
+
+	randoms, err := randomScalars(2*rangeBits + 4)
+	if nil != err {
+		return nil, nil, err
+	}
+	alpha, rho, tau1, tau2 := randoms[0], randoms[1], randoms[2], randoms[3]
+	sL, sR := randoms[4:4+rangeBits], randoms[4+rangeBits:]
+
+	// A = alpha*H + <aL, Gs> + <aR, Hs> and S = rho*H + <sL, Gs> + <sR, Hs>
+	proof := &RangeProof{
+		vecA: vectorCommit(alpha, aL, aR),
+		vecS: vectorCommit(rho, sL, sR),
+	}
+	if err := ts.appendPoints("AS", proof.vecA, proof.vecS); nil != err {
+		return nil, nil, err
+	}
+	y, z := ts.challenge("y"), ts.challenge("z")
+	z2 := mulN(z, z)
+
+	// l(X) = aL - z + sL*X and r(X) = y^n o (aR + z + sR*X) + z^2*2^n
+	yn, twon := powers(y, rangeBits), powers(big.NewInt(2), rangeBits)
+	l0, l1 := make([]*big.Int, rangeBits), sL
+	r0, r1 := make([]*big.Int, rangeBits), make([]*big.Int, rangeBits)
+	for i := range l0 {
+		l0[i] = modN(new(big.Int).Sub(aL[i], z))
+		r0[i] = modN(new(big.Int).Add(mulN(yn[i], new(big.Int).Add(aR[i], z)), mulN(z2, twon[i])))
+		r1[i] = mulN(yn[i], sR[i])
+	}
+
+	// t(X) = <l(X), r(X)> = t0 + t1*X + t2*X^2
+	t1 := modN(new(big.Int).Add(innerProduct(l0, r1), innerProduct(l1, r0)))
+	t2 := innerProduct(l1, r1)
+
+	generators()
+	proof.polyT1 = multiExp([]*big.Int{t1, tau1}, []*point{gens.G, gens.H})
+	proof.polyT2 = multiExp([]*big.Int{t2, tau2}, []*point{gens.G, gens.H})
+	if err := ts.appendPoints("T", proof.polyT1, proof.polyT2); nil != err {
+		return nil, nil, err
+	}
+	x := ts.challenge("x")
+
+	l, r := make([]*big.Int, rangeBits), make([]*big.Int, rangeBits)
+	for i := range l {
+		l[i] = modN(new(big.Int).Add(l0[i], mulN(l1[i], x)))
+		r[i] = modN(new(big.Int).Add(r0[i], mulN(r1[i], x)))
+	}
+	proof.t = innerProduct(l, r)
+	proof.taux = modN(new(big.Int).Add(mulN(tau2, mulN(x, x)), new(big.Int).Add(mulN(tau1, x), mulN(z2, blinding))))
+	proof.mu = modN(new(big.Int).Add(alpha, mulN(rho, x)))
+
+	ts.appendScalars("tx", proof.taux, proof.mu, proof.t)
+	Q := gens.G.mul(ts.challenge("w"))
+
+	// the inner product argument of <l, r> = t over Gs and Hs' = y^-n o Hs,
+	// the folded generators being the coefficients of Gs and Hs, i.e. the
+	// s-vector of BatchVerify
+	gCoeff, hCoeff := powers(big.NewInt(1), rangeBits), powers(new(big.Int).ModInverse(y, order), rangeBits)
+
+	a, b := l, r
+	for m := rangeBits / 2; m > 0; m /= 2 {
+		cL, cR := innerProduct(a[:m], b[m:]), innerProduct(a[m:], b[:m])
+
+		// the generator i of the round sums up those j = i mod 2m of Gs or Hs
+		lScalars, rScalars := []*big.Int{cL}, []*big.Int{cR}
+		lPoints, rPoints := []*point{Q}, []*point{Q}
+		for j := 0; j < rangeBits; j++ {
+			if i := j % (2 * m); i < m {
+				lScalars = append(lScalars, mulN(b[m+i], hCoeff[j]))
+				lPoints = append(lPoints, gens.Hs[j])
+				rScalars = append(rScalars, mulN(a[m+i], gCoeff[j]))
+				rPoints = append(rPoints, gens.Gs[j])
+			} else {
+				lScalars = append(lScalars, mulN(a[i-m], gCoeff[j]))
+				lPoints = append(lPoints, gens.Gs[j])
+				rScalars = append(rScalars, mulN(b[i-m], hCoeff[j]))
+				rPoints = append(rPoints, gens.Hs[j])
+			}
+		}
+		L, R := multiExp(lScalars, lPoints), multiExp(rScalars, rPoints)
+		proof.left, proof.right = append(proof.left, L), append(proof.right, R)
+		if err := ts.appendPoints("LR", L, R); nil != err {
+			return nil, nil, err
+		}
+
+		u := ts.challenge("u")
+		uInv := new(big.Int).ModInverse(u, order)
+		for i := 0; i < m; i++ {
+			a[i] = modN(new(big.Int).Add(mulN(a[i], u), mulN(a[m+i], uInv)))
+			b[i] = modN(new(big.Int).Add(mulN(b[i], uInv), mulN(b[m+i], u)))
+		}
+		for j := 0; j < rangeBits; j++ {
+			if j%(2*m) < m {
+				gCoeff[j], hCoeff[j] = mulN(gCoeff[j], uInv), mulN(hCoeff[j], u)
+			} else {
+				gCoeff[j], hCoeff[j] = mulN(gCoeff[j], u), mulN(hCoeff[j], uInv)
+			}
+		}
+		a, b = a[:m], b[:m]
+	}
+	proof.a, proof.b = a[0], b[0]
+
+	return proof, V, nil
+}
+
+// ParseRangeProof decodes the range proof data
+func ParseRangeProof(data []byte) (*RangeProof, error) {
+	if RangeProofSize != len(data) {
+		return nil, ErrInvalidProof
+	}
+
+	var ok = true
+	nextPoint := func() *point {
+		p, valid := decodePoint(data[:pointSize])
+		ok, data = ok && valid, data[pointSize:]
+		return p
+	}
+	nextScalar := func() *big.Int {
+		k := new(big.Int).SetBytes(data[:scalarSize])
+		ok, data = ok && (k.Cmp(order) < 0), data[scalarSize:]
+		return k
+	}
+
+	proof := &RangeProof{
+		vecA: nextPoint(), vecS: nextPoint(), polyT1: nextPoint(), polyT2: nextPoint(),
+		taux: nextScalar(), mu: nextScalar(), t: nextScalar(),
+	}
+	for i := 0; i < rounds; i++ {
+		proof.left = append(proof.left, nextPoint())
+		proof.right = append(proof.right, nextPoint())
+	}
+	proof.a, proof.b = nextScalar(), nextScalar()
+
+	if !ok {
+		return nil, ErrInvalidProof
+	}
+
+	return proof, nil
+}
+
+// Bytes encodes the proof as A || S || T1 || T2 || taux || mu || t ||
+// L1 || R1 || ... || L6 || R6 || a || b
+func (proof *RangeProof) Bytes() ([]byte, error) {
+	if !proof.wellFormed() {
+		return nil, ErrInvalidProof
+	}
+
+	out := make([]byte, 0, RangeProofSize)
+	points := []*point{proof.vecA, proof.vecS, proof.polyT1, proof.polyT2}
+	for _, p := range points {
+		data, err := p.bytes()
+		if nil != err {
+			return nil, ErrInvalidProof
+		}
+		out = append(out, data...)
+	}
+	for _, k := range []*big.Int{proof.taux, proof.mu, proof.t} {
+		out = append(out, k.FillBytes(make([]byte, scalarSize))...)
+	}
+	for i := range proof.left {
+		for _, p := range []*point{proof.left[i], proof.right[i]} {
+			data, err := p.bytes()
+			if nil != err {
+				return nil, ErrInvalidProof
+			}
+			out = append(out, data...)
+		}
+	}
+	for _, k := range []*big.Int{proof.a, proof.b} {
+		out = append(out, k.FillBytes(make([]byte, scalarSize))...)
+	}
+
+	return out, nil
+}
+
+// Verify checks the proof that the commitment c is to a value in [0, 2^64)
+func (proof *RangeProof) Verify(c *Commitment) bool {
+	return BatchVerify([]*Commitment{c}, []*RangeProof{proof})
+}
+
+// BatchVerify checks the proofs that each commitment is to a value in
+// [0, 2^64), which costs much less than checking them one by one
+func BatchVerify(commitments []*Commitment, proofs []*RangeProof) bool {
+	if (0 == len(proofs)) || (len(commitments) != len(proofs)) {
+		return false
+	}
+	generators()
+
+	// the coefficients of G, H, Gs and Hs shared by all the proofs
+	gCoeff, hCoeff := new(big.Int), new(big.Int)
+	gsCoeff, hsCoeff := zeros(rangeBits), zeros(rangeBits)
+
+	var scalars []*big.Int
+	var points []*point
+	for j, proof := range proofs {
+		if !commitments[j].valid() || (nil == proof) || !proof.wellFormed() {
+			return false
+		}
+
+		ts := newTranscript()
+		if err := ts.appendPoints("V", commitments[j].p); nil != err {
+			return false
+		}
+		if err := ts.appendPoints("AS", proof.vecA, proof.vecS); nil != err {
+			return false
+		}
+		y, z := ts.challenge("y"), ts.challenge("z")
+		if err := ts.appendPoints("T", proof.polyT1, proof.polyT2); nil != err {
+			return false
+		}
+		x := ts.challenge("x")
+		ts.appendScalars("tx", proof.taux, proof.mu, proof.t)
+		w := ts.challenge("w")
+
+		u, uInv := make([]*big.Int, rounds), make([]*big.Int, rounds)
+		for k := range u {
+			if err := ts.appendPoints("LR", proof.left[k], proof.right[k]); nil != err {
+				return false
+			}
+			u[k] = ts.challenge("u")
+			uInv[k] = new(big.Int).ModInverse(u[k], order)
+		}
+
+		// the weight of this proof, and that of its check of t
+		weights, err := randomScalars(2)
+		if nil != err {
+			return false
+		}
+		weight, c := weights[0], weights[1]
+		cw := mulN(c, weight)
+
+		z2 := mulN(z, z)
+		yInv := new(big.Int).ModInverse(y, order)
+		yn, yInvN, twon := powers(y, rangeBits), powers(yInv, rangeBits), powers(big.NewInt(2), rangeBits)
+
+		// delta(y, z) = (z - z^2)*<1, y^n> - z^3*<1, 2^n>
+		delta := mulN(new(big.Int).Sub(z, z2), sum(yn))
+		delta.Sub(delta, mulN(mulN(z2, z), sum(twon)))
+
+		// the check of t: t*G + taux*H = z^2*V + delta*G + x*T1 + x^2*T2
+		gCoeff.Add(gCoeff, mulN(cw, new(big.Int).Sub(proof.t, delta)))
+		hCoeff.Add(hCoeff, mulN(cw, proof.taux))
+		scalars = append(scalars, new(big.Int).Neg(mulN(cw, z2)), new(big.Int).Neg(mulN(cw, x)), new(big.Int).Neg(mulN(cw, mulN(x, x))))
+		points = append(points, commitments[j].p, proof.polyT1, proof.polyT2)
+
+		// the inner product argument: A + x*S - z*<1, Gs> + <z + z^2*2^n o y^-n, Hs>
+		// - mu*H + t*Q + sum(u^2*L + u^-2*R) = a*<s, Gs> + b*<s^-1 o y^-n, Hs> + ab*Q
+		ab := mulN(proof.a, proof.b)
+		gCoeff.Add(gCoeff, mulN(weight, mulN(w, new(big.Int).Sub(proof.t, ab))))
+		hCoeff.Sub(hCoeff, mulN(weight, proof.mu))
+		scalars = append(scalars, weight, mulN(weight, x))
+		points = append(points, proof.vecA, proof.vecS)
+		for k := range u {
+			scalars = append(scalars, mulN(weight, mulN(u[k], u[k])), mulN(weight, mulN(uInv[k], uInv[k])))
+			points = append(points, proof.left[k], proof.right[k])
+		}
+
+		for i := 0; i < rangeBits; i++ {
+			// s_i takes u_k for the bit of round k set in i, else u_k^-1
+			s, sInv := big.NewInt(1), big.NewInt(1)
+			for k := 0; k < rounds; k++ {
+				if 1 == (i>>uint(rounds-1-k))&1 {
+					s, sInv = mulN(s, u[k]), mulN(sInv, uInv[k])
+				} else {
+					s, sInv = mulN(s, uInv[k]), mulN(sInv, u[k])
+				}
+			}
+
+			g := new(big.Int).Add(z, mulN(proof.a, s))
+			gsCoeff[i].Sub(gsCoeff[i], mulN(weight, g))
+
+			h := new(big.Int).Sub(mulN(z2, twon[i]), mulN(proof.b, sInv))
+			h = new(big.Int).Add(z, mulN(h, yInvN[i]))
+			hsCoeff[i].Add(hsCoeff[i], mulN(weight, h))
+		}
+	}
+
+	scalars = append(scalars, gCoeff, hCoeff)
+	points = append(points, gens.G, gens.H)
+	scalars = append(append(scalars, gsCoeff...), hsCoeff...)
+	points = append(append(points, gens.Gs...), gens.Hs...)
+
+	return multiExp(scalars, points).isIdentity()
+}
+
+// wellFormed tells if the proof has all its pieces
+func (proof *RangeProof) wellFormed() bool {
+	for _, p := range []*point{proof.vecA, proof.vecS, proof.polyT1, proof.polyT2} {
+		if nil == p {
+			return false
+		}
+	}
+	for _, k := range []*big.Int{proof.taux, proof.mu, proof.t, proof.a, proof.b} {
+		if nil == k {
+			return false
+		}
+	}
+	if (rounds != len(proof.left)) || (rounds != len(proof.right)) {
+		return false
+	}
+	for i := range proof.left {
+		if (nil == proof.left[i]) || (nil == proof.right[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// vectorCommit computes blinding*H + <l, Gs> + <r, Hs>
+func vectorCommit(blinding *big.Int, l, r []*big.Int) *point {
+	generators()
+
+	scalars := append(append([]*big.Int{blinding}, l...), r...)
+	points := append(append([]*point{gens.H}, gens.Gs...), gens.Hs...)
+
+	return multiExp(scalars, points)
+}
+
+func randomScalars(n int) ([]*big.Int, error) {
+	out := make([]*big.Int, n)
+	for i := range out {
+		k, err := randomScalar()
+		if nil != err {
+			return nil, err
+		}
+		out[i] = k
+	}
+
+	return out, nil
+}
+
+// powers computes 1, k, ..., k^(n-1)
+func powers(k *big.Int, n int) []*big.Int {
+	out := make([]*big.Int, n)
+	out[0] = big.NewInt(1)
+	for i := 1; i < n; i++ {
+		out[i] = mulN(out[i-1], k)
+	}
+
+	return out
+}
+
+func innerProduct(a, b []*big.Int) *big.Int {
+	out := new(big.Int)
+	for i := range a {
+		out.Add(out, new(big.Int).Mul(a[i], b[i]))
+	}
+
+	return out.Mod(out, order)
+}
+
+func sum(ks []*big.Int) *big.Int {
+	out := new(big.Int)
+	for _, k := range ks {
+		out.Add(out, k)
+	}
+
+	return out.Mod(out, order)
+}
+
+func zeros(n int) []*big.Int {
+	out := make([]*big.Int, n)
+	for i := range out {
+		out[i] = new(big.Int)
+	}
+
+	return out
+}
+
+func mulN(a, b *big.Int) *big.Int {
+	out := new(big.Int).Mul(a, b)
+
+	return out.Mod(out, order)
+}
+
+func modN(k *big.Int) *big.Int {
+	return k.Mod(k, order)
+}
@@ -0,0 +1,123 @@
+package pedersen_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec/pedersen"
+)
+
+func proveRange(t *testing.T, value uint64) (*pedersen.RangeProof, *pedersen.Commitment) {
+	r, err := pedersen.NewBlinding()
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	proof, c, err := pedersen.ProveRange(value, r)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if !c.Open(new(big.Int).SetUint64(value), r) {
+		t.Fatal("the commitment doesn't open to the value")
+	}
+
+	return proof, c
+}
+
+func TestRangeProof(t *testing.T) {
+	values := []uint64{0, 1, 1 << 32, math.MaxUint64}
+
+	for i, v := range values {
+		proof, c := proveRange(t, v)
+
+		data, err := proof.Bytes()
+		if nil != err {
+			t.Fatal(err)
+		}
+		if pedersen.RangeProofSize != len(data) {
+			t.Fatalf("#%d: want %d bytes, got %d", i, pedersen.RangeProofSize, len(data))
+		}
+
+		parsed, err := pedersen.ParseRangeProof(data)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if !parsed.Verify(c) {
+			t.Fatalf("#%d: the verification shouldn't fail", i)
+		}
+
+		// the proof is bound to its commitment
+		other, _ := proveRange(t, v)
+		if other.Verify(c) {
+			t.Fatalf("#%d: the proof of another commitment is accepted", i)
+		}
+		if parsed.Verify(nil) || parsed.Verify(new(pedersen.Commitment)) {
+			t.Fatalf("#%d: the proof of an invalid commitment is accepted", i)
+		}
+	}
+}
+
+func TestRangeProofTampered(t *testing.T) {
+	proof, c := proveRange(t, 1000)
+	data, err := proof.Bytes()
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	// a byte of taux, mu, t, a and b
+	for _, offset := range []int{4*33 + 31, 4*33 + 63, 4*33 + 95, len(data) - 33, len(data) - 1} {
+		tampered := append([]byte(nil), data...)
+		tampered[offset] ^= 0x01
+
+		p, err := pedersen.ParseRangeProof(tampered)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if p.Verify(c) {
+			t.Fatalf("the proof tampered at %d is accepted", offset)
+		}
+	}
+
+	// the commitment shifted by one doesn't commit to the proven value
+	one, _ := pedersen.Commit(big.NewInt(1), new(big.Int))
+	if proof.Verify(c.Add(one)) {
+		t.Fatal("the proof of a shifted commitment is accepted")
+	}
+
+	if _, err := pedersen.ParseRangeProof(data[1:]); pedersen.ErrInvalidProof != err {
+		t.Fatalf("want %v, got %v", pedersen.ErrInvalidProof, err)
+	}
+	if _, err := new(pedersen.RangeProof).Bytes(); pedersen.ErrInvalidProof != err {
+		t.Fatalf("want %v, got %v", pedersen.ErrInvalidProof, err)
+	}
+	if new(pedersen.RangeProof).Verify(c) {
+		t.Fatal("the empty proof is accepted")
+	}
+}
+
+func TestBatchVerify(t *testing.T) {
+	var proofs []*pedersen.RangeProof
+	var commitments []*pedersen.Commitment
+	for _, v := range []uint64{3, 1 << 20, 1 << 63} {
+		proof, c := proveRange(t, v)
+		proofs, commitments = append(proofs, proof), append(commitments, c)
+	}
+
+	if !pedersen.BatchVerify(commitments, proofs) {
+		t.Fatal("the verification shouldn't fail")
+	}
+
+	// a single swapped commitment fails the whole batch
+	swapped := []*pedersen.Commitment{commitments[1], commitments[0], commitments[2]}
+	if pedersen.BatchVerify(swapped, proofs) {
+		t.Fatal("the batch of swapped commitments is accepted")
+	}
+
+	if pedersen.BatchVerify(commitments[:2], proofs) {
+		t.Fatal("the batch of mismatched lengths is accepted")
+	}
+	if pedersen.BatchVerify(nil, nil) {
+		t.Fatal("the empty batch is accepted")
+	}
+}
@@ -0,0 +1,57 @@
+package pedersen
+
+import (
+	"math/big"
+
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// transcript derives the Fiat-Shamir challenges of a range proof from all
+// the messages before them, chaining tagged hashes
+type transcript struct {
+	state []byte
+}
+
+func newTranscript() *transcript {
+	return &transcript{state: secp.TaggedHash(domain+"/rangeproof", []byte{rangeBits})}
+}
+
+// appendPoints absorbs the points labelled by label
+func (t *transcript) appendPoints(label string, points ...*point) error {
+	msg := make([][]byte, 0, 2+len(points))
+	msg = append(msg, t.state, []byte(label))
+	for _, p := range points {
+		data, err := p.bytes()
+		if nil != err {
+			return err
+		}
+		msg = append(msg, data)
+	}
+
+	t.state = secp.TaggedHash(domain+"/transcript", msg...)
+
+	return nil
+}
+
+// appendScalars absorbs the scalars labelled by label
+func (t *transcript) appendScalars(label string, scalars ...*big.Int) {
+	msg := make([][]byte, 0, 2+len(scalars))
+	msg = append(msg, t.state, []byte(label))
+	for _, k := range scalars {
+		msg = append(msg, k.FillBytes(make([]byte, scalarSize)))
+	}
+
+	t.state = secp.TaggedHash(domain+"/transcript", msg...)
+}
+
+// challenge draws the non-zero challenge labelled by label
+func (t *transcript) challenge(label string) *big.Int {
+	for {
+		t.state = secp.TaggedHash(domain+"/challenge", t.state, []byte(label))
+
+		c := new(big.Int).SetBytes(t.state)
+		if c.Mod(c, order); 0 != c.Sign() {
+			return c
+		}
+	}
+}
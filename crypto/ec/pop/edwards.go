@@ -0,0 +1,71 @@
+package pop
+
+import (
+	"bytes"
+	"crypto/rand"
+
+	"filippo.io/edwards25519"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+)
+
+const (
+	edwardsName = "Ed25519"
+	// edwardsProofSize is the length of R || s
+	edwardsProofSize = 64
+)
+
+func proveEdwards(priv ed25519.PrivateKey, context []byte) ([]byte, error) {
+	x, err := ed25519.Scalar(priv)
+	if nil != err {
+		return nil, err
+	}
+	pub := priv.PrivateKey[32:]
+
+	var buf [64]byte
+	if _, err := rand.Read(buf[:]); nil != err {
+		return nil, err
+	}
+	k, err := edwards25519.NewScalar().SetUniformBytes(buf[:])
+	if nil != err {
+		return nil, err
+	}
+
+	R := new(edwards25519.Point).ScalarBaseMult(k).Bytes()
+	c, err := edwards25519.NewScalar().SetUniformBytes(challenge(edwardsName, pub, context, R))
+	if nil != err {
+		return nil, err
+	}
+
+	return append(R, k.MultiplyAdd(c, x, k).Bytes()...), nil
+}
+
+func verifyEdwards(pub ed25519.PublicKey, context, proof []byte) bool {
+	if (edwardsProofSize != len(proof)) || (nil != ed25519.Validate(pub)) {
+		return false
+	}
+
+	// a key of mixed order would let a proof pass for one challenge in 8
+	A, ok := ed25519.DecodePoint(pub)
+	if !ok || !ed25519.IsTorsionFree(A) {
+		return false
+	}
+
+	R := proof[:32]
+	if _, ok := ed25519.DecodePoint(R); !ok {
+		return false
+	}
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(proof[32:])
+	if nil != err {
+		return false
+	}
+	c, err := edwards25519.NewScalar().SetUniformBytes(challenge(edwardsName, pub, context, R))
+	if nil != err {
+		return false
+	}
+
+	// R = sG - cA
+	negC := edwards25519.NewScalar().Negate(c)
+	want := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(negC, A, s)
+
+	return bytes.Equal(want.Bytes(), R)
+}
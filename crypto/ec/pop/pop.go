@@ -0,0 +1,69 @@
+// Package pop implements the proofs of possession of secret keys, i.e. the
+// non-interactive Schnorr proofs of knowledge of the discrete logarithm of a
+// public key, which keep rogue and copied keys out of a key registry
+package pop
+
+// Note:
+// + a proof is R || s with R = kG, c = H(transcript) and s = k + c*x, and
+//   is accepted iff sG = R + cX
+// + the transcript binds a domain tag, the curve, the public key, the context
+//   (i.e. the identity of the registrant) and R, each prefixed by its length
+//   so no two transcripts collide
+// + binding the identity keeps a proof copied from another registrant from
+//   registering its key under a new identity
+// + the supported keys are those of ed25519.Worker, ecdsa.Worker256,
+//   ecdsa.Worker512 and secp.Worker, i.e. Ed25519, P-256, P-521 and
+//   secp256k1
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	localECDSA "github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+)
+
+// domain separates the transcripts of the proofs from any other hash
+const domain = "gravity/pop/v1"
+
+// Prove proves the possession of privKey, which is an ed25519.PrivateKey or
+// an ecdsa.PrivateKey of P-256, P-521 or secp256k1, for the context
+// identifying the registrant
+func Prove(privKey ec.PrivateKey, context []byte) ([]byte, error) {
+	switch priv := privKey.(type) {
+	case ed25519.PrivateKey:
+		return proveEdwards(priv, context)
+	case *localECDSA.PrivateKey:
+		return proveWeierstrass(priv, context)
+	}
+
+	return nil, ec.ErrKeyTampered
+}
+
+// VerifyProof checks proof proves the possession of the secret of pubKey
+// for the context identifying the registrant
+func VerifyProof(pubKey ec.PublicKey, context, proof []byte) bool {
+	switch pub := pubKey.(type) {
+	case ed25519.PublicKey:
+		return verifyEdwards(pub, context, proof)
+	case *localECDSA.PublicKey:
+		return verifyWeierstrass(pub, context, proof)
+	}
+
+	return false
+}
+
+// challenge hashes the transcript of a proof with SHA-512
+func challenge(curveName string, pub, context, R []byte) []byte {
+	h := sha512.New()
+	for _, field := range [][]byte{[]byte(domain), []byte(curveName), pub, context, R} {
+		var n [8]byte
+		binary.BigEndian.PutUint64(n[:], uint64(len(field)))
+
+		h.Write(n[:])
+		h.Write(field)
+	}
+
+	return h.Sum(nil)
+}
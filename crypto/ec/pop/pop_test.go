@@ -0,0 +1,109 @@
+package pop_test
+
+import (
+	stdEcdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	localECDSA "github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	"github.com/sammy00/gravity/crypto/ec/pop"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+var workers = []struct {
+	name   string
+	worker ec.Worker
+}{
+	{"Ed25519", new(ed25519.Worker)},
+	{"P-256", new(localECDSA.Worker256)},
+	{"P-521", new(localECDSA.Worker512)},
+	{"secp256k1", secp.New()},
+}
+
+func TestProveVerify(t *testing.T) {
+	context := []byte("validator:alice")
+
+	for _, c := range workers {
+		priv, err := c.worker.GenerateKey(rand.Reader)
+		if nil != err {
+			t.Fatal(err)
+		}
+		other, err := c.worker.GenerateKey(rand.Reader)
+		if nil != err {
+			t.Fatal(err)
+		}
+
+		proof, err := pop.Prove(priv, context)
+		if nil != err {
+			t.Fatalf("%s: unexpected error: %v", c.name, err)
+		}
+		if !pop.VerifyProof(priv.Public(), context, proof) {
+			t.Fatalf("%s: the verification shouldn't fail", c.name)
+		}
+
+		// a copied key and proof can't be registered under another identity
+		if pop.VerifyProof(priv.Public(), []byte("validator:mallory"), proof) {
+			t.Fatalf("%s: the proof is accepted for another identity", c.name)
+		}
+		if pop.VerifyProof(other.Public(), context, proof) {
+			t.Fatalf("%s: the proof is accepted for another key", c.name)
+		}
+
+		for i := range proof {
+			tampered := append([]byte(nil), proof...)
+			tampered[i] ^= 0x01
+			if pop.VerifyProof(priv.Public(), context, tampered) {
+				t.Fatalf("%s: the proof tampered at %d is accepted", c.name, i)
+			}
+		}
+		if pop.VerifyProof(priv.Public(), context, proof[1:]) {
+			t.Fatalf("%s: the truncated proof is accepted", c.name)
+		}
+	}
+}
+
+func TestProveUnsupported(t *testing.T) {
+	p224, err := stdEcdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		priv ec.PrivateKey
+		err  error
+	}{
+		{p224, ec.ErrECTypeUnsupported},
+		{ed25519.PrivateKey{}, ec.ErrKeyTampered},
+		{&localECDSA.PrivateKey{}, ec.ErrKeyTampered},
+		// a scalar without its curve
+		{&localECDSA.PrivateKey{D: big.NewInt(1)}, ec.ErrKeyTampered},
+		{nil, ec.ErrKeyTampered},
+	}
+
+	for i, c := range testCases {
+		if _, err := pop.Prove(c.priv, nil); c.err != err {
+			t.Fatalf("#%d: want %v, got %v", i, c.err, err)
+		}
+	}
+
+	if pop.VerifyProof(&p224.PublicKey, nil, make([]byte, 57)) {
+		t.Fatal("the proof of an unsupported curve is accepted")
+	}
+	if pop.VerifyProof("not a key", nil, nil) {
+		t.Fatal("the proof of an unknown key is accepted")
+	}
+}
+
+func TestVerifySmallOrderKey(t *testing.T) {
+	// the identity, which any R and s = r satisfy for a known r
+	identity := make(ed25519.PublicKey, 32)
+	identity[0] = 0x01
+
+	if pop.VerifyProof(identity, nil, make([]byte, 64)) {
+		t.Fatal("the proof of a small order key is accepted")
+	}
+}
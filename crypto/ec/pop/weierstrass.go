@@ -0,0 +1,104 @@
+package pop
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	localECDSA "github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// curveName names the supported curve c in the transcripts
+func curveName(c elliptic.Curve) (string, bool) {
+	if secp.IsS256(c) {
+		return "secp256k1", true
+	}
+
+	params := c.Params()
+	for _, supported := range []elliptic.Curve{elliptic.P256(), elliptic.P521()} {
+		if want := supported.Params(); (params.Name == want.Name) && (0 == params.N.Cmp(want.N)) {
+			return want.Name, true
+		}
+	}
+
+	return "", false
+}
+
+func proveWeierstrass(priv *localECDSA.PrivateKey, context []byte) ([]byte, error) {
+	if (nil == priv) || (nil == priv.D) || (nil == priv.Curve) {
+		return nil, ec.ErrKeyTampered
+	}
+	name, ok := curveName(priv.Curve)
+	if !ok {
+		return nil, ec.ErrECTypeUnsupported
+	}
+
+	params := priv.Curve.Params()
+	if (priv.D.Sign() <= 0) || (priv.D.Cmp(params.N) >= 0) {
+		return nil, ec.ErrKeyTampered
+	}
+	pub, err := localECDSA.MarshalSEC1(&priv.PublicKey, true)
+	if nil != err {
+		return nil, err
+	}
+
+	k, err := rand.Int(rand.Reader, new(big.Int).Sub(params.N, big.NewInt(1)))
+	if nil != err {
+		return nil, err
+	}
+	k.Add(k, big.NewInt(1))
+
+	size := (params.BitSize + 7) / 8
+	Rx, Ry := priv.Curve.ScalarBaseMult(k.FillBytes(make([]byte, size)))
+	R, err := localECDSA.MarshalSEC1(&localECDSA.PublicKey{Curve: priv.Curve, X: Rx, Y: Ry}, true)
+	if nil != err {
+		return nil, err
+	}
+
+	c := new(big.Int).SetBytes(challenge(name, pub, context, R))
+	s := c.Mul(c, priv.D)
+	s.Add(s, k).Mod(s, params.N)
+
+	return append(R, s.FillBytes(make([]byte, size))...), nil
+}
+
+func verifyWeierstrass(pub *localECDSA.PublicKey, context, proof []byte) bool {
+	if nil != localECDSA.Validate(pub) {
+		return false
+	}
+	name, ok := curveName(pub.Curve)
+	if !ok {
+		return false
+	}
+
+	params := pub.Curve.Params()
+	size := (params.BitSize + 7) / 8
+	if (1+2*size != len(proof)) || ((0x02 != proof[0]) && (0x03 != proof[0])) {
+		return false
+	}
+
+	R, err := localECDSA.UnmarshalSEC1(pub.Curve, proof[:1+size])
+	if nil != err {
+		return false
+	}
+	s := new(big.Int).SetBytes(proof[1+size:])
+	if s.Cmp(params.N) >= 0 {
+		return false
+	}
+
+	pubBytes, err := localECDSA.MarshalSEC1(pub, true)
+	if nil != err {
+		return false
+	}
+	c := new(big.Int).SetBytes(challenge(name, pubBytes, context, proof[:1+size]))
+	c.Mod(c, params.N)
+
+	// sG = R + cX
+	sx, sy := pub.Curve.ScalarBaseMult(proof[1+size:])
+	cx, cy := pub.Curve.ScalarMult(pub.X, pub.Y, c.FillBytes(make([]byte, size)))
+	wantX, wantY := pub.Curve.Add(R.X, R.Y, cx, cy)
+
+	return (0 == sx.Cmp(wantX)) && (0 == sy.Cmp(wantY))
+}
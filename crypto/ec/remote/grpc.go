@@ -0,0 +1,232 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// the gRPC service carries the same JSON messages as the HTTP API, so no
+// generated protobuf code is needed
+const (
+	grpcServiceName = "gravity.remote.Signer"
+	codecName       = "json"
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return codecName
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// mapping between the errors and the gRPC status codes
+var grpcCodes = map[error]codes.Code{
+	ErrUnauthenticated:  codes.Unauthenticated,
+	ErrAccessDenied:     codes.PermissionDenied,
+	ErrKeyNotFound:      codes.NotFound,
+	ErrUnknownAlgorithm: codes.InvalidArgument,
+	ErrDigestSize:       codes.InvalidArgument,
+}
+
+func toStatus(err error) error {
+	if code, ok := grpcCodes[err]; ok {
+		return status.Error(code, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+func fromStatus(err error) error {
+	st := status.Convert(err)
+	for e, c := range grpcCodes {
+		if (c == st.Code()) && (e.Error() == st.Message()) {
+			return e
+		}
+	}
+
+	return err
+}
+
+func grpcIdentity(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return ""
+	}
+
+	return identityOf(&info.State)
+}
+
+func (s *Service) grpcGenerateKey(ctx context.Context, req *keyRequest) (*keyReply, error) {
+	reply := &keyReply{Alg: req.Alg}
+
+	var err error
+	if reply.ID, reply.PubKey, err = s.GenerateKey(grpcIdentity(ctx), req.Alg); nil != err {
+		return nil, toStatus(err)
+	}
+
+	return reply, nil
+}
+
+func (s *Service) grpcPublicKey(ctx context.Context, req *pubKeyRequest) (*keyReply, error) {
+	reply := &keyReply{ID: req.ID}
+
+	var err error
+	if reply.Alg, reply.PubKey, err = s.PublicKey(grpcIdentity(ctx), req.ID); nil != err {
+		return nil, toStatus(err)
+	}
+
+	return reply, nil
+}
+
+func (s *Service) grpcSign(ctx context.Context, req *signRequest) (*signReply, error) {
+	reply := new(signReply)
+
+	var err error
+	if reply.Sig, err = s.Sign(grpcIdentity(ctx), req.ID, req.Digest); nil != err {
+		return nil, toStatus(err)
+	}
+
+	return reply, nil
+}
+
+type grpcServer interface {
+	grpcGenerateKey(ctx context.Context, req *keyRequest) (*keyReply, error)
+	grpcPublicKey(ctx context.Context, req *pubKeyRequest) (*keyReply, error)
+	grpcSign(ctx context.Context, req *signRequest) (*signReply, error)
+}
+
+// grpcHandler decodes the request of method and calls it through the unary
+// interceptor of the server if any, as the generated code does
+func grpcHandler(method string, newReq func() interface{},
+	call func(s grpcServer, ctx context.Context, req interface{}) (interface{}, error)) grpc.MethodHandler {
+	return func(srv interface{}, ctx context.Context, dec func(interface{}) error,
+		interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+		req := newReq()
+		if err := dec(req); nil != err {
+			return nil, err
+		}
+
+		if nil == interceptor {
+			return call(srv.(grpcServer), ctx, req)
+		}
+
+		info := &grpc.UnaryServerInfo{
+			Server:     srv,
+			FullMethod: "/" + grpcServiceName + "/" + method,
+		}
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return call(srv.(grpcServer), ctx, req)
+		}
+
+		return interceptor(ctx, req, info, handler)
+	}
+}
+
+var grpcServiceDesc = grpc.ServiceDesc{
+	ServiceName: grpcServiceName,
+	HandlerType: (*grpcServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GenerateKey",
+			Handler: grpcHandler("GenerateKey", func() interface{} { return new(keyRequest) },
+				func(s grpcServer, ctx context.Context, req interface{}) (interface{}, error) {
+					return s.grpcGenerateKey(ctx, req.(*keyRequest))
+				}),
+		},
+		{
+			MethodName: "PublicKey",
+			Handler: grpcHandler("PublicKey", func() interface{} { return new(pubKeyRequest) },
+				func(s grpcServer, ctx context.Context, req interface{}) (interface{}, error) {
+					return s.grpcPublicKey(ctx, req.(*pubKeyRequest))
+				}),
+		},
+		{
+			MethodName: "Sign",
+			Handler: grpcHandler("Sign", func() interface{} { return new(signRequest) },
+				func(s grpcServer, ctx context.Context, req interface{}) (interface{}, error) {
+					return s.grpcSign(ctx, req.(*signRequest))
+				}),
+		},
+	},
+}
+
+// RegisterGRPC exposes s on srv, which must be created with the transport
+// credentials of ServerTLSConfig
+func (s *Service) RegisterGRPC(srv *grpc.Server) {
+	srv.RegisterService(&grpcServiceDesc, s)
+}
+
+type grpcTransport struct {
+	conn    *grpc.ClientConn
+	timeout time.Duration
+}
+
+func (t *grpcTransport) invoke(method string, req, reply interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), t.timeout)
+	defer cancel()
+
+	err := t.conn.Invoke(ctx, "/"+grpcServiceName+"/"+method, req, reply,
+		grpc.CallContentSubtype(codecName))
+	if nil != err {
+		return fromStatus(err)
+	}
+
+	return nil
+}
+
+func (t *grpcTransport) generateKey(req *keyRequest) (*keyReply, error) {
+	reply := new(keyReply)
+	if err := t.invoke("GenerateKey", req, reply); nil != err {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+func (t *grpcTransport) publicKey(req *pubKeyRequest) (*keyReply, error) {
+	reply := new(keyReply)
+	if err := t.invoke("PublicKey", req, reply); nil != err {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+func (t *grpcTransport) sign(req *signRequest) (*signReply, error) {
+	reply := new(signReply)
+	if err := t.invoke("Sign", req, reply); nil != err {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+// NewGRPCWorker makes a worker of alg calling the service over conn, which
+// must be dialed with the transport credentials carrying the client certificate
+func NewGRPCWorker(alg string, conn *grpc.ClientConn) *Worker {
+	return &Worker{Alg: alg, t: &grpcTransport{conn, 30 * time.Second}}
+}
@@ -0,0 +1,190 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// routes of the HTTP API
+//
+//	POST /v1/keys            generates a key
+//	GET  /v1/keys/{id}       fetches the public key
+//	POST /v1/keys/{id}/sign  signs a digest
+const keysPath = "/v1/keys"
+
+// maximum size of a request body
+const maxBodySize = 1 << 16
+
+type httpHandler struct {
+	s *Service
+}
+
+// NewHTTPHandler exposes s as a JSON API over HTTP. The handler must be
+// served by a TLS server configured as ServerTLSConfig
+func NewHTTPHandler(s *Service) http.Handler {
+	return &httpHandler{s}
+}
+
+func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	identity := identityOf(r.TLS)
+
+	path := strings.TrimPrefix(r.URL.Path, keysPath)
+	if path == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	var (
+		reply interface{}
+		err   error
+	)
+	switch {
+	case "" == parts[0] && http.MethodPost == r.Method:
+		req := new(keyRequest)
+		if err = readJSON(r, req); nil != err {
+			break
+		}
+		k := &keyReply{Alg: req.Alg}
+		k.ID, k.PubKey, err = h.s.GenerateKey(identity, req.Alg)
+		reply = k
+	case 1 == len(parts) && "" != parts[0] && http.MethodGet == r.Method:
+		k := &keyReply{ID: parts[0]}
+		k.Alg, k.PubKey, err = h.s.PublicKey(identity, parts[0])
+		reply = k
+	case 2 == len(parts) && "sign" == parts[1] && http.MethodPost == r.Method:
+		req := new(signRequest)
+		if err = readJSON(r, req); nil != err {
+			break
+		}
+		sig := new(signReply)
+		sig.Sig, err = h.s.Sign(identity, parts[0], req.Digest)
+		reply = sig
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	if nil != err {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reply)
+}
+
+// readJSON decodes the body into v, where any failure, e.g. an empty or
+// truncated body or a field of the wrong type, is the fault of the client
+func readJSON(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxBodySize)).Decode(v); nil != err {
+		return ErrMalformedRequest
+	}
+
+	return nil
+}
+
+// mapping between the errors and the HTTP status codes
+var httpStatus = map[error]int{
+	ErrUnauthenticated:  http.StatusUnauthorized,
+	ErrAccessDenied:     http.StatusForbidden,
+	ErrKeyNotFound:      http.StatusNotFound,
+	ErrUnknownAlgorithm: http.StatusBadRequest,
+	ErrMalformedRequest: http.StatusBadRequest,
+	ErrDigestSize:       http.StatusBadRequest,
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	code, ok := httpStatus[err]
+	if !ok {
+		code = http.StatusInternalServerError
+	}
+
+	http.Error(w, err.Error(), code)
+}
+
+type httpTransport struct {
+	baseURL string
+	client  *http.Client
+}
+
+func (t *httpTransport) do(method, path string, req, reply interface{}) error {
+	var body io.Reader
+	if nil != req {
+		buf, err := json.Marshal(req)
+		if nil != err {
+			return err
+		}
+		body = bytes.NewReader(buf)
+	}
+
+	httpReq, err := http.NewRequest(method, t.baseURL+path, body)
+	if nil != err {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(httpReq)
+	if nil != err {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if http.StatusOK != resp.StatusCode {
+		// several errors share a status code, which the message tells apart
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+		text := strings.TrimSpace(string(msg))
+		for e, code := range httpStatus {
+			if (code == resp.StatusCode) && (e.Error() == text) {
+				return e
+			}
+		}
+		return errors.New("remote: " + text)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, maxBodySize)).Decode(reply)
+}
+
+func (t *httpTransport) generateKey(req *keyRequest) (*keyReply, error) {
+	reply := new(keyReply)
+	if err := t.do(http.MethodPost, keysPath, req, reply); nil != err {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+func (t *httpTransport) publicKey(req *pubKeyRequest) (*keyReply, error) {
+	reply := new(keyReply)
+	if err := t.do(http.MethodGet, keysPath+"/"+req.ID, nil, reply); nil != err {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+func (t *httpTransport) sign(req *signRequest) (*signReply, error) {
+	reply := new(signReply)
+	if err := t.do(http.MethodPost, keysPath+"/"+req.ID+"/sign", req, reply); nil != err {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+// NewHTTPWorker makes a worker of alg calling the service at baseURL
+// (e.g. https://signer:8443), authenticating by the client certificate in config
+func NewHTTPWorker(alg, baseURL string, config *tls.Config) *Worker {
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: config},
+		Timeout:   30 * time.Second,
+	}
+
+	return &Worker{Alg: alg, t: &httpTransport{strings.TrimSuffix(baseURL, "/"), client}}
+}
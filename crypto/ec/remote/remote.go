@@ -0,0 +1,101 @@
+// Package remote implements a signing service keeping private keys on a
+// single host, together with a Worker calling it over HTTP or gRPC
+package remote
+
+// Note:
+// + clients are authenticated by mutual TLS, and the common name of the
+//   leaf certificate is taken as the identity of the caller
+// + every key carries an access list of identities allowed to use it, and
+//   the identity generating the key is always granted
+
+import (
+	"errors"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// names of the algorithms served
+const (
+	AlgECDSA256  = "ecdsa256"
+	AlgECDSA512  = "ecdsa512"
+	AlgEd25519   = "ed25519"
+	AlgSecp256k1 = "secp256k1"
+)
+
+var (
+	// ErrUnauthenticated means the caller presents no verified client certificate
+	ErrUnauthenticated = errors.New("remote: unauthenticated caller")
+	// ErrAccessDenied means the caller isn't on the access list of the key
+	ErrAccessDenied = errors.New("remote: access denied")
+	// ErrKeyNotFound means no key is registered under the given ID
+	ErrKeyNotFound = errors.New("remote: key not found")
+	// ErrUnknownAlgorithm means the algorithm isn't served
+	ErrUnknownAlgorithm = errors.New("remote: unknown algorithm")
+	// ErrMalformedRequest means the body of the request can't be decoded
+	ErrMalformedRequest = errors.New("remote: malformed request")
+	// ErrDigestSize means the digest to sign is empty or longer than 64 bytes
+	ErrDigestSize = errors.New("remote: the digest must be of 1 to 64 bytes")
+)
+
+// pubKeyCodec is implemented by the marshallers of every served algorithm
+type pubKeyCodec interface {
+	MarshalPubKey(pubKey ec.PublicKey) ([]byte, error)
+	UnmarshalPubKey(pubKeyBytes []byte) (ec.PublicKey, error)
+}
+
+type algorithm struct {
+	worker ec.Worker
+	codec  pubKeyCodec
+}
+
+func lookupAlgorithm(alg string) (*algorithm, error) {
+	switch alg {
+	case AlgECDSA256:
+		w := new(ecdsa.Worker256)
+		return &algorithm{w, w}, nil
+	case AlgECDSA512:
+		w := new(ecdsa.Worker512)
+		return &algorithm{w, w}, nil
+	case AlgEd25519:
+		return &algorithm{new(ed25519.Worker), new(ed25519.Marshaller)}, nil
+	case AlgSecp256k1:
+		w := secp.New()
+		return &algorithm{w, w}, nil
+	}
+
+	return nil, ErrUnknownAlgorithm
+}
+
+// messages exchanged over both transports
+type keyRequest struct {
+	Alg string `json:"alg"`
+}
+
+type pubKeyRequest struct {
+	ID string `json:"id"`
+}
+
+type keyReply struct {
+	ID     string `json:"id"`
+	Alg    string `json:"alg"`
+	PubKey []byte `json:"pubKey"`
+}
+
+type signRequest struct {
+	ID     string `json:"id"`
+	Digest []byte `json:"digest"`
+}
+
+type signReply struct {
+	Sig []byte `json:"sig"`
+}
+
+// transport abstracts the wire protocol used by the Worker
+type transport interface {
+	generateKey(req *keyRequest) (*keyReply, error)
+	publicKey(req *pubKeyRequest) (*keyReply, error)
+	sign(req *signRequest) (*signReply, error)
+}
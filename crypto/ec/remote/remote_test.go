@@ -0,0 +1,317 @@
+package remote_test
+
+import (
+	"context"
+	stdEcdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/remote"
+	"golang.org/x/crypto/sha3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+type pki struct {
+	pool   *x509.CertPool
+	server tls.Certificate
+	alice  tls.Certificate
+	bob    tls.Certificate
+}
+
+func issue(t *testing.T, ca *x509.Certificate, caKey *stdEcdsa.PrivateKey,
+	serial int64, cn string, usage x509.ExtKeyUsage) tls.Certificate {
+	key, err := stdEcdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func newPKI(t *testing.T) *pki {
+	caKey, err := stdEcdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gravity test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &caKey.PublicKey, caKey)
+	if nil != err {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	p := &pki{pool: x509.NewCertPool()}
+	p.pool.AddCert(ca)
+	p.server = issue(t, ca, caKey, 2, "signer", x509.ExtKeyUsageServerAuth)
+	p.alice = issue(t, ca, caKey, 3, "alice", x509.ExtKeyUsageClientAuth)
+	p.bob = issue(t, ca, caKey, 4, "bob", x509.ExtKeyUsageClientAuth)
+
+	return p
+}
+
+func (p *pki) clientConfig(cert tls.Certificate) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      p.pool,
+		ServerName:   "127.0.0.1",
+	}
+}
+
+var algs = []string{
+	remote.AlgECDSA256,
+	remote.AlgECDSA512,
+	remote.AlgEd25519,
+	remote.AlgSecp256k1,
+}
+
+// runWorkers checks alice owns the keys she generates, and bob may only use
+// them once granted
+func runWorkers(t *testing.T, svc *remote.Service, newWorker func(alg string, cert tls.Certificate) ec.Worker, p *pki) {
+	digest := sha3.Sum256([]byte("Hello World"))
+
+	for _, alg := range algs {
+		alice := newWorker(alg, p.alice)
+		bob := newWorker(alg, p.bob)
+
+		priv, err := alice.GenerateKey(rand.Reader)
+		if nil != err {
+			t.Fatalf("%s: %v", alg, err)
+		}
+
+		sig, err := alice.Sign(priv, digest[:])
+		if nil != err {
+			t.Fatalf("%s: %v", alg, err)
+		}
+		if !alice.Verify(priv.Public(), digest[:], sig) {
+			t.Fatalf("%s: the verification shouldn't fail", alg)
+		}
+
+		// corrupted digest
+		digest[11] = ^digest[11]
+		if alice.Verify(priv.Public(), digest[:], sig) {
+			t.Fatalf("%s: the verification should fail", alg)
+		}
+		digest[11] = ^digest[11]
+
+		if _, err := bob.Sign(priv, digest[:]); remote.ErrAccessDenied != err {
+			t.Fatalf("%s: want %v, got %v", alg, remote.ErrAccessDenied, err)
+		}
+		for _, bad := range [][]byte{nil, make([]byte, 65)} {
+			if _, err := alice.Sign(priv, bad); remote.ErrDigestSize != err {
+				t.Fatalf("%s: want %v, got %v", alg, remote.ErrDigestSize, err)
+			}
+		}
+
+		id := priv.(*remote.PrivateKey).ID
+		if err := svc.Grant(id, "bob"); nil != err {
+			t.Fatal(err)
+		}
+		loaded, err := bob.(*remote.Worker).LoadKey(id)
+		if nil != err {
+			t.Fatalf("%s: %v", alg, err)
+		}
+		if sig, err = bob.Sign(loaded, digest[:]); nil != err {
+			t.Fatalf("%s: %v", alg, err)
+		}
+		if !bob.Verify(priv.Public(), digest[:], sig) {
+			t.Fatalf("%s: the verification shouldn't fail", alg)
+		}
+
+		if err := svc.Revoke(id, "bob"); nil != err {
+			t.Fatal(err)
+		}
+		if _, err := bob.Sign(loaded, digest[:]); remote.ErrAccessDenied != err {
+			t.Fatalf("%s: want %v, got %v", alg, remote.ErrAccessDenied, err)
+		}
+	}
+
+	w := newWorker("rsa", p.alice)
+	if _, err := w.GenerateKey(rand.Reader); remote.ErrUnknownAlgorithm != err {
+		t.Fatalf("want %v, got %v", remote.ErrUnknownAlgorithm, err)
+	}
+	if _, err := w.(*remote.Worker).LoadKey("missing"); remote.ErrKeyNotFound != err {
+		t.Fatalf("want %v, got %v", remote.ErrKeyNotFound, err)
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	p := newPKI(t)
+	svc := remote.NewService()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", remote.ServerTLSConfig(p.server, p.pool))
+	if nil != err {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: remote.NewHTTPHandler(svc)}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	baseURL := "https://" + ln.Addr().String()
+	runWorkers(t, svc, func(alg string, cert tls.Certificate) ec.Worker {
+		return remote.NewHTTPWorker(alg, baseURL, p.clientConfig(cert))
+	}, p)
+
+	// the malformed bodies are the fault of the client
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: p.clientConfig(p.alice)}}
+	bodies := []string{"", `{"alg":`, `{"alg":42}`}
+	for i, body := range bodies {
+		resp, err := client.Post(baseURL+"/v1/keys", "application/json", strings.NewReader(body))
+		if nil != err {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if http.StatusBadRequest != resp.StatusCode {
+			t.Fatalf("#%d: want %d, got %d", i, http.StatusBadRequest, resp.StatusCode)
+		}
+	}
+
+	// clients without a certificate can't even finish the handshake
+	w := remote.NewHTTPWorker(remote.AlgEd25519, baseURL, &tls.Config{RootCAs: p.pool})
+	if _, err := w.GenerateKey(rand.Reader); nil == err {
+		t.Fatal("anonymous clients should be rejected")
+	}
+}
+
+func TestGRPC(t *testing.T) {
+	p := newPKI(t)
+	svc := remote.NewService()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer(grpc.Creds(credentials.NewTLS(remote.ServerTLSConfig(p.server, p.pool))))
+	svc.RegisterGRPC(srv)
+	go srv.Serve(ln)
+	defer srv.Stop()
+
+	var conns []*grpc.ClientConn
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	runWorkers(t, svc, func(alg string, cert tls.Certificate) ec.Worker {
+		conn, err := grpc.NewClient(ln.Addr().String(),
+			grpc.WithTransportCredentials(credentials.NewTLS(p.clientConfig(cert))))
+		if nil != err {
+			t.Fatal(err)
+		}
+		conns = append(conns, conn)
+
+		return remote.NewGRPCWorker(alg, conn)
+	}, p)
+}
+
+func TestGRPCInterceptor(t *testing.T) {
+	p := newPKI(t)
+	svc := remote.NewService()
+
+	// the interceptor counts the calls, and refuses to sign once disabled
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	signing := true
+	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		mu.Lock()
+		calls[info.FullMethod]++
+		allowed := signing || !strings.HasSuffix(info.FullMethod, "/Sign")
+		mu.Unlock()
+
+		if !allowed {
+			return nil, status.Error(codes.Unavailable, "signing disabled")
+		}
+		return handler(ctx, req)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer(grpc.Creds(credentials.NewTLS(remote.ServerTLSConfig(p.server, p.pool))),
+		grpc.UnaryInterceptor(interceptor))
+	svc.RegisterGRPC(srv)
+	go srv.Serve(ln)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient(ln.Addr().String(),
+		grpc.WithTransportCredentials(credentials.NewTLS(p.clientConfig(p.alice))))
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	w := remote.NewGRPCWorker(remote.AlgEd25519, conn)
+	priv, err := w.GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if _, err := w.LoadKey(priv.(*remote.PrivateKey).ID); nil != err {
+		t.Fatal(err)
+	}
+	if _, err := w.Sign(priv, []byte("digest")); nil != err {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	signing = false
+	mu.Unlock()
+	if _, err := w.Sign(priv, []byte("digest")); nil == err {
+		t.Fatal("the interceptor should refuse to sign")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for method, want := range map[string]int{"GenerateKey": 1, "PublicKey": 1, "Sign": 2} {
+		var got int
+		for full, n := range calls {
+			if strings.HasSuffix(full, "/"+method) {
+				got += n
+			}
+		}
+		if want != got {
+			t.Fatalf("%s: want %d calls, got %d", method, want, got)
+		}
+	}
+}
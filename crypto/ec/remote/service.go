@@ -0,0 +1,162 @@
+package remote
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"sync"
+
+	"github.com/sammy00/gravity/crypto/ec"
+)
+
+// maximum size of a digest, i.e. that of SHA-512
+const maxDigestSize = 64
+
+type entry struct {
+	alg    string
+	priv   ec.PrivateKey
+	pubKey []byte
+	acl    map[string]bool
+}
+
+// Service keeps the private keys and serves the requests of the workers
+type Service struct {
+	mtx  sync.RWMutex
+	keys map[string]*entry
+}
+
+// NewService makes an empty service
+func NewService() *Service {
+	return &Service{keys: make(map[string]*entry)}
+}
+
+// GenerateKey generates a key of alg owned by identity, and returns the ID
+// and the marshalled public key
+func (s *Service) GenerateKey(identity, alg string) (string, []byte, error) {
+	if "" == identity {
+		return "", nil, ErrUnauthenticated
+	}
+
+	a, err := lookupAlgorithm(alg)
+	if nil != err {
+		return "", nil, err
+	}
+
+	priv, err := a.worker.GenerateKey(rand.Reader)
+	if nil != err {
+		return "", nil, err
+	}
+
+	pubKey, err := a.codec.MarshalPubKey(priv.Public())
+	if nil != err {
+		return "", nil, err
+	}
+
+	// the ID is bound to the public key, so it never collides
+	h := sha256.Sum256(pubKey)
+	id := hex.EncodeToString(h[:16])
+
+	s.mtx.Lock()
+	s.keys[id] = &entry{alg, priv, pubKey, map[string]bool{identity: true}}
+	s.mtx.Unlock()
+
+	return id, pubKey, nil
+}
+
+// PublicKey returns the algorithm and marshalled public key of the key id
+func (s *Service) PublicKey(identity, id string) (string, []byte, error) {
+	e, err := s.lookup(identity, id)
+	if nil != err {
+		return "", nil, err
+	}
+
+	return e.alg, e.pubKey, nil
+}
+
+// Sign signs digest with the key id
+func (s *Service) Sign(identity, id string, digest []byte) (ec.Sig, error) {
+	e, err := s.lookup(identity, id)
+	if nil != err {
+		return nil, err
+	}
+
+	if (0 == len(digest)) || (len(digest) > maxDigestSize) {
+		return nil, ErrDigestSize
+	}
+
+	a, err := lookupAlgorithm(e.alg)
+	if nil != err {
+		return nil, err
+	}
+
+	return a.worker.Sign(e.priv, digest)
+}
+
+// Grant adds identity to the access list of the key id
+func (s *Service) Grant(id, identity string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	e, ok := s.keys[id]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	e.acl[identity] = true
+
+	return nil
+}
+
+// Revoke removes identity from the access list of the key id
+func (s *Service) Revoke(id, identity string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	e, ok := s.keys[id]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	delete(e.acl, identity)
+
+	return nil
+}
+
+func (s *Service) lookup(identity, id string) (*entry, error) {
+	if "" == identity {
+		return nil, ErrUnauthenticated
+	}
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	e, ok := s.keys[id]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	if !e.acl[identity] {
+		return nil, ErrAccessDenied
+	}
+
+	return e, nil
+}
+
+// ServerTLSConfig makes a config requiring every client to present a
+// certificate issued by one of clientCAs
+func ServerTLSConfig(cert tls.Certificate, clientCAs *x509.CertPool) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
+// identityOf extracts the caller identity from a verified connection
+func identityOf(state *tls.ConnectionState) string {
+	if nil == state || 0 == len(state.VerifiedChains) || 0 == len(state.VerifiedChains[0]) {
+		return ""
+	}
+
+	return state.VerifiedChains[0][0].Subject.CommonName
+}
@@ -0,0 +1,88 @@
+package remote
+
+import (
+	"io"
+
+	"github.com/sammy00/gravity/crypto/ec"
+)
+
+// PrivateKey refers to a private key held by the service
+type PrivateKey struct {
+	ID     string
+	Alg    string
+	pubKey ec.PublicKey
+}
+
+// Public returns the public key of the referred key
+func (priv *PrivateKey) Public() ec.PublicKey {
+	return priv.pubKey
+}
+
+// Worker implements ec.Worker by calling the signing service, so the private
+// keys never leave the service
+type Worker struct {
+	Alg string
+	t   transport
+}
+
+// GenerateKey asks the service to generate a key pair, rand is ignored since
+// the entropy is drawn by the service
+func (w *Worker) GenerateKey(rand io.Reader) (ec.PrivateKey, error) {
+	reply, err := w.t.generateKey(&keyRequest{Alg: w.Alg})
+	if nil != err {
+		return nil, err
+	}
+
+	return w.decodeKey(reply)
+}
+
+// LoadKey fetches the reference to an existing key
+func (w *Worker) LoadKey(id string) (*PrivateKey, error) {
+	reply, err := w.t.publicKey(&pubKeyRequest{ID: id})
+	if nil != err {
+		return nil, err
+	}
+
+	return w.decodeKey(reply)
+}
+
+// Sign asks the service to sign digest with privKey.
+func (w *Worker) Sign(privKey ec.PrivateKey, digest []byte) (ec.Sig, error) {
+	priv, ok := privKey.(*PrivateKey)
+	if !ok || (w.Alg != priv.Alg) {
+		return nil, ec.ErrKeyTampered
+	}
+
+	reply, err := w.t.sign(&signRequest{ID: priv.ID, Digest: digest})
+	if nil != err {
+		return nil, err
+	}
+
+	return reply.Sig, nil
+}
+
+// Verify verifies the signature in sig of hash using the public key, pubKey.
+// It is done locally without calling the service.
+func (w *Worker) Verify(pubKey ec.PublicKey, digest []byte, sig ec.Sig) bool {
+	a, err := lookupAlgorithm(w.Alg)
+
+	return (nil == err) && a.worker.Verify(pubKey, digest, sig)
+}
+
+func (w *Worker) decodeKey(reply *keyReply) (*PrivateKey, error) {
+	if w.Alg != reply.Alg {
+		return nil, ErrUnknownAlgorithm
+	}
+
+	a, err := lookupAlgorithm(reply.Alg)
+	if nil != err {
+		return nil, err
+	}
+
+	pubKey, err := a.codec.UnmarshalPubKey(reply.PubKey)
+	if nil != err {
+		return nil, err
+	}
+
+	return &PrivateKey{reply.ID, reply.Alg, pubKey}, nil
+}
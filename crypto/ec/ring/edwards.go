@@ -0,0 +1,117 @@
+package ring
+
+import (
+	"crypto/sha512"
+	"math/big"
+
+	"filippo.io/edwards25519"
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+)
+
+// edwardsGroup is the prime order subgroup of edwards25519
+type edwardsGroup struct{}
+
+// edwardsL is the order l of the prime order subgroup
+var edwardsL, _ = new(big.Int).SetString("1000000000000000000000000000000014def9dea2f79cd65812631a5cf5d3ed", 16)
+
+var edwards = new(edwardsGroup)
+
+func (g *edwardsGroup) tag() byte       { return tagEd25519 }
+func (g *edwardsGroup) order() *big.Int { return edwardsL }
+func (g *edwardsGroup) pointSize() int  { return 32 }
+
+func (g *edwardsGroup) secret(privKey ec.PrivateKey) (*big.Int, []byte, error) {
+	x, err := ed25519.Scalar(privKey.(ed25519.PrivateKey))
+	if nil != err {
+		return nil, nil, err
+	}
+
+	return fromEdwardsScalar(x), new(edwards25519.Point).ScalarBaseMult(x).Bytes(), nil
+}
+
+func (g *edwardsGroup) encode(pubKey ec.PublicKey) ([]byte, error) {
+	pub := pubKey.(ed25519.PublicKey)
+	if err := ed25519.Validate(pub); nil != err {
+		return nil, err
+	}
+
+	return []byte(pub), nil
+}
+
+// hashToPoint hashes pub with a counter until the digest decodes to a point,
+// whose cofactor is then cleared
+func (g *edwardsGroup) hashToPoint(pub []byte) ([]byte, error) {
+	for ctr := 0; ctr < 256; ctr++ {
+		h := sha512.New()
+		h.Write([]byte(domain + "/Hp"))
+		h.Write(pub)
+		h.Write([]byte{byte(ctr)})
+
+		p, ok := ed25519.DecodePoint(h.Sum(nil)[:32])
+		if !ok {
+			continue
+		}
+		if p.MultByCofactor(p); 1 != p.Equal(edwards25519.NewIdentityPoint()) {
+			return p.Bytes(), nil
+		}
+	}
+
+	return nil, ec.ErrNotOnCurve
+}
+
+func (g *edwardsGroup) combine(a *big.Int, A []byte, b *big.Int, B []byte) ([]byte, error) {
+	P := edwards25519.NewGeneratorPoint()
+	if nil != A {
+		var ok bool
+		if P, ok = ed25519.DecodePoint(A); !ok {
+			return nil, ec.ErrNotOnCurve
+		}
+	}
+	if nil == b {
+		return new(edwards25519.Point).ScalarMult(toEdwardsScalar(a), P).Bytes(), nil
+	}
+
+	Q, ok := ed25519.DecodePoint(B)
+	if !ok {
+		return nil, ec.ErrNotOnCurve
+	}
+
+	out := new(edwards25519.Point).MultiScalarMult(
+		[]*edwards25519.Scalar{toEdwardsScalar(a), toEdwardsScalar(b)}, []*edwards25519.Point{P, Q})
+
+	return out.Bytes(), nil
+}
+
+// validImage checks p is torsion free and isn't the identity
+func (g *edwardsGroup) validImage(p []byte) bool {
+	I, ok := ed25519.DecodePoint(p)
+
+	return ok && (1 != I.Equal(edwards25519.NewIdentityPoint())) && ed25519.IsTorsionFree(I)
+}
+
+// toEdwardsScalar converts the non-negative x to a scalar modulo l
+func toEdwardsScalar(x *big.Int) *edwards25519.Scalar {
+	buf := new(big.Int).Mod(x, edwardsL).FillBytes(make([]byte, 32))
+	reverse(buf)
+
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(buf)
+	if nil != err {
+		panic(err)
+	}
+
+	return s
+}
+
+func fromEdwardsScalar(s *edwards25519.Scalar) *big.Int {
+	buf := s.Bytes()
+	reverse(buf)
+
+	return new(big.Int).SetBytes(buf)
+}
+
+func reverse(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
@@ -0,0 +1,325 @@
+// Package ring implements the linkable spontaneous anonymous group (LSAG)
+// signatures, i.e. linkable ring signatures, over the keys of ed25519.Worker
+// and secp.Worker
+package ring
+
+// Note:
+// + a signature proves one member of the ring signed without telling which,
+//   and carries the key image I = x*Hp(P) of the signer, which is the same
+//   for all the signatures of a key whatever the ring and the message
+// + the signatures are tag || I || c_0 || s_0 || ... || s_(n-1), where the
+//   tag names the curve and the scalars are 32 bytes each
+// + the key images of ed25519 must lie in the prime order subgroup, as adding
+//   a small order point would make another image of the same key
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+var (
+	// ErrInvalidRing indicates the ring is empty, repeats a key or mixes
+	// the keys of different curves
+	ErrInvalidRing = errors.New("ring: invalid ring")
+	// ErrNotInRing indicates the key of the signer isn't a member of the ring
+	ErrNotInRing = errors.New("ring: the signer isn't in the ring")
+)
+
+// the tags of the curves prefixing the signatures
+const (
+	tagEd25519   byte = 1
+	tagSecp256k1 byte = 2
+)
+
+// scalarSize is the byte size of the scalars of both curves
+const scalarSize = 32
+
+// the domain separation tag of the challenges
+const domain = "gravity/ring/LSAG"
+
+// group abstracts the prime order groups the rings are over, whose points
+// are passed around in their encodings
+type group interface {
+	tag() byte
+	order() *big.Int
+	pointSize() int
+	// secret returns the scalar and the encoded public key of privKey
+	secret(privKey ec.PrivateKey) (*big.Int, []byte, error)
+	// encode validates pubKey and encodes it
+	encode(pubKey ec.PublicKey) ([]byte, error)
+	// hashToPoint maps an encoded public key to a point of unknown discrete
+	// logarithm
+	hashToPoint(pub []byte) ([]byte, error)
+	// combine computes a*A + b*B, where A is the base point if nil and the
+	// second term is dropped if b is nil
+	combine(a *big.Int, A []byte, b *big.Int, B []byte) ([]byte, error)
+	// validImage checks p encodes a key image of the prime order subgroup
+	validImage(p []byte) bool
+}
+
+// RingSign signs msg with privKey on behalf of ring, which must contain the
+// public key of privKey, without revealing the index of the signer
+func RingSign(privKey ec.PrivateKey, ring []ec.PublicKey, msg []byte) (ec.Sig, error) {
+	var g group
+	switch privKey.(type) {
+	case ed25519.PrivateKey:
+		g = edwards
+	case *secp.PrivateKey:
+		g = secp256k1
+	default:
+		return nil, ec.ErrECTypeUnsupported
+	}
+
+	x, pub, err := g.secret(privKey)
+	if nil != err {
+		return nil, err
+	}
+	keys, err := encodeRing(g, ring)
+	if nil != err {
+		return nil, err
+	}
+
+	signer := -1
+	for i, k := range keys {
+		if bytes.Equal(pub, k) {
+			signer = i
+		}
+	}
+	if signer < 0 {
+		return nil, ErrNotInRing
+	}
+
+	hp, err := g.hashToPoint(pub)
+	if nil != err {
+		return nil, err
+	}
+	image, err := g.combine(x, hp, nil, nil)
+	if nil != err {
+		return nil, err
+	}
+	prefix := transcript(g, keys, image, msg)
+
+	n, q := len(keys), g.order()
+	c, s := make([]*big.Int, n), make([]*big.Int, n)
+
+	u, err := rand.Int(rand.Reader, q)
+	if nil != err {
+		return nil, err
+	}
+	L, err := g.combine(u, nil, nil, nil)
+	if nil != err {
+		return nil, err
+	}
+	R, err := g.combine(u, hp, nil, nil)
+	if nil != err {
+		return nil, err
+	}
+
+	// close the ring from the member after the signer back to the signer
+	for i := (signer + 1) % n; ; i = (i + 1) % n {
+		c[i] = challenge(q, prefix, L, R)
+		if signer == i {
+			break
+		}
+
+		if s[i], err = rand.Int(rand.Reader, q); nil != err {
+			return nil, err
+		}
+		if L, R, err = round(g, keys[i], image, c[i], s[i]); nil != err {
+			return nil, err
+		}
+	}
+
+	// s_signer = u - c_signer*x
+	s[signer] = new(big.Int).Mul(c[signer], x)
+	s[signer].Sub(u, s[signer]).Mod(s[signer], q)
+
+	sig := make([]byte, 0, 1+g.pointSize()+(n+1)*scalarSize)
+	sig = append(append(sig, g.tag()), image...)
+	sig = append(sig, c[0].FillBytes(make([]byte, scalarSize))...)
+	for _, si := range s {
+		sig = append(sig, si.FillBytes(make([]byte, scalarSize))...)
+	}
+
+	return sig, nil
+}
+
+// RingVerify verifies the signature sig of msg by a member of ring, whose
+// keys must be in the order they were signed with
+func RingVerify(ring []ec.PublicKey, msg []byte, sig ec.Sig) bool {
+	if 0 == len(ring) {
+		return false
+	}
+	g, err := groupOf(ring[0])
+	if nil != err {
+		return false
+	}
+	keys, err := encodeRing(g, ring)
+	if nil != err {
+		return false
+	}
+
+	n, q := len(keys), g.order()
+	if (1+g.pointSize()+(n+1)*scalarSize != len(sig)) || (g.tag() != sig[0]) {
+		return false
+	}
+	image := sig[1 : 1+g.pointSize()]
+	if !g.validImage(image) {
+		return false
+	}
+
+	scalars := make([]*big.Int, n+1)
+	for i := range scalars {
+		start := 1 + g.pointSize() + i*scalarSize
+		scalars[i] = new(big.Int).SetBytes(sig[start : start+scalarSize])
+		if scalars[i].Cmp(q) >= 0 {
+			return false
+		}
+	}
+
+	prefix := transcript(g, keys, image, msg)
+	c := scalars[0]
+	for i, k := range keys {
+		L, R, err := round(g, k, image, c, scalars[i+1])
+		if nil != err {
+			return false
+		}
+		c = challenge(q, prefix, L, R)
+	}
+
+	return 0 == c.Cmp(scalars[0])
+}
+
+// Linked tells whether the signatures sigA and sigB are made by the same key,
+// which is only meaningful once both are verified
+func Linked(sigA, sigB ec.Sig) bool {
+	imageA, okA := keyImage(sigA)
+	imageB, okB := keyImage(sigB)
+
+	return okA && okB && (sigA[0] == sigB[0]) && bytes.Equal(imageA, imageB)
+}
+
+// keyImage extracts the key image of the signature sig
+func keyImage(sig ec.Sig) ([]byte, bool) {
+	if 0 == len(sig) {
+		return nil, false
+	}
+
+	var g group
+	switch sig[0] {
+	case tagEd25519:
+		g = edwards
+	case tagSecp256k1:
+		g = secp256k1
+	default:
+		return nil, false
+	}
+
+	// a ring has at least one member
+	body := len(sig) - 1 - g.pointSize()
+	if (body < 2*scalarSize) || (0 != body%scalarSize) {
+		return nil, false
+	}
+
+	return sig[1 : 1+g.pointSize()], true
+}
+
+// round computes L = s*G + c*P and R = s*Hp(P) + c*I for the member P
+func round(g group, pub, image []byte, c, s *big.Int) ([]byte, []byte, error) {
+	hp, err := g.hashToPoint(pub)
+	if nil != err {
+		return nil, nil, err
+	}
+
+	L, err := g.combine(s, nil, c, pub)
+	if nil != err {
+		return nil, nil, err
+	}
+	R, err := g.combine(s, hp, c, image)
+	if nil != err {
+		return nil, nil, err
+	}
+
+	return L, R, nil
+}
+
+// transcript hashes the curve, the ring, the key image and msg, which all
+// the challenges commit to
+func transcript(g group, keys [][]byte, image, msg []byte) []byte {
+	h := sha512.New()
+	h.Write([]byte(domain))
+	h.Write([]byte{g.tag()})
+
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(keys)))
+	h.Write(n[:])
+	for _, k := range keys {
+		h.Write(k)
+	}
+	h.Write(image)
+	h.Write(msg)
+
+	return h.Sum(nil)
+}
+
+// challenge hashes the transcript and the points L and R to a scalar
+func challenge(q *big.Int, prefix, L, R []byte) *big.Int {
+	h := sha512.New()
+	h.Write(prefix)
+	h.Write(L)
+	h.Write(R)
+
+	// the 512-bit digest makes the bias of the reduction negligible
+	c := new(big.Int).SetBytes(h.Sum(nil))
+
+	return c.Mod(c, q)
+}
+
+// groupOf finds the group of the public key pubKey
+func groupOf(pubKey ec.PublicKey) (group, error) {
+	switch pub := pubKey.(type) {
+	case ed25519.PublicKey:
+		return edwards, nil
+	case *secp.PublicKey:
+		if (nil != pub) && (nil != pub.Curve) && secp.IsS256(pub.Curve) {
+			return secp256k1, nil
+		}
+	}
+
+	return nil, ec.ErrECTypeUnsupported
+}
+
+// encodeRing validates and encodes the keys of ring, all of group g
+func encodeRing(g group, ring []ec.PublicKey) ([][]byte, error) {
+	if 0 == len(ring) {
+		return nil, ErrInvalidRing
+	}
+
+	keys := make([][]byte, len(ring))
+	seen := make(map[string]bool, len(ring))
+	for i, pubKey := range ring {
+		if other, err := groupOf(pubKey); (nil != err) || (other != g) {
+			return nil, ErrInvalidRing
+		}
+
+		k, err := g.encode(pubKey)
+		if nil != err {
+			return nil, err
+		}
+		if seen[string(k)] {
+			return nil, ErrInvalidRing
+		}
+		seen[string(k)] = true
+		keys[i] = k
+	}
+
+	return keys, nil
+}
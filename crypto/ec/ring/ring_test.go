@@ -0,0 +1,176 @@
+package ring_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	"github.com/sammy00/gravity/crypto/ec/ring"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+const ringSize = 5
+
+// generateRing generates ringSize keys by worker
+func generateRing(t *testing.T, worker ec.Worker) ([]ec.PrivateKey, []ec.PublicKey) {
+	privs := make([]ec.PrivateKey, ringSize)
+	pubs := make([]ec.PublicKey, ringSize)
+	for i := range privs {
+		priv, err := worker.GenerateKey(rand.Reader)
+		if nil != err {
+			t.Fatal(err)
+		}
+		privs[i], pubs[i] = priv, priv.Public()
+	}
+
+	return privs, pubs
+}
+
+var workers = []struct {
+	name   string
+	worker ec.Worker
+}{
+	{"ed25519", new(ed25519.Worker)},
+	{"secp256k1", secp.New()},
+}
+
+func TestRingSignVerify(t *testing.T) {
+	msg := []byte("vote for block 0x2a")
+
+	for _, w := range workers {
+		privs, pubs := generateRing(t, w.worker)
+
+		// every member can sign on behalf of the ring
+		for i, priv := range privs {
+			sig, err := ring.RingSign(priv, pubs, msg)
+			if nil != err {
+				t.Fatalf("%s #%d: unexpected error: %v", w.name, i, err)
+			}
+
+			if !ring.RingVerify(pubs, msg, sig) {
+				t.Fatalf("%s #%d: the verification shouldn't fail", w.name, i)
+			}
+			if ring.RingVerify(pubs, append(msg, '!'), sig) {
+				t.Fatalf("%s #%d: the signature of another message is accepted", w.name, i)
+			}
+
+			// the ring is committed to, including its order
+			reordered := append([]ec.PublicKey{pubs[ringSize-1]}, pubs[:ringSize-1]...)
+			if ring.RingVerify(reordered, msg, sig) {
+				t.Fatalf("%s #%d: the signature is accepted by a reordered ring", w.name, i)
+			}
+			if ring.RingVerify(pubs[:ringSize-1], msg, sig) {
+				t.Fatalf("%s #%d: the signature is accepted by a smaller ring", w.name, i)
+			}
+
+			for j := range sig {
+				tampered := append([]byte(nil), sig...)
+				tampered[j] ^= 0x01
+				if ring.RingVerify(pubs, msg, tampered) {
+					t.Fatalf("%s #%d: the signature tampered at %d is accepted", w.name, i, j)
+				}
+			}
+		}
+	}
+}
+
+func TestRingSingleMember(t *testing.T) {
+	for _, w := range workers {
+		priv, err := w.worker.GenerateKey(rand.Reader)
+		if nil != err {
+			t.Fatal(err)
+		}
+		pubs := []ec.PublicKey{priv.Public()}
+
+		sig, err := ring.RingSign(priv, pubs, nil)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if !ring.RingVerify(pubs, nil, sig) {
+			t.Fatalf("%s: the verification shouldn't fail", w.name)
+		}
+	}
+}
+
+func TestLinked(t *testing.T) {
+	for _, w := range workers {
+		privs, pubs := generateRing(t, w.worker)
+
+		// the same key votes twice, in different rings
+		sigA, err := ring.RingSign(privs[0], pubs, []byte("yes"))
+		if nil != err {
+			t.Fatal(err)
+		}
+		sigB, err := ring.RingSign(privs[0], pubs[:3], []byte("no"))
+		if nil != err {
+			t.Fatal(err)
+		}
+		sigC, err := ring.RingSign(privs[1], pubs, []byte("yes"))
+		if nil != err {
+			t.Fatal(err)
+		}
+
+		if !ring.Linked(sigA, sigB) {
+			t.Fatalf("%s: the signatures of the same key aren't linked", w.name)
+		}
+		if ring.Linked(sigA, sigC) {
+			t.Fatalf("%s: the signatures of different keys are linked", w.name)
+		}
+		if ring.Linked(sigA, sigA[:len(sigA)-1]) {
+			t.Fatalf("%s: a malformed signature is linked", w.name)
+		}
+	}
+
+	edPriv, err := new(ed25519.Worker).GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	secpPriv, err := secp.New().GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	edSig, err := ring.RingSign(edPriv, []ec.PublicKey{edPriv.Public()}, nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+	secpSig, err := ring.RingSign(secpPriv, []ec.PublicKey{secpPriv.Public()}, nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if ring.Linked(edSig, secpSig) || ring.Linked(nil, nil) {
+		t.Fatal("signatures over different curves are linked")
+	}
+}
+
+func TestRingSignInvalid(t *testing.T) {
+	edPrivs, edPubs := generateRing(t, new(ed25519.Worker))
+	secpPrivs, secpPubs := generateRing(t, secp.New())
+
+	testCases := []struct {
+		priv ec.PrivateKey
+		ring []ec.PublicKey
+		err  error
+	}{
+		{edPrivs[0], nil, ring.ErrInvalidRing},
+		{edPrivs[0], edPubs[1:], ring.ErrNotInRing},
+		{edPrivs[0], append(edPubs, edPubs[0]), ring.ErrInvalidRing},
+		{edPrivs[0], append(edPubs, secpPubs[0]), ring.ErrInvalidRing},
+		{secpPrivs[0], append(secpPubs, edPubs[0]), ring.ErrInvalidRing},
+		{secpPrivs[0], edPubs, ring.ErrInvalidRing},
+		{&ed25519.PrivateKey{}, edPubs, ec.ErrECTypeUnsupported},
+	}
+
+	for i, c := range testCases {
+		if _, err := ring.RingSign(c.priv, c.ring, nil); c.err != err {
+			t.Fatalf("#%d: want %v, got %v", i, c.err, err)
+		}
+	}
+
+	// the identity is no member of any ring
+	identity := make(ed25519.PublicKey, 32)
+	identity[0] = 0x01
+	if _, err := ring.RingSign(edPrivs[0], append(edPubs, identity), nil); ec.ErrPointAtInfinity != err {
+		t.Fatalf("want %v, got %v", ec.ErrPointAtInfinity, err)
+	}
+}
@@ -0,0 +1,94 @@
+package ring
+
+import (
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	localECDSA "github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// secpGroup is the group of secp256k1, whose points are compressed as SEC1
+type secpGroup struct{}
+
+var secp256k1 = &secpGroup{}
+
+// s256 is the curve of the ring signatures over secp256k1
+var s256 = secp.S256()
+
+func (g *secpGroup) tag() byte       { return tagSecp256k1 }
+func (g *secpGroup) order() *big.Int { return s256.Params().N }
+func (g *secpGroup) pointSize() int  { return 33 }
+
+func (g *secpGroup) secret(privKey ec.PrivateKey) (*big.Int, []byte, error) {
+	priv := privKey.(*secp.PrivateKey)
+	if (nil == priv) || (nil == priv.D) || (nil == priv.Curve) || !secp.IsS256(priv.Curve) {
+		return nil, nil, ec.ErrKeyTampered
+	}
+
+	pub, err := g.encode(&priv.PublicKey)
+	if nil != err {
+		return nil, nil, err
+	}
+
+	return priv.D, pub, nil
+}
+
+func (g *secpGroup) encode(pubKey ec.PublicKey) ([]byte, error) {
+	return localECDSA.MarshalSEC1(pubKey.(*secp.PublicKey), true)
+}
+
+// hashToPoint hashes pub with a counter until the digest is the x-coordinate
+// of a point, taken with an even y
+func (g *secpGroup) hashToPoint(pub []byte) ([]byte, error) {
+	H, err := localECDSA.HashToPoint(s256, func(ctr int) []byte {
+		h := sha256.New()
+		h.Write([]byte(domain + "/Hp"))
+		h.Write(pub)
+		h.Write([]byte{byte(ctr)})
+
+		return h.Sum(nil)
+	})
+	if nil != err {
+		return nil, err
+	}
+
+	return localECDSA.MarshalSEC1(H, true)
+}
+
+func (g *secpGroup) combine(a *big.Int, A []byte, b *big.Int, B []byte) ([]byte, error) {
+	out := &secp.PublicKey{Curve: s256}
+	if nil == A {
+		out.X, out.Y = s256.ScalarBaseMult(a.Bytes())
+	} else {
+		P, err := localECDSA.UnmarshalSEC1(s256, A)
+		if nil != err {
+			return nil, err
+		}
+		out.X, out.Y = s256.ScalarMult(P.X, P.Y, a.Bytes())
+	}
+
+	if nil != b {
+		Q, err := localECDSA.UnmarshalSEC1(s256, B)
+		if nil != err {
+			return nil, err
+		}
+		x, y := s256.ScalarMult(Q.X, Q.Y, b.Bytes())
+		out.X, out.Y = s256.Add(out.X, out.Y, x, y)
+	}
+
+	// the point at infinity fails the encoding
+	return localECDSA.MarshalSEC1(out, true)
+}
+
+// validImage checks p is a compressed point, the cofactor of secp256k1
+// being 1
+func (g *secpGroup) validImage(p []byte) bool {
+	if (33 != len(p)) || ((0x02 != p[0]) && (0x03 != p[0])) {
+		return false
+	}
+	_, err := localECDSA.UnmarshalSEC1(s256, p)
+
+	return nil == err
+}
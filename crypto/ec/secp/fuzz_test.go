@@ -0,0 +1,110 @@
+package secp_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	localECDSA "github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+	"golang.org/x/crypto/sha3"
+)
+
+// checkErr fails unless err is one of the typed errors of the decoders
+func checkErr(t *testing.T, err error) {
+	switch err {
+	case ec.ErrWrongVersion, ec.ErrMalformedKey, ec.ErrInvalidKey,
+		ec.ErrNotOnCurve, ec.ErrPointAtInfinity, ec.ErrSmallOrder, ec.ErrECTypeUnsupported,
+		ec.ErrMalformedSig:
+	default:
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func FuzzUnmarshalPrivKey(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		worker := secp.New256()
+
+		key, err := worker.UnmarshalPrivKey(data)
+		if nil != err {
+			checkErr(t, err)
+			return
+		}
+
+		// a decoded key must sign verifiable signatures
+		priv := key.(*ecdsa.PrivateKey)
+		digest := sha3.Sum256([]byte("Hello World"))
+		sig, err := worker.Sign(priv, digest[:])
+		if nil != err {
+			t.Fatal(err)
+		}
+		if !worker.Verify(priv.Public(), digest[:], sig) {
+			t.Fatal("the verification shouldn't fail")
+		}
+
+		if out, err := worker.MarshalPrivKey(priv); nil != err || !bytes.Equal(data, out) {
+			t.Fatalf("the round trip changes the key: %v", err)
+		}
+	})
+}
+
+func FuzzUnmarshalPubKey(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		worker := secp.New256()
+
+		key, err := worker.UnmarshalPubKey(data)
+		if nil != err {
+			checkErr(t, err)
+			return
+		}
+
+		pub := key.(*ecdsa.PublicKey)
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			t.Fatal("the decoded point isn't on the curve")
+		}
+
+		// the keys of the former versions and the uncompressed points are
+		// marshalled in the latest version as compressed points
+		out, err := worker.MarshalPubKey(pub)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if (data[0] == out[0]) && (len(data) == len(out)) && !bytes.Equal(data, out) {
+			t.Fatal("the round trip changes the key")
+		}
+		again, err := worker.UnmarshalPubKey(out)
+		if (nil != err) || (0 != pub.X.Cmp(again.(*ecdsa.PublicKey).X)) || (0 != pub.Y.Cmp(again.(*ecdsa.PublicKey).Y)) {
+			t.Fatalf("the round trip changes the key: %v", err)
+		}
+	})
+}
+
+func FuzzUnmarshalSig(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, encoding := range []localECDSA.SigEncoding{localECDSA.EncodingDER, localECDSA.EncodingP1363} {
+			worker := secp.New()
+			worker.Encoding = encoding
+
+			sig, err := worker.UnmarshalSig(data)
+			if nil != err {
+				checkErr(t, err)
+				continue
+			}
+
+			// a decoded signature is in the encoding of the worker
+			if localECDSA.EncodingP1363 == encoding {
+				_, err = localECDSA.FromP1363(secp.S256(), sig)
+			} else {
+				_, err = localECDSA.ToP1363(secp.S256(), sig)
+			}
+			if nil != err {
+				t.Fatalf("the signature isn't in the encoding %d: %v", encoding, err)
+			}
+
+			if out, err := worker.MarshalSig(sig); nil != err || !bytes.Equal(data, out) {
+				t.Fatalf("the round trip changes the signature: %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package secp
+
+// Note:
+// + a recoverable signature is r||s||v of 65 bytes, where the recovery id v
+//   tells the parity of the Y of R = kG in its bit 0 and whether the X of R
+//   overflows the order n in its bit 1
+// + the public key is then recovered as Q = r^-1(sR - eG)
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	localECDSA "github.com/sammy00/gravity/crypto/ec/ecdsa"
+)
+
+// RecoverableSigSize is the byte size of the recoverable signatures
+const RecoverableSigSize = 65
+
+// SignRecoverable signs digest with privKey into r||s||v, where s is low
+func (w *Worker) SignRecoverable(privKey ec.PrivateKey, digest []byte) (ec.Sig, error) {
+	priv, ok := privKey.(*PrivateKey)
+	if !ok {
+		return nil, ec.ErrKeyTampered
+	}
+	if err := Validate(&priv.PublicKey); nil != err {
+		return nil, err
+	}
+
+	der, err := priv.Sign(rand.Reader, digest, nil)
+	if nil != err {
+		return nil, err
+	}
+	if der, err = localECDSA.NormalizeSig(priv.Curve, der); nil != err {
+		return nil, err
+	}
+	sig, err := localECDSA.ToP1363(priv.Curve, der)
+	if nil != err {
+		return nil, err
+	}
+
+	sig = append(sig, 0)
+	for v := byte(0); v < 4; v++ {
+		sig[RecoverableSigSize-1] = v
+		pub, err := recoverPubKey(digest, sig)
+		if (nil == err) && (0 == pub.X.Cmp(priv.X)) && (0 == pub.Y.Cmp(priv.Y)) {
+			return sig, nil
+		}
+	}
+
+	return nil, ec.ErrMalformedSig
+}
+
+// RecoverPubKey recovers the public key whose recoverable signature on
+// digest is sig, refusing the high s if the worker is strict
+func (w *Worker) RecoverPubKey(digest []byte, sig ec.Sig) (ec.PublicKey, error) {
+	if (RecoverableSigSize == len(sig)) && w.Strict &&
+		!localECDSA.IsLowS(S256(), new(big.Int).SetBytes(sig[32:64])) {
+		return nil, ec.ErrMalformedSig
+	}
+
+	return recoverPubKey(digest, sig)
+}
+
+func recoverPubKey(digest []byte, sig ec.Sig) (*PublicKey, error) {
+	if (RecoverableSigSize != len(sig)) || (3 < sig[RecoverableSigSize-1]) {
+		return nil, ec.ErrMalformedSig
+	}
+
+	c := S256()
+	params := c.Params()
+
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:64])
+	v := sig[RecoverableSigSize-1]
+	if (0 == r.Sign()) || (0 == s.Sign()) || (r.Cmp(params.N) >= 0) || (s.Cmp(params.N) >= 0) {
+		return nil, ec.ErrMalformedSig
+	}
+
+	// lift the X of R back into the point of the parity v&1
+	x := new(big.Int).Set(r)
+	if 1 == v>>1 {
+		x.Add(x, params.N)
+	}
+	if x.Cmp(params.P) >= 0 {
+		return nil, ec.ErrMalformedSig
+	}
+	point := make([]byte, 33)
+	point[0] = 0x02 | (v & 1)
+	x.FillBytes(point[1:])
+
+	R, err := localECDSA.UnmarshalSEC1(c, point)
+	if nil != err {
+		return nil, ec.ErrMalformedSig
+	}
+
+	// Q = u1 G + u2 R with u1 = -e r^-1 and u2 = s r^-1
+	rInv := new(big.Int).ModInverse(r, params.N)
+	u1 := new(big.Int).Mul(hashToInt(digest, params.N), rInv)
+	u1.Neg(u1).Mod(u1, params.N)
+	u2 := new(big.Int).Mul(s, rInv)
+	u2.Mod(u2, params.N)
+
+	qx, qy := c.ScalarMult(R.X, R.Y, u2.Bytes())
+	if 0 != u1.Sign() {
+		gx, gy := c.ScalarBaseMult(u1.Bytes())
+		qx, qy = c.Add(gx, gy, qx, qy)
+	}
+
+	pub := &PublicKey{Curve: c, X: qx, Y: qy}
+	if err := Validate(pub); nil != err {
+		return nil, ec.ErrMalformedSig
+	}
+
+	return pub, nil
+}
+
+// hashToInt converts digest to an integer as the ECDSA of the standard
+// library, keeping its leftmost bits as many as those of n
+func hashToInt(digest []byte, n *big.Int) *big.Int {
+	orderBytes := (n.BitLen() + 7) / 8
+	if len(digest) > orderBytes {
+		digest = digest[:orderBytes]
+	}
+
+	e := new(big.Int).SetBytes(digest)
+	if excess := len(digest)*8 - n.BitLen(); excess > 0 {
+		e.Rsh(e, uint(excess))
+	}
+
+	return e
+}
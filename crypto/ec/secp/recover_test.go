@@ -0,0 +1,115 @@
+package secp_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	localECDSA "github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestSignRecoverable(t *testing.T) {
+	w := secp.New()
+	priv, err := w.GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	pub := priv.Public().(*secp.PublicKey)
+
+	digest := sha3.Sum256([]byte("Hello World"))
+	for i := 0; i < 8; i++ {
+		sig, err := w.SignRecoverable(priv, digest[:])
+		if nil != err {
+			t.Fatal(err)
+		}
+		if secp.RecoverableSigSize != len(sig) {
+			t.Fatalf("#%d: want %d bytes, got %d", i, secp.RecoverableSigSize, len(sig))
+		}
+
+		got, err := w.RecoverPubKey(digest[:], sig)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if q := got.(*secp.PublicKey); (0 != q.X.Cmp(pub.X)) || (0 != q.Y.Cmp(pub.Y)) {
+			t.Fatalf("#%d: recovered another key", i)
+		}
+
+		// r||s is a plain signature as well
+		der, err := localECDSA.FromP1363(secp.S256(), sig[:64])
+		if nil != err {
+			t.Fatal(err)
+		}
+		if !w.Verify(pub, digest[:], der) {
+			t.Fatal("the verification shouldn't fail")
+		}
+	}
+}
+
+// the EIP-712 Mail example signed by keccak256("cow")
+func TestRecoverPubKey(t *testing.T) {
+	digest, _ := hex.DecodeString("be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2")
+	sig, _ := hex.DecodeString("4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d" +
+		"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562" + "01")
+
+	d := sha3.NewLegacyKeccak256()
+	d.Write([]byte("cow"))
+	x, y := secp.S256().ScalarBaseMult(d.Sum(nil))
+
+	w := secp.New()
+	got, err := w.RecoverPubKey(digest, sig)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if q := got.(*secp.PublicKey); (0 != q.X.Cmp(x)) || (0 != q.Y.Cmp(y)) {
+		t.Fatal("recovered another key")
+	}
+
+	// the other parity recovers another key
+	sig[64] = 0
+	if got, err := w.RecoverPubKey(digest, sig); (nil == err) && (0 == got.(*secp.PublicKey).X.Cmp(x)) {
+		t.Fatal("the recovery should fail")
+	}
+}
+
+func TestRecoverPubKeyMalformed(t *testing.T) {
+	w := secp.New()
+	priv, err := w.GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	digest := sha3.Sum256([]byte("Hello World"))
+	sig, err := w.SignRecoverable(priv, digest[:])
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	n := secp.S256().Params().N
+	highS := append([]byte(nil), sig...)
+	new(big.Int).Sub(n, new(big.Int).SetBytes(sig[32:64])).FillBytes(highS[32:64])
+	highS[64] ^= 1
+
+	// the high s recovers the same key unless the worker is strict
+	if _, err := w.RecoverPubKey(digest[:], highS); nil != err {
+		t.Fatal(err)
+	}
+	w.Strict = true
+
+	testCases := []ec.Sig{
+		sig[:64],
+		append(append([]byte(nil), sig[:64]...), 4),
+		append(make([]byte, 32), sig[32:]...),
+		append(n.Bytes(), sig[32:]...),
+		highS,
+	}
+
+	for i, c := range testCases {
+		if _, err := w.RecoverPubKey(digest[:], c); ec.ErrMalformedSig != err {
+			t.Fatalf("#%d: want %v, got %v", i, ec.ErrMalformedSig, err)
+		}
+	}
+}
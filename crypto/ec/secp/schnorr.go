@@ -0,0 +1,187 @@
+package secp
+
+// Note:
+// + BIP-340 Schnorr signatures are R.X||s of 64 bytes, verified against the
+//   32-byte X of a public key whose Y is implicitly even
+// + the signer negates its scalar whenever its public point has an odd Y, and
+//   likewise the nonce whenever R has an odd Y
+// + the nonce mixes the scalar with the hash of the auxiliary random data,
+//   which keeps the signatures sound however weak the randomness is
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	localECDSA "github.com/sammy00/gravity/crypto/ec/ecdsa"
+)
+
+const (
+	// SchnorrSigSize is the byte size of the BIP-340 signatures
+	SchnorrSigSize = 64
+	// XOnlySize is the byte size of the BIP-340 public keys
+	XOnlySize = 32
+)
+
+// TaggedHash computes SHA256(SHA256(tag) || SHA256(tag) || msg) of BIP-340
+func TaggedHash(tag string, msg ...[]byte) []byte {
+	t := sha256.Sum256([]byte(tag))
+
+	h := sha256.New()
+	h.Write(t[:])
+	h.Write(t[:])
+	for _, m := range msg {
+		h.Write(m)
+	}
+
+	return h.Sum(nil)
+}
+
+// XOnly encodes the X of pubKey in 32 bytes as the BIP-340 public keys
+func XOnly(pubKey *PublicKey) ([]byte, error) {
+	if err := Validate(pubKey); nil != err {
+		return nil, err
+	}
+
+	out := make([]byte, XOnlySize)
+	pubKey.X.FillBytes(out)
+
+	return out, nil
+}
+
+// LiftX decodes the BIP-340 public key pub into the point of even Y
+func LiftX(pub []byte) (*PublicKey, error) {
+	if XOnlySize != len(pub) {
+		return nil, ec.ErrMalformedKey
+	}
+
+	return localECDSA.UnmarshalSEC1(S256(), append([]byte{0x02}, pub...))
+}
+
+// SignSchnorr signs msg with privKey as BIP-340 with fresh auxiliary random
+// data
+func (w *Worker) SignSchnorr(privKey ec.PrivateKey, msg []byte) (ec.Sig, error) {
+	priv, ok := privKey.(*PrivateKey)
+	if !ok {
+		return nil, ec.ErrKeyTampered
+	}
+
+	aux := make([]byte, 32)
+	if _, err := rand.Read(aux); nil != err {
+		return nil, err
+	}
+
+	return SignSchnorr(priv, msg, aux)
+}
+
+// VerifySchnorr verifies the BIP-340 signature sig of msg by the public key,
+// given as either a *PublicKey or its 32-byte X
+func (w *Worker) VerifySchnorr(pubKey ec.PublicKey, msg []byte, sig ec.Sig) bool {
+	switch pub := pubKey.(type) {
+	case []byte:
+		return VerifySchnorr(pub, msg, sig)
+	case *PublicKey:
+		x, err := XOnly(pub)
+		return (nil == err) && VerifySchnorr(x, msg, sig)
+	}
+
+	return false
+}
+
+// SignSchnorr signs msg with priv as BIP-340 with the 32 bytes of auxiliary
+// random data aux
+func SignSchnorr(priv *PrivateKey, msg, aux []byte) (ec.Sig, error) {
+	if 32 != len(aux) {
+		return nil, ec.ErrMalformedSig
+	}
+
+	c := S256()
+	n := c.Params().N
+	if (nil == priv.D) || (0 >= priv.D.Sign()) || (priv.D.Cmp(n) >= 0) {
+		return nil, ec.ErrInvalidKey
+	}
+
+	px, py := c.ScalarBaseMult(priv.D.Bytes())
+	d := new(big.Int).Set(priv.D)
+	if 1 == py.Bit(0) {
+		d.Sub(n, d)
+	}
+	pub := make([]byte, XOnlySize)
+	px.FillBytes(pub)
+
+	// t = bytes(d) xor hash_BIP0340/aux(aux)
+	t := make([]byte, 32)
+	d.FillBytes(t)
+	for i, b := range TaggedHash("BIP0340/aux", aux) {
+		t[i] ^= b
+	}
+
+	k := new(big.Int).SetBytes(TaggedHash("BIP0340/nonce", t, pub, msg))
+	k.Mod(k, n)
+	if 0 == k.Sign() {
+		return nil, ec.ErrInvalidKey
+	}
+
+	rx, ry := c.ScalarBaseMult(k.Bytes())
+	if 1 == ry.Bit(0) {
+		k.Sub(n, k)
+	}
+
+	sig := make([]byte, SchnorrSigSize)
+	rx.FillBytes(sig[:32])
+
+	e := schnorrChallenge(sig[:32], pub, msg)
+	s := e.Mul(e, d)
+	s.Add(s, k).Mod(s, n)
+	s.FillBytes(sig[32:])
+
+	if !VerifySchnorr(pub, msg, sig) {
+		return nil, ec.ErrInvalidKey
+	}
+
+	return sig, nil
+}
+
+// VerifySchnorr verifies the BIP-340 signature sig of msg by the 32-byte
+// public key pub
+func VerifySchnorr(pub, msg []byte, sig ec.Sig) bool {
+	if SchnorrSigSize != len(sig) {
+		return false
+	}
+	P, err := LiftX(pub)
+	if nil != err {
+		return false
+	}
+
+	c := S256()
+	params := c.Params()
+
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if (r.Cmp(params.P) >= 0) || (s.Cmp(params.N) >= 0) {
+		return false
+	}
+
+	// R = sG - eP
+	e := schnorrChallenge(sig[:32], pub, msg)
+	e.Sub(params.N, e)
+
+	sx, sy := c.ScalarBaseMult(s.Bytes())
+	ex, ey := c.ScalarMult(P.X, P.Y, e.Bytes())
+	rx, ry := c.Add(sx, sy, ex, ey)
+
+	R := &PublicKey{Curve: c, X: rx, Y: ry}
+	if nil != Validate(R) {
+		return false
+	}
+
+	return (0 == ry.Bit(0)) && (0 == rx.Cmp(r))
+}
+
+// schnorrChallenge computes int(hash_BIP0340/challenge(r || pub || msg)) mod n
+func schnorrChallenge(r, pub, msg []byte) *big.Int {
+	e := new(big.Int).SetBytes(TaggedHash("BIP0340/challenge", r, pub, msg))
+
+	return e.Mod(e, S256().Params().N)
+}
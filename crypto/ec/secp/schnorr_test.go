@@ -0,0 +1,98 @@
+package secp_test
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec/secp"
+	"golang.org/x/crypto/sha3"
+)
+
+// the test vectors of BIP-340
+func TestSchnorrBIP340(t *testing.T) {
+	fd, err := os.Open(filepath.Join("testdata", "bip340.csv"))
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+
+	records, err := csv.NewReader(fd).ReadAll()
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	for _, r := range records[1:] {
+		index := r[0]
+		pub, _ := hex.DecodeString(r[2])
+		aux, _ := hex.DecodeString(r[3])
+		msg, _ := hex.DecodeString(r[4])
+		sig, _ := hex.DecodeString(r[5])
+		want := "TRUE" == r[6]
+
+		if got := secp.VerifySchnorr(pub, msg, sig); want != got {
+			t.Fatalf("#%s: want %v, got %v (%s)", index, want, got, r[7])
+		}
+
+		if "" == r[1] {
+			continue
+		}
+
+		priv := new(secp.PrivateKey)
+		priv.Curve = secp.S256()
+		priv.D, _ = new(big.Int).SetString(r[1], 16)
+		priv.X, priv.Y = priv.Curve.ScalarBaseMult(priv.D.Bytes())
+
+		x, err := secp.XOnly(&priv.PublicKey)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(pub) != hex.EncodeToString(x) {
+			t.Fatalf("#%s: want %x, got %x", index, pub, x)
+		}
+
+		got, err := secp.SignSchnorr(priv, msg, aux)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(sig) != hex.EncodeToString(got) {
+			t.Fatalf("#%s: want %x, got %x", index, sig, got)
+		}
+	}
+}
+
+func TestSignSchnorr(t *testing.T) {
+	w := secp.New()
+	priv, err := w.GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	pub := priv.Public().(*secp.PublicKey)
+
+	msg := sha3.Sum256([]byte("Hello World"))
+	sig, err := w.SignSchnorr(priv, msg[:])
+	if nil != err {
+		t.Fatal(err)
+	}
+	if !w.VerifySchnorr(pub, msg[:], sig) {
+		t.Fatal("the verification shouldn't fail")
+	}
+
+	x, err := secp.XOnly(pub)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if !w.VerifySchnorr(x, msg[:], sig) {
+		t.Fatal("the verification shouldn't fail")
+	}
+
+	// corrupted message
+	msg[11] = ^msg[11]
+	if w.VerifySchnorr(pub, msg[:], sig) {
+		t.Fatal("the verification should fail")
+	}
+}
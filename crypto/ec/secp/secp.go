@@ -2,19 +2,26 @@ package secp
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/elliptic"
 	"encoding/asn1"
-	"errors"
 	"io"
 	"math/big"
 
 	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/codec"
 	localECDSA "github.com/sammy00/gravity/crypto/ec/ecdsa"
 	"github.com/sammy00/secp"
 	"github.com/sammy00/secp/curve"
 )
 
-const codecVersion = 1
+const (
+	codecVersion = 1
+	// public keys are marshalled as pubKeyVersion || curve || SEC1 compressed
+	// point since version 2, and as version || bitSize || asn1(X,Y) before
+	pubKeyVersion = 2
+)
 
 type PublicKey = ecdsa.PublicKey
 type PrivateKey = ecdsa.PrivateKey
@@ -35,6 +42,20 @@ func New256() *Worker {
 	return w
 }
 
+// S256 returns the secp256k1 curve used by the workers
+func S256() elliptic.Curve {
+	return &secp.KoblitzCurve{
+		BitCurve: curve.S256(),
+	}
+}
+
+// IsS256 tells whether c is the secp256k1 curve
+func IsS256(c elliptic.Curve) bool {
+	params, want := c.Params(), curve.S256().Params()
+
+	return (0 == params.P.Cmp(want.P)) && (0 == params.N.Cmp(want.N)) && (0 == params.B.Cmp(want.B))
+}
+
 // New produces a default worker as New256()
 func New() *Worker {
 	return New256()
@@ -71,89 +92,221 @@ type localPrivateKey struct {
 	D      *big.Int
 }
 
-/*
-func (w *Worker) MarshalPrivKey(privKey ec.PrivateKey) ([]byte, error) {
-	priv, ok := privKey.(*ecdsa.PrivateKey)
-	if !ok {
-		return nil, ec.ErrKeyTampered
-	}
+// codecs version the blobs of the workers
+var codecs = &codec.Set{
+	PrivKey: codec.New(codecVersion, encodePrivKey, decodePrivKey),
+	PubKey:  codec.New(pubKeyVersion, encodePubKey, decodePubKey).Register(codecVersion, decodeLegacyPubKey),
+	Sig:     codec.New(codecVersion, encodeSig, decodeSig),
+}
 
+func init() {
+	codec.Register("secp256k1", codecs)
+}
 
+// MarshalPrivKey marshal privKey to []byte as version || bitSize || asn1(PubKey,D)
+func (w *Worker) MarshalPrivKey(privKey crypto.PrivateKey) ([]byte, error) {
+	return codecs.PrivKey.Marshal(privKey)
 }
-*/
+
+// MarshalPubKey marshal pubKey to []byte as version || curve || SEC1 compressed point
 func (w *Worker) MarshalPubKey(pubKey ec.PublicKey) ([]byte, error) {
-	pub, ok := pubKey.(*ecdsa.PublicKey)
-	if !ok {
+	return codecs.PubKey.Marshal(pubKey)
+}
+
+// UnmarshalPrivKey unmarshal privKeyBytes to privKey
+func (w *Worker) UnmarshalPrivKey(privKeyBytes []byte) (crypto.PrivateKey, error) {
+	return codecs.PrivKey.Unmarshal(privKeyBytes)
+}
+
+// UnmarshalPubKey unmarshal pubKeyBytes of any version to pubKey
+func (w *Worker) UnmarshalPubKey(pubKeyBytes []byte) (ec.PublicKey, error) {
+	return codecs.PubKey.Unmarshal(pubKeyBytes)
+}
+
+// MarshalSig marshal sig to []byte where byte[0] records the marshal version
+func (w *Worker) MarshalSig(sig ec.Sig) ([]byte, error) {
+	return codecs.Sig.Marshal(sig)
+}
+
+// UnmarshalSig unmarshal sigBytes to sig in the encoding of the worker
+func (w *Worker) UnmarshalSig(sigBytes []byte) (ec.Sig, error) {
+	v, err := codecs.Sig.Unmarshal(sigBytes)
+	if nil != err {
+		return nil, err
+	}
+
+	sig := v.(ec.Sig)
+	if localECDSA.EncodingP1363 == w.Encoding {
+		_, err = localECDSA.FromP1363(S256(), sig)
+	} else {
+		_, err = localECDSA.ToP1363(S256(), sig)
+	}
+	if nil != err {
+		return nil, err
+	}
+
+	return sig, nil
+}
+
+// Migrate upgrades a private key, public key or signature blob of any
+// version to the current one
+func (w *Worker) Migrate(blob []byte) ([]byte, error) {
+	return codecs.Migrate(blob)
+}
+
+// Validate checks pubKey is a point of secp256k1 other than the point at infinity
+func Validate(pubKey *PublicKey) error {
+	if (nil == pubKey) || (nil == pubKey.Curve) || !IsS256(pubKey.Curve) {
+		return ec.ErrECTypeUnsupported
+	}
+
+	return localECDSA.Validate(pubKey)
+}
+
+// encodePrivKey encodes the private keys as bitSize || asn1(PubKey,D)
+func encodePrivKey(v interface{}) ([]byte, error) {
+	priv, ok := v.(*ecdsa.PrivateKey)
+	if !ok || (nil == priv.Curve) {
 		return nil, ec.ErrKeyTampered
 	}
+	if !IsS256(priv.Curve) {
+		return nil, ec.ErrECTypeUnsupported
+	}
 
 	buf := new(bytes.Buffer)
 
-	// version
-	buf.WriteByte(byte(codecVersion))
 	// bitSize
-	bitSize := pub.Curve.Params().BitSize
+	bitSize := priv.Curve.Params().BitSize
 	buf.WriteByte(byte((bitSize >> 8) & 0xff))
 	buf.WriteByte(byte(bitSize & 0xff))
 
-	pubBytes, err := asn1.Marshal(localPublicKey{pub.X, pub.Y})
+	privBytes, err := asn1.Marshal(localPrivateKey{localPublicKey{priv.X, priv.Y}, priv.D})
 	if nil != err {
 		return nil, err
 	}
 
-	if _, err := buf.Write(pubBytes); nil != err {
+	if _, err := buf.Write(privBytes); nil != err {
 		return nil, err
 	}
 
 	return buf.Bytes(), nil
 }
 
-/*
-func (w *Worker) MarshalSig(sig Sig) ([]byte, error) {
+// encodePubKey encodes the public keys as curve || SEC1 compressed point
+func encodePubKey(v interface{}) ([]byte, error) {
+	pub, ok := v.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, ec.ErrKeyTampered
+	}
+	if err := Validate(pub); nil != err {
+		return nil, err
+	}
+
+	point, err := localECDSA.MarshalSEC1(pub, true)
+	if nil != err {
+		return nil, err
+	}
 
+	return append([]byte{ec.CurveSecp256k1}, point...), nil
 }
 
-func (w *Worker) UnmarshalPrivKey(privKeyBytes []byte) (ec.PrivateKey, error) {
+// encodeSig keeps the signature as is, in whatever encoding
+func encodeSig(v interface{}) ([]byte, error) {
+	sig, ok := v.(ec.Sig)
+	if !ok {
+		return nil, ec.ErrMalformedSig
+	}
 
+	return append([]byte(nil), sig...), nil
 }
-*/
-func (w *Worker) UnmarshalPubKey(pubKeyBytes []byte) (ec.PublicKey, error) {
-	buf := bytes.NewBuffer(pubKeyBytes)
 
-	version, err := buf.ReadByte()
-	if nil != err {
+// decodePrivKey decodes the private keys, whose scalar must lie in [1,N-1]
+// and match the public point
+func decodePrivKey(body []byte) (interface{}, error) {
+	buf := bytes.NewBuffer(body)
+
+	privKey := new(ecdsa.PrivateKey)
+	if err := updateCurve(&privKey.PublicKey, buf); nil != err {
 		return nil, err
 	}
-	if codecVersion != version {
-		return nil, ec.ErrWrongVersion
+
+	localPrivKey := new(localPrivateKey)
+	if rest, err := asn1.Unmarshal(buf.Bytes(), localPrivKey); (nil != err) || (0 != len(rest)) {
+		return nil, ec.ErrMalformedKey
 	}
 
+	privKey.X = localPrivKey.PubKey.X
+	privKey.Y = localPrivKey.PubKey.Y
+	privKey.D = localPrivKey.D
+
+	if err := Validate(&privKey.PublicKey); nil != err {
+		return nil, err
+	}
+	// the scalar must lie in [1,N-1] and match the public point
+	if (privKey.D.Sign() <= 0) || (privKey.D.Cmp(privKey.Curve.Params().N) >= 0) {
+		return nil, ec.ErrInvalidKey
+	}
+	if x, y := privKey.Curve.ScalarBaseMult(privKey.D.Bytes()); (0 != x.Cmp(privKey.X)) || (0 != y.Cmp(privKey.Y)) {
+		return nil, ec.ErrInvalidKey
+	}
+
+	return privKey, nil
+}
+
+// decodePubKey decodes the public keys tagged as secp256k1
+func decodePubKey(body []byte) (interface{}, error) {
+	if 1 > len(body) {
+		return nil, ec.ErrMalformedKey
+	}
+	if ec.CurveSecp256k1 != body[0] {
+		return nil, ec.ErrECTypeUnsupported
+	}
+
+	return localECDSA.UnmarshalSEC1(S256(), body[1:])
+}
+
+// decodeLegacyPubKey decodes the public keys of version 1, i.e.
+// bitSize || asn1(X,Y)
+func decodeLegacyPubKey(body []byte) (interface{}, error) {
+	buf := bytes.NewBuffer(body)
+
 	pubKey := new(ecdsa.PublicKey)
 	if err := updateCurve(pubKey, buf); nil != err {
 		return nil, err
 	}
 
 	localPubKey := new(localPublicKey)
-	if _, err := asn1.Unmarshal(buf.Bytes(), localPubKey); nil != err {
-		return nil, err
+	if rest, err := asn1.Unmarshal(buf.Bytes(), localPubKey); (nil != err) || (0 != len(rest)) {
+		return nil, ec.ErrMalformedKey
 	}
 
 	pubKey.X = localPubKey.X
 	pubKey.Y = localPubKey.Y
 
+	if err := Validate(pubKey); nil != err {
+		return nil, err
+	}
+
 	return pubKey, nil
 }
 
-/*
-func (w *Worker) UnmarshalSig(sigBytes []byte) (Sig, error) {
+// decodeSig decodes the signatures in either encoding, leaving the choice of
+// the encoding to the worker
+func decodeSig(body []byte) (interface{}, error) {
+	if _, err := localECDSA.ToP1363(S256(), body); nil == err {
+		return append(ec.Sig(nil), body...), nil
+	}
+	if _, err := localECDSA.FromP1363(S256(), body); nil != err {
+		return nil, err
+	}
 
+	return append(ec.Sig(nil), body...), nil
 }
-*/
 
 func updateCurve(pubKey *ecdsa.PublicKey, buf *bytes.Buffer) error {
-	bs := make([]byte, 2)
-	if n, err := buf.Read(bs); (len(bs) != n) || (nil != err) {
-		return errors.New("error in reading BitSize")
+	bs := buf.Next(2)
+	if len(bs) != 2 {
+		return ec.ErrMalformedKey
 	}
 
 	var bitSize int
@@ -166,8 +319,48 @@ func updateCurve(pubKey *ecdsa.PublicKey, buf *bytes.Buffer) error {
 			BitCurve: curve.S256(),
 		}
 	default:
-		err = errors.New("error: unsupported BitSize")
+		err = ec.ErrECTypeUnsupported
 	}
 
 	return err
 }
+
+// Verify verifies the signature in sig of hash using the public key, pubKey.
+// Its return value records whether the signature is valid.
+func (w *Worker) Verify(pubKey ec.PublicKey, digest []byte, sig ec.Sig) bool {
+	pub, ok := pubKey.(*PublicKey)
+	if !ok || (w.Strict && ((nil != Validate(pub)) || !w.isLowS(sig))) {
+		return false
+	}
+
+	// the checks of Strict are done above over secp256k1, which the embedded
+	// Worker256 would refuse as another curve than P-256
+	lenient := w.Worker256
+	lenient.Strict = false
+
+	return lenient.Verify(pubKey, digest, sig)
+}
+
+// isLowS tells whether sig in the encoding of the worker carries the low s
+func (w *Worker) isLowS(sig ec.Sig) bool {
+	rs := sig
+	if localECDSA.EncodingP1363 != w.Encoding {
+		var err error
+		if rs, err = localECDSA.ToP1363(S256(), sig); nil != err {
+			return false
+		}
+	}
+
+	size := localECDSA.P1363Size(S256())
+	return (size == len(rs)) && localECDSA.IsLowS(S256(), new(big.Int).SetBytes(rs[size/2:]))
+}
+
+// Validate checks pubKey is a valid public key of secp256k1
+func (w *Worker) Validate(pubKey ec.PublicKey) error {
+	pub, ok := pubKey.(*PublicKey)
+	if !ok {
+		return ec.ErrKeyTampered
+	}
+
+	return Validate(pub)
+}
@@ -1,9 +1,16 @@
 package secp_test
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
+	"encoding/asn1"
+	"math/big"
 	"testing"
 
+	"github.com/sammy00/gravity/crypto/ec"
+	localECDSA "github.com/sammy00/gravity/crypto/ec/ecdsa"
 	"github.com/sammy00/gravity/crypto/ec/secp"
 
 	"golang.org/x/crypto/sha3"
@@ -104,3 +111,184 @@ func TestPublicKeyCodec(t *testing.T) {
 		t.Errorf("mismatched Y: want %s, got %x\n", pub.Y, pub2.Y)
 	}
 }
+
+func TestPrivateKeyCodec(t *testing.T) {
+	w := secp.New()
+
+	priv, err := w.GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	privBytes, err := w.MarshalPrivKey(priv)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	rawPriv, err := w.UnmarshalPrivKey(privBytes)
+	if nil != err {
+		t.Fatal(err)
+	}
+	priv2 := rawPriv.(*secp.PrivateKey)
+
+	if 0 != priv.(*secp.PrivateKey).D.Cmp(priv2.D) {
+		t.Errorf("mismatched D: want %s, got %s\n", priv.(*secp.PrivateKey).D, priv2.D)
+	}
+
+	digest := sha3.Sum256([]byte("Hello World"))
+	sig, err := w.Sign(priv2, digest[:])
+	if nil != err {
+		t.Fatal(err)
+	}
+	if !w.Verify(priv.Public(), digest[:], sig) {
+		t.Fatal("the verification shouldn't fail")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	w := secp.New256()
+	priv, err := w.GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	pub := priv.(*secp.PrivateKey).PublicKey
+	if err := w.Validate(&pub); nil != err {
+		t.Fatal(err)
+	}
+
+	// a P-256 key isn't a secp256k1 key even if it's valid
+	if err := secp.Validate(&ecdsa.PublicKey{Curve: elliptic.P256(), X: pub.X, Y: pub.Y}); ec.ErrECTypeUnsupported != err {
+		t.Fatalf("want %v, got %v", ec.ErrECTypeUnsupported, err)
+	}
+
+	testCases := []struct {
+		x, y   *big.Int
+		expect error
+	}{
+		{big.NewInt(0), big.NewInt(0), ec.ErrPointAtInfinity},
+		{pub.X, new(big.Int).Add(pub.Y, big.NewInt(1)), ec.ErrNotOnCurve},
+	}
+	for i, c := range testCases {
+		if _, err := w.MarshalPubKey(&ecdsa.PublicKey{Curve: pub.Curve, X: c.x, Y: c.y}); c.expect != err {
+			t.Fatalf("#%d: want %v, got %v", i, c.expect, err)
+		}
+
+		// version 1 as version || bitSize || asn1(X,Y)
+		legacy, err := asn1.Marshal(struct{ X, Y *big.Int }{c.x, c.y})
+		if nil != err {
+			t.Fatal(err)
+		}
+		if _, err := w.UnmarshalPubKey(append([]byte{1, 0x01, 0x00}, legacy...)); c.expect != err {
+			t.Fatalf("#%d: want %v, got %v", i, c.expect, err)
+		}
+	}
+}
+
+func TestLowS(t *testing.T) {
+	w := secp.New256()
+	priv, err := w.GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	c := priv.(*secp.PrivateKey).Curve
+
+	digest := sha3.Sum256([]byte("Hello World"))
+	for i := 0; i < 16; i++ {
+		sig, err := w.Sign(priv, digest[:])
+		if nil != err {
+			t.Fatal(err)
+		}
+
+		var decoded struct{ R, S *big.Int }
+		if _, err := asn1.Unmarshal(sig, &decoded); nil != err {
+			t.Fatal(err)
+		}
+		if !localECDSA.IsLowS(c, decoded.S) {
+			t.Fatalf("#%d: the signature isn't normalized", i)
+		}
+
+		decoded.S.Sub(c.Params().N, decoded.S)
+		flipped, _ := asn1.Marshal(decoded)
+
+		w.Strict = false
+		if !w.Verify(priv.Public(), digest[:], flipped) {
+			t.Fatalf("#%d: the verification shouldn't fail", i)
+		}
+		w.Strict = true
+		if w.Verify(priv.Public(), digest[:], flipped) {
+			t.Fatalf("#%d: the verification should fail", i)
+		}
+		if !w.Verify(priv.Public(), digest[:], sig) {
+			t.Fatalf("#%d: the strict verification shouldn't fail", i)
+		}
+	}
+}
+
+func TestP1363(t *testing.T) {
+	w := secp.New256()
+	w.Encoding = localECDSA.EncodingP1363
+
+	priv, err := w.GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	digest := sha3.Sum256([]byte("Hello World"))
+	sig, err := w.Sign(priv, digest[:])
+	if nil != err {
+		t.Fatal(err)
+	}
+	if 64 != len(sig) {
+		t.Fatalf("want 64 bytes, got %d", len(sig))
+	}
+	if !w.Verify(priv.Public(), digest[:], sig) {
+		t.Fatal("the verification shouldn't fail")
+	}
+	w.Strict = true
+	if !w.Verify(priv.Public(), digest[:], sig) {
+		t.Fatal("the strict verification shouldn't fail")
+	}
+
+	data, err := w.MarshalSig(sig)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if got, err := w.UnmarshalSig(data); nil != err || !bytes.Equal(sig, got) {
+		t.Fatalf("the round trip changes the signature: %v", err)
+	}
+}
+
+func TestSEC1(t *testing.T) {
+	w := secp.New256()
+
+	for i := 0; i < 16; i++ {
+		priv, err := w.GenerateKey(rand.Reader)
+		if nil != err {
+			t.Fatal(err)
+		}
+		pub := &priv.(*secp.PrivateKey).PublicKey
+
+		data, err := w.MarshalPubKey(pub)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if (2 != data[0]) || (ec.CurveSecp256k1 != data[1]) || (35 != len(data)) {
+			t.Fatalf("#%d: unexpected layout: %x", i, data)
+		}
+
+		// the decompression of secp256k1 takes a = 0
+		got, err := w.UnmarshalPubKey(data)
+		if nil != err {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if (0 != pub.X.Cmp(got.(*secp.PublicKey).X)) || (0 != pub.Y.Cmp(got.(*secp.PublicKey).Y)) {
+			t.Fatalf("#%d: the round trip changes the key", i)
+		}
+
+		// the same point tagged as P-256 is refused
+		data[1] = ec.CurveP256
+		if _, err := w.UnmarshalPubKey(data); ec.ErrECTypeUnsupported != err {
+			t.Fatalf("#%d: want %v, got %v", i, ec.ErrECTypeUnsupported, err)
+		}
+	}
+}
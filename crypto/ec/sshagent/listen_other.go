@@ -0,0 +1,24 @@
+//go:build !unix
+
+package sshagent
+
+import (
+	"net"
+	"os"
+)
+
+// listenPrivate binds the socket then restricts it to the current user,
+// since there's no umask to create it so
+func listenPrivate(path string) (net.Listener, error) {
+	l, err := net.Listen("unix", path)
+	if nil != err {
+		return nil, err
+	}
+
+	if err := os.Chmod(path, 0600); nil != err {
+		l.Close()
+		return nil, err
+	}
+
+	return l, nil
+}
@@ -0,0 +1,57 @@
+//go:build unix
+
+package sshagent
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// privateListener reports the socket linked at addr, and removes it on Close
+type privateListener struct {
+	*net.UnixListener
+	addr *net.UnixAddr
+}
+
+func (l *privateListener) Addr() net.Addr {
+	return l.addr
+}
+
+func (l *privateListener) Close() error {
+	err := l.UnixListener.Close()
+	if nil == err {
+		os.Remove(l.addr.Name)
+	}
+
+	return err
+}
+
+// listenPrivate binds the socket inside a fresh directory of mode 0700, where
+// no one else can reach it before it's restricted to 0600, then links it at
+// path, failing as net.Listen if path exists
+func listenPrivate(path string) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".sshagent-")
+	if nil != err {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "agent.sock")
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: tmp, Net: "unix"})
+	if nil != err {
+		return nil, err
+	}
+	l.SetUnlinkOnClose(false)
+
+	if err := os.Chmod(tmp, 0600); nil != err {
+		l.Close()
+		return nil, err
+	}
+	if err := os.Link(tmp, path); nil != err {
+		l.Close()
+		return nil, err
+	}
+
+	return &privateListener{l, &net.UnixAddr{Name: path, Net: "unix"}}, nil
+}
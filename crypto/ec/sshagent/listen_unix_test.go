@@ -0,0 +1,59 @@
+//go:build unix
+
+package sshagent_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec/sshagent"
+)
+
+func TestListenPrivate(t *testing.T) {
+	// the socket is private even under a permissive umask
+	mask := syscall.Umask(0)
+	defer syscall.Umask(mask)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.sock")
+	l, err := sshagent.Listen(path)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	if fi, err := os.Stat(path); nil != err || 0600 != fi.Mode().Perm() {
+		t.Fatalf("unexpected socket permission: %v %v", fi, err)
+	}
+	if entries, err := os.ReadDir(dir); nil != err || 1 != len(entries) {
+		t.Fatalf("want the socket only, got %v %v", entries, err)
+	}
+	if path != l.Addr().String() {
+		t.Fatalf("want %s, got %s", path, l.Addr())
+	}
+
+	// the path in use is refused as by net.Listen
+	if _, err := sshagent.Listen(path); nil == err {
+		t.Fatal("the socket in use is replaced")
+	}
+
+	go func() {
+		if conn, err := l.Accept(); nil == err {
+			conn.Close()
+		}
+	}()
+	conn, err := net.Dial("unix", path)
+	if nil != err {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	if err := l.Close(); nil != err {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("the socket is left behind: %v", err)
+	}
+}
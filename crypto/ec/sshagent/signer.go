@@ -0,0 +1,152 @@
+package sshagent
+
+import (
+	stdEcdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	stdEd25519 "golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrKeyUnsupported means the key can't be used for SSH
+var ErrKeyUnsupported = errors.New("sshagent: unsupported key type")
+
+// Signer implements ssh.Signer by the gravity workers
+type Signer struct {
+	privKey ec.PrivateKey
+	pubKey  ssh.PublicKey
+	worker  ec.Worker
+	hash    func(data []byte) []byte
+}
+
+func sum256(data []byte) []byte {
+	h := sha256.Sum256(data)
+	return h[:]
+}
+
+func sum512(data []byte) []byte {
+	h := sha512.Sum512(data)
+	return h[:]
+}
+
+// NewSigner wraps a private key made by ed25519.Worker, ecdsa.Worker256 or
+// ecdsa.Worker512 as a ssh.Signer
+func NewSigner(privKey ec.PrivateKey) (*Signer, error) {
+	s := &Signer{privKey: privKey}
+
+	switch priv := privKey.(type) {
+	case ed25519.PrivateKey:
+		if len(priv.PrivateKey) != stdEd25519.PrivateKeySize {
+			return nil, ec.ErrKeyTampered
+		}
+		// ed25519 signs the data as a whole
+		s.worker = new(ed25519.Worker)
+		s.hash = func(data []byte) []byte { return data }
+	case *ecdsa.PrivateKey:
+		switch priv.Curve {
+		case elliptic.P256():
+			s.worker, s.hash = new(ecdsa.Worker256), sum256
+		case elliptic.P521():
+			s.worker, s.hash = new(ecdsa.Worker512), sum512
+		default:
+			return nil, ErrKeyUnsupported
+		}
+	default:
+		return nil, ErrKeyUnsupported
+	}
+
+	var err error
+	if s.pubKey, err = ssh.NewPublicKey(privKey.Public()); nil != err {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// fromStandard converts the keys decoded by the agent protocol
+func fromStandard(key interface{}) (ec.PrivateKey, error) {
+	switch priv := key.(type) {
+	case *stdEcdsa.PrivateKey:
+		return priv, nil
+	case stdEd25519.PrivateKey:
+		return ed25519.PrivateKey{PrivateKey: priv, PublicKey: priv.Public().(ed25519.PublicKey)}, nil
+	case *stdEd25519.PrivateKey:
+		return fromStandard(*priv)
+	}
+
+	return nil, ErrKeyUnsupported
+}
+
+// PublicKey returns the SSH public key
+func (s *Signer) PublicKey() ssh.PublicKey {
+	return s.pubKey
+}
+
+// Sign signs data by the worker, rand is ignored as the workers draw their own entropy
+func (s *Signer) Sign(rand io.Reader, data []byte) (*ssh.Signature, error) {
+	sig, err := s.worker.Sign(s.privKey, s.hash(data))
+	if nil != err {
+		return nil, err
+	}
+
+	blob, err := ToWire(s.pubKey.Type(), sig)
+	if nil != err {
+		return nil, err
+	}
+
+	return &ssh.Signature{Format: s.pubKey.Type(), Blob: blob}, nil
+}
+
+// asn1Sig is the (r,s) pair encoded by the ecdsa worker
+type asn1Sig struct {
+	R, S *big.Int
+}
+
+// ToWire converts a signature made by the workers into the SSH signature
+// blob of keyType
+func ToWire(keyType string, sig ec.Sig) ([]byte, error) {
+	switch keyType {
+	case ssh.KeyAlgoED25519:
+		if len(sig) != stdEd25519.SignatureSize {
+			return nil, errors.New("sshagent: malformed ed25519 signature")
+		}
+		return sig, nil
+	case ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA521:
+		var decoded asn1Sig
+		if rest, err := asn1.Unmarshal(sig, &decoded); nil != err {
+			return nil, err
+		} else if 0 != len(rest) {
+			return nil, errors.New("sshagent: trailing data after ecdsa signature")
+		}
+		// the blob is the pair of mpint r and s
+		return ssh.Marshal(decoded), nil
+	}
+
+	return nil, ErrKeyUnsupported
+}
+
+// FromWire converts a SSH signature blob of keyType back into the form
+// verified by the workers
+func FromWire(keyType string, blob []byte) (ec.Sig, error) {
+	switch keyType {
+	case ssh.KeyAlgoED25519:
+		return blob, nil
+	case ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA521:
+		var decoded asn1Sig
+		if err := ssh.Unmarshal(blob, &decoded); nil != err {
+			return nil, err
+		}
+		return asn1.Marshal(decoded)
+	}
+
+	return nil, ErrKeyUnsupported
+}
@@ -0,0 +1,278 @@
+// Package sshagent implements an ssh-agent (draft-miller-ssh-agent) serving
+// the ed25519 and ecdsa keys of gravity
+package sshagent
+
+// Note:
+// + the wire protocol is handled by golang.org/x/crypto/ssh/agent, while
+//   signing is done by the gravity workers, whose signatures are converted
+//   into the SSH wire format
+// + only ssh-ed25519, ecdsa-sha2-nistp256 and ecdsa-sha2-nistp521 keys are supported
+// + on unix, the socket of Listen is bound inside a fresh directory of mode
+//   0700 and restricted to 0600 before being linked at its path, leaving no
+//   window for others to connect
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+var (
+	// ErrLocked means the agent is locked
+	ErrLocked = errors.New("sshagent: agent is locked")
+	// ErrWrongPassphrase means the passphrase doesn't unlock the agent
+	ErrWrongPassphrase = errors.New("sshagent: incorrect passphrase")
+	// ErrNotFound means the requested key isn't held by the agent
+	ErrNotFound = errors.New("sshagent: key not found")
+	// ErrConfirmUnsupported means the agent can't prompt for confirmation
+	ErrConfirmUnsupported = errors.New("sshagent: confirmation constraint unsupported")
+)
+
+type identity struct {
+	signer  *Signer
+	comment string
+	expire  time.Time
+}
+
+func (id *identity) expired(now time.Time) bool {
+	return !id.expire.IsZero() && now.After(id.expire)
+}
+
+// Agent holds the keys and implements agent.Agent
+type Agent struct {
+	mtx        sync.Mutex
+	keys       []*identity
+	locked     bool
+	passphrase []byte
+}
+
+// New makes an empty agent
+func New() *Agent {
+	return new(Agent)
+}
+
+// AddKey adds a private key generated by the ed25519 or ecdsa workers
+func (a *Agent) AddKey(privKey ec.PrivateKey, comment string) error {
+	signer, err := NewSigner(privKey)
+	if nil != err {
+		return err
+	}
+
+	return a.add(&identity{signer: signer, comment: comment})
+}
+
+func (a *Agent) add(id *identity) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if a.locked {
+		return ErrLocked
+	}
+
+	// replace the key already held
+	blob := id.signer.pubKey.Marshal()
+	for i, k := range a.keys {
+		if bytes.Equal(blob, k.signer.pubKey.Marshal()) {
+			a.keys[i] = id
+			return nil
+		}
+	}
+	a.keys = append(a.keys, id)
+
+	return nil
+}
+
+// expireLocked drops the keys whose lifetime is over, and must be called with mtx held
+func (a *Agent) expireLocked() {
+	now := time.Now()
+
+	keys := a.keys[:0]
+	for _, k := range a.keys {
+		if !k.expired(now) {
+			keys = append(keys, k)
+		}
+	}
+	a.keys = keys
+}
+
+// List returns the identities known to the agent.
+func (a *Agent) List() ([]*agent.Key, error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if a.locked {
+		return nil, nil
+	}
+	a.expireLocked()
+
+	keys := make([]*agent.Key, 0, len(a.keys))
+	for _, k := range a.keys {
+		pub := k.signer.pubKey
+		keys = append(keys, &agent.Key{Format: pub.Type(), Blob: pub.Marshal(), Comment: k.comment})
+	}
+
+	return keys, nil
+}
+
+// Sign signs data with the key matching pubKey
+func (a *Agent) Sign(pubKey ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	a.mtx.Lock()
+	if a.locked {
+		a.mtx.Unlock()
+		return nil, ErrLocked
+	}
+	a.expireLocked()
+
+	var signer *Signer
+	blob := pubKey.Marshal()
+	for _, k := range a.keys {
+		if bytes.Equal(blob, k.signer.pubKey.Marshal()) {
+			signer = k.signer
+			break
+		}
+	}
+	a.mtx.Unlock()
+
+	if nil == signer {
+		return nil, ErrNotFound
+	}
+
+	return signer.Sign(rand.Reader, data)
+}
+
+// Add adds a private key sent by a client
+func (a *Agent) Add(key agent.AddedKey) error {
+	if key.ConfirmBeforeUse {
+		return ErrConfirmUnsupported
+	}
+
+	privKey, err := fromStandard(key.PrivateKey)
+	if nil != err {
+		return err
+	}
+
+	signer, err := NewSigner(privKey)
+	if nil != err {
+		return err
+	}
+
+	id := &identity{signer: signer, comment: key.Comment}
+	if 0 != key.LifetimeSecs {
+		id.expire = time.Now().Add(time.Duration(key.LifetimeSecs) * time.Second)
+	}
+
+	return a.add(id)
+}
+
+// Remove removes the identity with the given public key.
+func (a *Agent) Remove(pubKey ssh.PublicKey) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if a.locked {
+		return ErrLocked
+	}
+
+	blob := pubKey.Marshal()
+	for i, k := range a.keys {
+		if bytes.Equal(blob, k.signer.pubKey.Marshal()) {
+			a.keys = append(a.keys[:i], a.keys[i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrNotFound
+}
+
+// RemoveAll removes all identities.
+func (a *Agent) RemoveAll() error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if a.locked {
+		return ErrLocked
+	}
+	a.keys = nil
+
+	return nil
+}
+
+// Lock locks the agent with passphrase
+func (a *Agent) Lock(passphrase []byte) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if a.locked {
+		return ErrLocked
+	}
+	a.locked = true
+	a.passphrase = append([]byte(nil), passphrase...)
+
+	return nil
+}
+
+// Unlock undoes the effect of Lock
+func (a *Agent) Unlock(passphrase []byte) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if !a.locked {
+		return errors.New("sshagent: agent is not locked")
+	}
+	if 1 != subtle.ConstantTimeCompare(a.passphrase, passphrase) {
+		return ErrWrongPassphrase
+	}
+	a.locked = false
+	a.passphrase = nil
+
+	return nil
+}
+
+// Signers returns signers for all the known keys.
+func (a *Agent) Signers() ([]ssh.Signer, error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if a.locked {
+		return nil, ErrLocked
+	}
+	a.expireLocked()
+
+	signers := make([]ssh.Signer, 0, len(a.keys))
+	for _, k := range a.keys {
+		signers = append(signers, k.signer)
+	}
+
+	return signers, nil
+}
+
+// ServeConn serves the agent protocol on conn until it is closed
+func (a *Agent) ServeConn(conn net.Conn) error {
+	defer conn.Close()
+	return agent.ServeAgent(a, conn)
+}
+
+// Serve accepts connections on l and serves each of them
+func (a *Agent) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if nil != err {
+			return err
+		}
+		go a.ServeConn(conn)
+	}
+}
+
+// Listen creates a Unix socket at path accessible only by the current user,
+// to be exported as SSH_AUTH_SOCK
+func Listen(path string) (net.Listener, error) {
+	return listenPrivate(path)
+}
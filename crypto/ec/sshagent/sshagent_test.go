@@ -0,0 +1,199 @@
+package sshagent_test
+
+import (
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	"github.com/sammy00/gravity/crypto/ec/sshagent"
+	stdEd25519 "golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// socketPair connects a client to the agent served on the other end
+func socketPair(t *testing.T, a *sshagent.Agent) agent.ExtendedAgent {
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM, 0)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	conns := make([]net.Conn, 2)
+	for i, fd := range fds {
+		f := os.NewFile(uintptr(fd), "agent")
+		if conns[i], err = net.FileConn(f); nil != err {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+	t.Cleanup(func() { conns[1].Close() })
+
+	go a.ServeConn(conns[0])
+
+	return agent.NewClient(conns[1])
+}
+
+func generate(t *testing.T, worker ec.Worker) ec.PrivateKey {
+	priv, err := worker.GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	return priv
+}
+
+func TestListAndSign(t *testing.T) {
+	a := sshagent.New()
+	client := socketPair(t, a)
+
+	workers := []ec.Worker{new(ed25519.Worker), new(ecdsa.Worker256), new(ecdsa.Worker512)}
+	for i, w := range workers {
+		if err := a.AddKey(generate(t, w), "key"); nil != err {
+			t.Fatalf("#%d: %v", i, err)
+		}
+	}
+
+	keys, err := client.List()
+	if nil != err {
+		t.Fatal(err)
+	}
+	if len(keys) != len(workers) {
+		t.Fatalf("want %d keys, got %d", len(workers), len(keys))
+	}
+
+	data := []byte("Hello World")
+	for _, k := range keys {
+		sig, err := client.Sign(k, data)
+		if nil != err {
+			t.Fatalf("%s: %v", k.Format, err)
+		}
+		if err := k.Verify(data, sig); nil != err {
+			t.Fatalf("%s: %v", k.Format, err)
+		}
+
+		// corrupted data
+		if nil == k.Verify([]byte("Hello Gravity"), sig) {
+			t.Fatalf("%s: the verification should fail", k.Format)
+		}
+	}
+}
+
+func TestAddAndRemove(t *testing.T) {
+	a := sshagent.New()
+	client := socketPair(t, a)
+
+	_, priv, err := stdEd25519.GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if err := client.Add(agent.AddedKey{PrivateKey: priv, Comment: "added"}); nil != err {
+		t.Fatal(err)
+	}
+
+	keys, err := client.List()
+	if nil != err {
+		t.Fatal(err)
+	}
+	if 1 != len(keys) || "added" != keys[0].Comment {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+
+	// the signature made by the agent is verified by the gravity worker
+	data := []byte("Hello World")
+	sig, err := client.Sign(keys[0], data)
+	if nil != err {
+		t.Fatal(err)
+	}
+	decoded, err := sshagent.FromWire(sig.Format, sig.Blob)
+	if nil != err {
+		t.Fatal(err)
+	}
+	pub := ed25519.PublicKey(priv.Public().(stdEd25519.PublicKey))
+	if !new(ed25519.Worker).Verify(pub, data, decoded) {
+		t.Fatal("the verification shouldn't fail")
+	}
+
+	if err := client.Remove(keys[0]); nil != err {
+		t.Fatal(err)
+	}
+	if keys, _ = client.List(); 0 != len(keys) {
+		t.Fatalf("want no keys, got %d", len(keys))
+	}
+
+	if err := client.Add(agent.AddedKey{PrivateKey: priv, ConfirmBeforeUse: true}); nil == err {
+		t.Fatal("confirmation constraint should be rejected")
+	}
+}
+
+func TestLock(t *testing.T) {
+	a := sshagent.New()
+	client := socketPair(t, a)
+
+	if err := a.AddKey(generate(t, new(ecdsa.Worker256)), "locked"); nil != err {
+		t.Fatal(err)
+	}
+	keys, err := client.List()
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	if err := client.Lock([]byte("secret")); nil != err {
+		t.Fatal(err)
+	}
+	if locked, _ := client.List(); 0 != len(locked) {
+		t.Fatal("a locked agent should list no keys")
+	}
+	if _, err := client.Sign(keys[0], []byte("Hello World")); nil == err {
+		t.Fatal("a locked agent shouldn't sign")
+	}
+	if err := client.Unlock([]byte("wrong")); nil == err {
+		t.Fatal("a wrong passphrase shouldn't unlock")
+	}
+
+	if err := client.Unlock([]byte("secret")); nil != err {
+		t.Fatal(err)
+	}
+	if _, err := client.Sign(keys[0], []byte("Hello World")); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func TestUnixSocket(t *testing.T) {
+	a := sshagent.New()
+	if err := a.AddKey(generate(t, new(ed25519.Worker)), "sock"); nil != err {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	l, err := sshagent.Listen(path)
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go a.Serve(l)
+
+	if fi, err := os.Stat(path); nil != err || 0600 != fi.Mode().Perm() {
+		t.Fatalf("unexpected socket permission: %v %v", fi, err)
+	}
+
+	conn, err := net.Dial("unix", path)
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// the signers are what ssh.PublicKeysCallback hands to a SSH handshake
+	signers, err := agent.NewClient(conn).Signers()
+	if nil != err {
+		t.Fatal(err)
+	}
+	if 1 != len(signers) || ssh.KeyAlgoED25519 != signers[0].PublicKey().Type() {
+		t.Fatalf("unexpected signers %v", signers)
+	}
+}
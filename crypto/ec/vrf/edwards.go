@@ -0,0 +1,170 @@
+package vrf
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+
+	"filippo.io/edwards25519"
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+)
+
+// the byte sizes of the encodings of the edwards suites
+const (
+	edwardsPointSize  = 32
+	edwardsScalarSize = 32
+	edwardsProofSize  = edwardsPointSize + cLen + edwardsScalarSize
+)
+
+// edwardsSuite implements the suites over edwards25519, which differ in how
+// they encode alpha to the curve
+type edwardsSuite struct {
+	id            byte
+	encodeToCurve func(suite byte, salt, alpha []byte) (*edwards25519.Point, error)
+}
+
+// Prove computes the proof of alpha by privKey, an ed25519.PrivateKey
+func (suite *edwardsSuite) Prove(privKey ec.PrivateKey, alpha []byte) ([]byte, error) {
+	priv, ok := privKey.(ed25519.PrivateKey)
+	if !ok {
+		return nil, ec.ErrKeyTampered
+	}
+
+	// the secret scalar and the nonce prefix are derived as RFC 8032
+	x, prefix, err := ed25519.Expand(priv)
+	if nil != err {
+		return nil, err
+	}
+	pub := priv.PrivateKey[32:]
+
+	H, err := suite.encodeToCurve(suite.id, pub, alpha)
+	if nil != err {
+		return nil, err
+	}
+	hString := H.Bytes()
+	gamma := new(edwards25519.Point).ScalarMult(x, H)
+
+	kString := sha512.Sum512(append(append([]byte(nil), prefix...), hString...))
+	k, err := edwards25519.NewScalar().SetUniformBytes(kString[:])
+	if nil != err {
+		return nil, err
+	}
+
+	U := new(edwards25519.Point).ScalarBaseMult(k)
+	V := new(edwards25519.Point).ScalarMult(k, H)
+	c := challenge(sha512.New(), suite.id, pub, hString, gamma.Bytes(), U.Bytes(), V.Bytes())
+
+	cScalar, err := edwardsChallenge(c)
+	if nil != err {
+		return nil, err
+	}
+	s := edwards25519.NewScalar().MultiplyAdd(cScalar, x, k)
+
+	proof := make([]byte, 0, edwardsProofSize)
+	proof = append(proof, gamma.Bytes()...)
+	proof = append(proof, c...)
+	proof = append(proof, s.Bytes()...)
+
+	return proof, nil
+}
+
+// Verify checks proof of alpha by pubKey, an ed25519.PublicKey, returning
+// its output beta
+func (suite *edwardsSuite) Verify(pubKey ec.PublicKey, alpha, proof []byte) ([]byte, error) {
+	pub, ok := pubKey.(ed25519.PublicKey)
+	if !ok {
+		return nil, ec.ErrKeyTampered
+	}
+	if err := ed25519.Validate(pub); nil != err {
+		return nil, err
+	}
+	Y, err := new(edwards25519.Point).SetBytes(pub)
+	if nil != err {
+		return nil, ec.ErrNotOnCurve
+	}
+
+	gamma, c, s, err := decodeEdwardsProof(proof)
+	if nil != err {
+		return nil, err
+	}
+	cScalar, err := edwardsChallenge(c)
+	if nil != err {
+		return nil, err
+	}
+
+	H, err := suite.encodeToCurve(suite.id, pub, alpha)
+	if nil != err {
+		return nil, err
+	}
+
+	// U = s*B - c*Y and V = s*H - c*Gamma
+	negC := edwards25519.NewScalar().Negate(cScalar)
+	U := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(negC, Y, s)
+	V := new(edwards25519.Point).VarTimeMultiScalarMult(
+		[]*edwards25519.Scalar{s, negC}, []*edwards25519.Point{H, gamma})
+
+	want := challenge(sha512.New(), suite.id, pub, H.Bytes(), proof[:edwardsPointSize], U.Bytes(), V.Bytes())
+	if 1 != subtle.ConstantTimeCompare(want, c) {
+		return nil, ErrInvalidProof
+	}
+
+	return suite.gammaToHash(gamma), nil
+}
+
+// ProofToHash computes the output beta of proof
+func (suite *edwardsSuite) ProofToHash(proof []byte) ([]byte, error) {
+	gamma, _, _, err := decodeEdwardsProof(proof)
+	if nil != err {
+		return nil, err
+	}
+
+	return suite.gammaToHash(gamma), nil
+}
+
+func (suite *edwardsSuite) gammaToHash(gamma *edwards25519.Point) []byte {
+	return beta(sha512.New(), suite.id, new(edwards25519.Point).MultByCofactor(gamma).Bytes())
+}
+
+// decodeEdwardsProof splits proof into Gamma, the challenge string c and s
+func decodeEdwardsProof(proof []byte) (*edwards25519.Point, []byte, *edwards25519.Scalar, error) {
+	if edwardsProofSize != len(proof) {
+		return nil, nil, nil, ec.ErrMalformedSig
+	}
+
+	gamma, ok := ed25519.DecodePoint(proof[:edwardsPointSize])
+	if !ok {
+		return nil, nil, nil, ErrInvalidProof
+	}
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(proof[edwardsPointSize+cLen:])
+	if nil != err {
+		return nil, nil, nil, ErrInvalidProof
+	}
+
+	return gamma, proof[edwardsPointSize : edwardsPointSize+cLen], s, nil
+}
+
+// edwardsChallenge decodes the little-endian challenge string c
+func edwardsChallenge(c []byte) (*edwards25519.Scalar, error) {
+	var buf [edwardsScalarSize]byte
+	copy(buf[:], c)
+
+	return edwards25519.NewScalar().SetCanonicalBytes(buf[:])
+}
+
+// encodeToCurveTAI hashes salt and alpha with a counter until the first 32
+// bytes of the digest decode to a point, then clears its cofactor
+func encodeToCurveTAI(suite byte, salt, alpha []byte) (*edwards25519.Point, error) {
+	for ctr := 0; ctr < 256; ctr++ {
+		h := sha512.New()
+		h.Write([]byte{suite, domainEncode})
+		h.Write(salt)
+		h.Write(alpha)
+		h.Write([]byte{byte(ctr), domainBack})
+
+		if p, ok := ed25519.DecodePoint(h.Sum(nil)[:edwardsPointSize]); ok {
+			return p.MultByCofactor(p), nil
+		}
+	}
+
+	return nil, ErrEncodeToCurve
+}
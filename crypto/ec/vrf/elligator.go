@@ -0,0 +1,152 @@
+package vrf
+
+// Note:
+// + the ELL2 suite encodes alpha by edwards25519_XMD:SHA-512_ELL2_NU_ of
+//   RFC 9380, i.e. one field element hashed by expand_message_xmd, mapped by
+//   Elligator2 onto curve25519 and then by the birational map onto
+//   edwards25519, whose cofactor is finally cleared
+
+import (
+	"crypto/sha512"
+
+	"filippo.io/edwards25519"
+	"filippo.io/edwards25519/field"
+)
+
+// the domain separation tag of the ELL2 suite, followed by the suite string
+const ell2DST = "ECVRF_edwards25519_XMD:SHA-512_ELL2_NU_"
+
+// the byte size of the hashed field elements, i.e. ceil((255+128)/8)
+const ell2HashSize = 48
+
+// the constants of curve25519 and its map
+var (
+	feOne     = new(field.Element).One()
+	montA     = new(field.Element).Mult32(feOne, 486662)
+	edwardsC1 = mustSqrt(new(field.Element).Negate(new(field.Element).Mult32(feOne, 486664)))
+)
+
+// encodeToCurveELL2 hashes salt || alpha onto edwards25519 non-uniformly
+func encodeToCurveELL2(suite byte, salt, alpha []byte) (*edwards25519.Point, error) {
+	msg := append(append([]byte(nil), salt...), alpha...)
+	uniform := expandMessageXMD(msg, append([]byte(ell2DST), suite), ell2HashSize)
+
+	// the big-endian string is reduced as a little-endian wide one
+	var wide [64]byte
+	for i, b := range uniform {
+		wide[len(uniform)-1-i] = b
+	}
+	u, err := new(field.Element).SetWideBytes(wide[:])
+	if nil != err {
+		return nil, err
+	}
+
+	p, err := mapToEdwards(mapToCurve25519(u))
+	if nil != err {
+		return nil, err
+	}
+
+	return p.MultByCofactor(p), nil
+}
+
+// expandMessageXMD implements expand_message_xmd of RFC 9380 with SHA-512,
+// for outputs no longer than 255 blocks
+func expandMessageXMD(msg, dst []byte, size int) []byte {
+	dstPrime := append(append([]byte(nil), dst...), byte(len(dst)))
+
+	h := sha512.New()
+	h.Write(make([]byte, h.BlockSize()))
+	h.Write(msg)
+	h.Write([]byte{byte(size >> 8), byte(size), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	var out, bi []byte
+	for i := 1; len(out) < size; i++ {
+		// b_i = H(strxor(b_0, b_(i-1)) || i || DST_prime)
+		chain := make([]byte, len(b0))
+		for j := range chain {
+			chain[j] = b0[j]
+			if nil != bi {
+				chain[j] ^= bi[j]
+			}
+		}
+
+		h.Reset()
+		h.Write(chain)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+
+		out = append(out, bi...)
+	}
+
+	return out[:size]
+}
+
+// mapToCurve25519 maps u to the point (s, t) of curve25519 by Elligator2
+// with Z = 2
+func mapToCurve25519(u *field.Element) (*field.Element, *field.Element) {
+	// x1 = -A / (1 + 2u^2), which is -A if the denominator is zero
+	tv := new(field.Element).Square(u)
+	tv.Add(tv, tv)
+	x1 := new(field.Element).Add(feOne, tv)
+	x1.Invert(x1)
+	x1.Multiply(x1, new(field.Element).Negate(montA))
+	x1.Select(new(field.Element).Negate(montA), x1, x1.Equal(new(field.Element).Zero()))
+
+	// x2 = -x1 - A
+	x2 := new(field.Element).Negate(x1)
+	x2.Subtract(x2, montA)
+
+	y1, isSquare := new(field.Element).SqrtRatio(montgomeryRHS(x1), feOne)
+	y2, _ := new(field.Element).SqrtRatio(montgomeryRHS(x2), feOne)
+
+	// y takes the sign 1 with x1 and 0 with x2, while SqrtRatio returns the
+	// non-negative root
+	y1.Negate(y1)
+
+	s := new(field.Element).Select(x1, x2, isSquare)
+	t := new(field.Element).Select(y1, y2, isSquare)
+
+	return s, t
+}
+
+// montgomeryRHS computes x^3 + Ax^2 + x
+func montgomeryRHS(x *field.Element) *field.Element {
+	out := new(field.Element).Add(x, montA)
+	out.Multiply(out, x)
+	out.Add(out, feOne)
+
+	return out.Multiply(out, x)
+}
+
+// mapToEdwards maps the point (s, t) of curve25519 to edwards25519 as
+// (c1*s/t, (s-1)/(s+1)), sending the exceptional points to the identity
+func mapToEdwards(s, t *field.Element) (*edwards25519.Point, error) {
+	sPlus1 := new(field.Element).Add(s, feOne)
+	inv := new(field.Element).Multiply(t, sPlus1)
+	inv.Invert(inv)
+	exceptional := inv.Equal(new(field.Element).Zero())
+
+	x := new(field.Element).Multiply(edwardsC1, s)
+	x.Multiply(x, sPlus1)
+	x.Multiply(x, inv)
+
+	y := new(field.Element).Subtract(s, feOne)
+	y.Multiply(y, t)
+	y.Multiply(y, inv)
+	y.Select(feOne, y, exceptional)
+
+	return new(edwards25519.Point).SetExtendedCoordinates(x, y, feOne, new(field.Element).Multiply(x, y))
+}
+
+// mustSqrt computes the non-negative square root of the square x
+func mustSqrt(x *field.Element) *field.Element {
+	r, isSquare := new(field.Element).SqrtRatio(x, feOne)
+	if 1 != isSquare {
+		panic("vrf: not a square")
+	}
+
+	return r
+}
@@ -0,0 +1,205 @@
+package vrf
+
+import (
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/subtle"
+	"math/big"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+)
+
+// the suite string of ECVRF-P256-SHA256-TAI
+const p256SuiteID = 0x01
+
+// the byte sizes of the encodings of the P-256 suite
+const (
+	p256PointSize  = 33
+	p256ScalarSize = 32
+	p256ProofSize  = p256PointSize + cLen + p256ScalarSize
+)
+
+// p256Suite implements ECVRF-P256-SHA256-TAI, whose points are compressed
+// as SEC1 and whose nonces are generated as RFC 6979
+type p256Suite struct{}
+
+// Prove computes the proof of alpha by privKey, an ecdsa.PrivateKey of P-256
+func (suite *p256Suite) Prove(privKey ec.PrivateKey, alpha []byte) ([]byte, error) {
+	priv, ok := privKey.(*ecdsa.PrivateKey)
+	if !ok || (elliptic.P256() != priv.Curve) || (nil == priv.D) {
+		return nil, ec.ErrKeyTampered
+	}
+	c := priv.Curve
+	n := c.Params().N
+
+	pub, err := ecdsa.MarshalSEC1(&priv.PublicKey, true)
+	if nil != err {
+		return nil, err
+	}
+
+	H, err := encodeToCurveP256(pub, alpha)
+	if nil != err {
+		return nil, err
+	}
+	hString, err := ecdsa.MarshalSEC1(H, true)
+	if nil != err {
+		return nil, err
+	}
+
+	d := priv.D.Bytes()
+	gamma := scalarMultP256(H, d)
+
+	digest := sha256.Sum256(hString)
+	k := nonceRFC6979(n, priv.D, digest[:]).Bytes()
+
+	U := scalarMultP256(nil, k)
+	V := scalarMultP256(H, k)
+
+	points, err := marshalP256(gamma, U, V)
+	if nil != err {
+		return nil, err
+	}
+	cString := challenge(sha256.New(), p256SuiteID, pub, hString, points[0], points[1], points[2])
+
+	// s = k + c*x mod n
+	s := new(big.Int).SetBytes(cString)
+	s.Mul(s, priv.D)
+	s.Add(s, new(big.Int).SetBytes(k))
+	s.Mod(s, n)
+
+	proof := make([]byte, p256ProofSize)
+	copy(proof, points[0])
+	copy(proof[p256PointSize:], cString)
+	s.FillBytes(proof[p256PointSize+cLen:])
+
+	return proof, nil
+}
+
+// Verify checks proof of alpha by pubKey, an ecdsa.PublicKey of P-256,
+// returning its output beta
+func (suite *p256Suite) Verify(pubKey ec.PublicKey, alpha, proof []byte) ([]byte, error) {
+	Y, ok := pubKey.(*ecdsa.PublicKey)
+	if !ok || (nil == Y) || (elliptic.P256() != Y.Curve) {
+		return nil, ec.ErrKeyTampered
+	}
+	pub, err := ecdsa.MarshalSEC1(Y, true)
+	if nil != err {
+		return nil, err
+	}
+
+	gamma, c, s, err := decodeP256Proof(proof)
+	if nil != err {
+		return nil, err
+	}
+
+	H, err := encodeToCurveP256(pub, alpha)
+	if nil != err {
+		return nil, err
+	}
+	hString, err := ecdsa.MarshalSEC1(H, true)
+	if nil != err {
+		return nil, err
+	}
+
+	// U = s*B - c*Y and V = s*H - c*Gamma
+	negC := new(big.Int).SetBytes(c)
+	negC.Sub(elliptic.P256().Params().N, negC)
+
+	U := addP256(scalarMultP256(nil, s), scalarMultP256(Y, negC.Bytes()))
+	V := addP256(scalarMultP256(H, s), scalarMultP256(gamma, negC.Bytes()))
+
+	// U or V at infinity can't be encoded, which no valid proof leads to
+	points, err := marshalP256(U, V)
+	if nil != err {
+		return nil, ErrInvalidProof
+	}
+
+	want := challenge(sha256.New(), p256SuiteID, pub, hString, proof[:p256PointSize], points[0], points[1])
+	if 1 != subtle.ConstantTimeCompare(want, c) {
+		return nil, ErrInvalidProof
+	}
+
+	return beta(sha256.New(), p256SuiteID, proof[:p256PointSize]), nil
+}
+
+// ProofToHash computes the output beta of proof
+func (suite *p256Suite) ProofToHash(proof []byte) ([]byte, error) {
+	if _, _, _, err := decodeP256Proof(proof); nil != err {
+		return nil, err
+	}
+
+	// the cofactor of P-256 is 1, so Gamma is hashed as is
+	return beta(sha256.New(), p256SuiteID, proof[:p256PointSize]), nil
+}
+
+// decodeP256Proof splits proof into Gamma, the challenge string c and the
+// big-endian s
+func decodeP256Proof(proof []byte) (*ecdsa.PublicKey, []byte, []byte, error) {
+	if p256ProofSize != len(proof) {
+		return nil, nil, nil, ec.ErrMalformedSig
+	}
+
+	gamma, err := ecdsa.UnmarshalSEC1(elliptic.P256(), proof[:p256PointSize])
+	if nil != err {
+		return nil, nil, nil, ErrInvalidProof
+	}
+
+	s := proof[p256PointSize+cLen:]
+	if new(big.Int).SetBytes(s).Cmp(elliptic.P256().Params().N) >= 0 {
+		return nil, nil, nil, ErrInvalidProof
+	}
+
+	return gamma, proof[p256PointSize : p256PointSize+cLen], s, nil
+}
+
+// encodeToCurveP256 hashes salt and alpha with a counter until the digest
+// is the x-coordinate of a point, taken with an even y
+func encodeToCurveP256(salt, alpha []byte) (*ecdsa.PublicKey, error) {
+	H, err := ecdsa.HashToPoint(elliptic.P256(), func(ctr int) []byte {
+		h := sha256.New()
+		h.Write([]byte{p256SuiteID, domainEncode})
+		h.Write(salt)
+		h.Write(alpha)
+		h.Write([]byte{byte(ctr), domainBack})
+
+		return h.Sum(nil)
+	})
+	if nil != err {
+		return nil, ErrEncodeToCurve
+	}
+
+	return H, nil
+}
+
+// scalarMultP256 computes k*p, or k*B if p is nil
+func scalarMultP256(p *ecdsa.PublicKey, k []byte) *ecdsa.PublicKey {
+	out := &ecdsa.PublicKey{Curve: elliptic.P256()}
+	if nil == p {
+		out.X, out.Y = out.Curve.ScalarBaseMult(k)
+	} else {
+		out.X, out.Y = out.Curve.ScalarMult(p.X, p.Y, k)
+	}
+
+	return out
+}
+
+func addP256(p, q *ecdsa.PublicKey) *ecdsa.PublicKey {
+	out := &ecdsa.PublicKey{Curve: elliptic.P256()}
+	out.X, out.Y = out.Curve.Add(p.X, p.Y, q.X, q.Y)
+
+	return out
+}
+
+// marshalP256 compresses the points, failing on the point at infinity
+func marshalP256(points ...*ecdsa.PublicKey) ([][]byte, error) {
+	out := make([][]byte, len(points))
+	for i, p := range points {
+		var err error
+		if out[i], err = ecdsa.MarshalSEC1(p, true); nil != err {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,65 @@
+package vrf
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"math/big"
+)
+
+// nonceRFC6979 generates the nonce of the secret x for the digest h1 as the
+// section 3.2 of RFC 6979 with HMAC-SHA256
+func nonceRFC6979(n, x *big.Int, h1 []byte) *big.Int {
+	qLen := n.BitLen()
+	rLen := (qLen + 7) / 8
+
+	// bits2octets(h1) = int2octets(bits2int(h1) mod n)
+	h := bits2int(h1, qLen)
+	if h.Cmp(n) >= 0 {
+		h.Sub(h, n)
+	}
+	seed := append(x.FillBytes(make([]byte, rLen)), h.FillBytes(make([]byte, rLen))...)
+
+	V := make([]byte, sha256.Size)
+	for i := range V {
+		V[i] = 0x01
+	}
+	K := make([]byte, sha256.Size)
+
+	mac := func(key []byte, data ...[]byte) []byte {
+		m := hmac.New(sha256.New, key)
+		for _, d := range data {
+			m.Write(d)
+		}
+		return m.Sum(nil)
+	}
+
+	K = mac(K, V, []byte{0x00}, seed)
+	V = mac(K, V)
+	K = mac(K, V, []byte{0x01}, seed)
+	V = mac(K, V)
+
+	for {
+		var T []byte
+		for len(T) < rLen {
+			V = mac(K, V)
+			T = append(T, V...)
+		}
+
+		if k := bits2int(T, qLen); (k.Sign() > 0) && (k.Cmp(n) < 0) {
+			return k
+		}
+
+		K = mac(K, V, []byte{0x00})
+		V = mac(K, V)
+	}
+}
+
+// bits2int takes the leftmost qLen bits of b as an integer
+func bits2int(b []byte, qLen int) *big.Int {
+	out := new(big.Int).SetBytes(b)
+	if excess := len(b)*8 - qLen; excess > 0 {
+		out.Rsh(out, uint(excess))
+	}
+
+	return out
+}
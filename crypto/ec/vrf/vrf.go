@@ -0,0 +1,82 @@
+// Package vrf implements the elliptic curve verifiable random functions of
+// RFC 9381 (ECVRF) on top of the keys of the ed25519 and ecdsa packages
+package vrf
+
+// Note:
+// + a proof pi is point(Gamma) || c || s, where c is truncated to 16 bytes,
+//   and its output beta hashes cofactor*Gamma, so any valid proof of alpha by
+//   a key yields the same beta
+// + Verify always validates the public key, which ensures the uniqueness of
+//   beta against keys of small order
+// + the suites encode alpha to the curve either by try-and-increment (TAI),
+//   which isn't constant time, or by the Elligator2 map of RFC 9380 (ELL2)
+
+import (
+	"errors"
+	"hash"
+
+	"github.com/sammy00/gravity/crypto/ec"
+)
+
+var (
+	// ErrInvalidProof indicates the proof isn't made by the key for alpha
+	ErrInvalidProof = errors.New("vrf: invalid proof")
+	// ErrEncodeToCurve indicates try-and-increment found no point for alpha,
+	// which happens with a negligible probability
+	ErrEncodeToCurve = errors.New("vrf: failed to encode alpha to the curve")
+)
+
+// Suite is a ciphersuite of ECVRF
+type Suite interface {
+	// Prove computes the proof of alpha by privKey
+	Prove(privKey ec.PrivateKey, alpha []byte) ([]byte, error)
+	// Verify checks proof of alpha by pubKey, returning its output beta
+	Verify(pubKey ec.PublicKey, alpha, proof []byte) ([]byte, error)
+	// ProofToHash computes the output beta of proof, which must be known to
+	// be valid as Verify doesn't run
+	ProofToHash(proof []byte) ([]byte, error)
+}
+
+// the ciphersuites of RFC 9381
+var (
+	// P256SHA256TAI is ECVRF-P256-SHA256-TAI over the P-256 keys of ecdsa
+	P256SHA256TAI Suite = &p256Suite{}
+	// EdwardsSHA512TAI is ECVRF-EDWARDS25519-SHA512-TAI over the keys of
+	// ed25519
+	EdwardsSHA512TAI Suite = &edwardsSuite{id: 0x03, encodeToCurve: encodeToCurveTAI}
+	// EdwardsSHA512ELL2 is ECVRF-EDWARDS25519-SHA512-ELL2 over the keys of
+	// ed25519
+	EdwardsSHA512ELL2 Suite = &edwardsSuite{id: 0x04, encodeToCurve: encodeToCurveELL2}
+)
+
+// the domain separators of the hashes
+const (
+	domainEncode    = 0x01
+	domainChallenge = 0x02
+	domainBeta      = 0x03
+	domainBack      = 0x00
+)
+
+// cLen is the byte size of the challenges of all the suites
+const cLen = 16
+
+// challenge computes Hash(suite || 0x02 || points || 0x00) truncated to cLen
+func challenge(h hash.Hash, suite byte, points ...[]byte) []byte {
+	h.Write([]byte{suite, domainChallenge})
+	for _, p := range points {
+		h.Write(p)
+	}
+	h.Write([]byte{domainBack})
+
+	return h.Sum(nil)[:cLen]
+}
+
+// beta computes the output Hash(suite || 0x03 || point || 0x00), where
+// point encodes cofactor*Gamma
+func beta(h hash.Hash, suite byte, point []byte) []byte {
+	h.Write([]byte{suite, domainBeta})
+	h.Write(point)
+	h.Write([]byte{domainBack})
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,250 @@
+package vrf_test
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	"github.com/sammy00/gravity/crypto/ec/vrf"
+	stdEd25519 "golang.org/x/crypto/ed25519"
+)
+
+type vrfTestCase struct {
+	sk    string
+	pk    string
+	alpha string
+	pi    string
+	beta  string
+}
+
+// the test vectors of the appendix B of RFC 9381
+var (
+	p256TAIVectors = []vrfTestCase{
+		{
+			"c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721",
+			"0360fed4ba255a9d31c961eb74c6356d68c049b8923b61fa6ce669622e60f29fb6",
+			hex.EncodeToString([]byte("sample")),
+			"035b5c726e8c0e2c488a107c600578ee75cb702343c153cb1eb8dec77f4b5071b4a53f0a46f018bc2c56e58d383f2305e0975972c26feea0eb122fe7893c15af376b33edf7de17c6ea056d4d82de6bc02f",
+			"a3ad7b0ef73d8fc6655053ea22f9bede8c743f08bbed3d38821f0e16474b505e",
+		},
+		{
+			"c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721",
+			"0360fed4ba255a9d31c961eb74c6356d68c049b8923b61fa6ce669622e60f29fb6",
+			hex.EncodeToString([]byte("test")),
+			"034dac60aba508ba0c01aa9be80377ebd7562c4a52d74722e0abae7dc3080ddb56c19e067b15a8a8174905b13617804534214f935b94c2287f797e393eb0816969d864f37625b443f30f1a5a33f2b3c854",
+			"a284f94ceec2ff4b3794629da7cbafa49121972671b466cab4ce170aa365f26d",
+		},
+	}
+
+	edwardsTAIVectors = []vrfTestCase{
+		{
+			"9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+			"d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+			"",
+			"8657106690b5526245a92b003bb079ccd1a92130477671f6fc01ad16f26f723f26f8a57ccaed74ee1b190bed1f479d9727d2d0f9b005a6e456a35d4fb0daab1268a1b0db10836d9826a528ca76567805",
+			"90cf1df3b703cce59e2a35b925d411164068269d7b2d29f3301c03dd757876ff66b71dda49d2de59d03450451af026798e8f81cd2e333de5cdf4f3e140fdd8ae",
+		},
+		{
+			"4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb",
+			"3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c",
+			"72",
+			"f3141cd382dc42909d19ec5110469e4feae18300e94f304590abdced48aed5933bf0864a62558b3ed7f2fea45c92a465301b3bbf5e3e54ddf2d935be3b67926da3ef39226bbc355bdc9850112c8f4b02",
+			"eb4440665d3891d668e7e0fcaf587f1b4bd7fbfe99d0eb2211ccec90496310eb5e33821bc613efb94db5e5b54c70a848a0bef4553a41befc57663b56373a5031",
+		},
+		{
+			"c5aa8df43f9f837bedb7442f31dcb7b166d38535076f094b85ce3a2e0b4458f7",
+			"fc51cd8e6218a1a38da47ed00230f0580816ed13ba3303ac5deb911548908025",
+			"af82",
+			"9bc0f79119cc5604bf02d23b4caede71393cedfbb191434dd016d30177ccbf8096bb474e53895c362d8628ee9f9ea3c0e52c7a5c691b6c18c9979866568add7a2d41b00b05081ed0f58ee5e31b3a970e",
+			"645427e5d00c62a23fb703732fa5d892940935942101e456ecca7bb217c61c452118fec1219202a0edcf038bb6373241578be7217ba85a2687f7a0310b2df19f",
+		},
+	}
+
+	edwardsELL2Vectors = []vrfTestCase{
+		{
+			"9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+			"d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+			"",
+			"7d9c633ffeee27349264cf5c667579fc583b4bda63ab71d001f89c10003ab46f14adf9a3cd8b8412d9038531e865c341cafa73589b023d14311c331a9ad15ff2fb37831e00f0acaa6d73bc9997b06501",
+			"9d574bf9b8302ec0fc1e21c3ec5368269527b87b462ce36dab2d14ccf80c53cccf6758f058c5b1c856b116388152bbe509ee3b9ecfe63d93c3b4346c1fbc6c54",
+		},
+		{
+			"4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb",
+			"3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c",
+			"72",
+			"47b327393ff2dd81336f8a2ef10339112401253b3c714eeda879f12c509072ef055b48372bb82efbdce8e10c8cb9a2f9d60e93908f93df1623ad78a86a028d6bc064dbfc75a6a57379ef855dc6733801",
+			"38561d6b77b71d30eb97a062168ae12b667ce5c28caccdf76bc88e093e4635987cd96814ce55b4689b3dd2947f80e59aac7b7675f8083865b46c89b2ce9cc735",
+		},
+		{
+			"c5aa8df43f9f837bedb7442f31dcb7b166d38535076f094b85ce3a2e0b4458f7",
+			"fc51cd8e6218a1a38da47ed00230f0580816ed13ba3303ac5deb911548908025",
+			"af82",
+			"926e895d308f5e328e7aa159c06eddbe56d06846abf5d98c2512235eaa57fdce35b46edfc655bc828d44ad09d1150f31374e7ef73027e14760d42e77341fe05467bb286cc2c9d7fde29120a0b2320d04",
+			"121b7f9b9aaaa29099fc04a94ba52784d44eac976dd1a3cca458733be5cd090a7b5fbd148444f17f8daf1fb55cb04b1ae85a626e30a54b4b0f8abf4a43314a58",
+		},
+	}
+)
+
+func mustUnhex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func edwardsKey(t *testing.T, sk, pk string) (ec.PrivateKey, ec.PublicKey) {
+	priv := stdEd25519.NewKeyFromSeed(mustUnhex(t, sk))
+	pub := priv.Public().(stdEd25519.PublicKey)
+
+	if got := hex.EncodeToString(pub); pk != got {
+		t.Fatalf("want public key %s, got %s", pk, got)
+	}
+
+	return ed25519.PrivateKey{PrivateKey: priv, PublicKey: pub}, pub
+}
+
+func p256Key(t *testing.T, sk, pk string) (ec.PrivateKey, ec.PublicKey) {
+	priv := new(ecdsa.PrivateKey)
+	priv.Curve = elliptic.P256()
+	priv.D = new(big.Int).SetBytes(mustUnhex(t, sk))
+	priv.X, priv.Y = priv.Curve.ScalarBaseMult(priv.D.Bytes())
+
+	point, err := ecdsa.MarshalSEC1(&priv.PublicKey, true)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(point); pk != got {
+		t.Fatalf("want public key %s, got %s", pk, got)
+	}
+
+	return priv, &priv.PublicKey
+}
+
+func testVectors(t *testing.T, suite vrf.Suite, testCases []vrfTestCase,
+	key func(*testing.T, string, string) (ec.PrivateKey, ec.PublicKey)) {
+	for i, c := range testCases {
+		priv, pub := key(t, c.sk, c.pk)
+		alpha := mustUnhex(t, c.alpha)
+
+		pi, err := suite.Prove(priv, alpha)
+		if nil != err {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+		if got := hex.EncodeToString(pi); c.pi != got {
+			t.Fatalf("#%d: want pi %s, got %s", i, c.pi, got)
+		}
+
+		beta, err := suite.Verify(pub, alpha, pi)
+		if nil != err {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+		if got := hex.EncodeToString(beta); c.beta != got {
+			t.Fatalf("#%d: want beta %s, got %s", i, c.beta, got)
+		}
+
+		if beta, err = suite.ProofToHash(pi); (nil != err) || (c.beta != hex.EncodeToString(beta)) {
+			t.Fatalf("#%d: want beta %s, got %x (%v)", i, c.beta, beta, err)
+		}
+
+		if _, err := suite.Verify(pub, append(alpha, 0x00), pi); vrf.ErrInvalidProof != err {
+			t.Fatalf("#%d: want %v, got %v", i, vrf.ErrInvalidProof, err)
+		}
+	}
+}
+
+func TestP256SHA256TAI(t *testing.T) {
+	testVectors(t, vrf.P256SHA256TAI, p256TAIVectors, p256Key)
+}
+
+func TestEdwardsSHA512TAI(t *testing.T) {
+	testVectors(t, vrf.EdwardsSHA512TAI, edwardsTAIVectors, edwardsKey)
+}
+
+func TestEdwardsSHA512ELL2(t *testing.T) {
+	testVectors(t, vrf.EdwardsSHA512ELL2, edwardsELL2Vectors, edwardsKey)
+}
+
+func TestVerifyTampered(t *testing.T) {
+	edPriv, err := new(ed25519.Worker).GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+	p256Priv, err := new(ecdsa.Worker256).GenerateKey(rand.Reader)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		suite vrf.Suite
+		priv  ec.PrivateKey
+	}{
+		{vrf.P256SHA256TAI, p256Priv},
+		{vrf.EdwardsSHA512TAI, edPriv},
+		{vrf.EdwardsSHA512ELL2, edPriv},
+	}
+
+	alpha := []byte("leader of epoch 42")
+	for i, c := range testCases {
+		pi, err := c.suite.Prove(c.priv, alpha)
+		if nil != err {
+			t.Fatal(err)
+		}
+		pub := c.priv.Public()
+
+		beta, err := c.suite.Verify(pub, alpha, pi)
+		if nil != err {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+
+		// the proof is deterministic, hence so is beta
+		again, err := c.suite.Prove(c.priv, alpha)
+		if (nil != err) || !bytes.Equal(pi, again) {
+			t.Fatalf("#%d: proofs of the same alpha differ", i)
+		}
+
+		for j := range pi {
+			tampered := append([]byte(nil), pi...)
+			tampered[j] ^= 0x01
+
+			if _, err := c.suite.Verify(pub, alpha, tampered); nil == err {
+				t.Fatalf("#%d: the proof tampered at %d is accepted", i, j)
+			}
+		}
+
+		if _, err := c.suite.Verify(pub, alpha, pi[1:]); ec.ErrMalformedSig != err {
+			t.Fatalf("#%d: want %v, got %v", i, ec.ErrMalformedSig, err)
+		}
+		if _, err := c.suite.ProofToHash(pi[1:]); ec.ErrMalformedSig != err {
+			t.Fatalf("#%d: want %v, got %v", i, ec.ErrMalformedSig, err)
+		}
+		if got, err := c.suite.ProofToHash(pi); (nil != err) || !bytes.Equal(beta, got) {
+			t.Fatalf("#%d: want %x, got %x (%v)", i, beta, got, err)
+		}
+	}
+
+	// keys of the other suite are refused
+	if _, err := vrf.P256SHA256TAI.Prove(edPriv, alpha); ec.ErrKeyTampered != err {
+		t.Fatalf("want %v, got %v", ec.ErrKeyTampered, err)
+	}
+	if _, err := vrf.EdwardsSHA512ELL2.Prove(p256Priv, alpha); ec.ErrKeyTampered != err {
+		t.Fatalf("want %v, got %v", ec.ErrKeyTampered, err)
+	}
+}
+
+func TestVerifySmallOrderKey(t *testing.T) {
+	// the identity passes no proof, as beta would be the same for any alpha
+	identity := make(ed25519.PublicKey, 32)
+	identity[0] = 0x01
+
+	if _, err := vrf.EdwardsSHA512ELL2.Verify(identity, nil, make([]byte, 80)); ec.ErrPointAtInfinity != err {
+		t.Fatalf("want %v, got %v", ec.ErrPointAtInfinity, err)
+	}
+}
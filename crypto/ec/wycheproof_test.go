@@ -0,0 +1,195 @@
+package ec_test
+
+import (
+	stdEcdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/sammy00/gravity/crypto/ec"
+	"github.com/sammy00/gravity/crypto/ec/ecdsa"
+	"github.com/sammy00/gravity/crypto/ec/ed25519"
+	"github.com/sammy00/gravity/crypto/ec/secp"
+)
+
+// wycheproofSuite is the part of the vector files used by the harness
+type wycheproofSuite struct {
+	Algorithm     string `json:"algorithm"`
+	NumberOfTests int    `json:"numberOfTests"`
+	TestGroups    []struct {
+		Type      string `json:"type"`
+		Sha       string `json:"sha"`
+		PublicKey struct {
+			Curve string `json:"curve"`
+			Wx    string `json:"wx"`
+			Wy    string `json:"wy"`
+			Pk    string `json:"pk"`
+		} `json:"publicKey"`
+		Tests []wycheproofTest `json:"tests"`
+	} `json:"testGroups"`
+}
+
+type wycheproofTest struct {
+	TcID    int      `json:"tcId"`
+	Comment string   `json:"comment"`
+	Flags   []string `json:"flags"`
+	Msg     string   `json:"msg"`
+	Sig     string   `json:"sig"`
+	Result  string   `json:"result"`
+}
+
+// wycheproofDeviations lists the known deviations of the workers by file
+// and tcId, which are reported rather than failing the test
+var wycheproofDeviations = map[string]map[int]string{}
+
+type wycheproofFile struct {
+	name   string
+	worker ec.Worker
+	curve  elliptic.Curve
+}
+
+// p1363 configures w for the r||s signatures
+func p1363(w ec.Worker) ec.Worker {
+	switch w := w.(type) {
+	case *ecdsa.Worker256:
+		w.Encoding = ecdsa.EncodingP1363
+	case *ecdsa.Worker512:
+		w.Encoding = ecdsa.EncodingP1363
+	case *secp.Worker:
+		w.Encoding = ecdsa.EncodingP1363
+	}
+
+	return w
+}
+
+var wycheproofFiles = []wycheproofFile{
+	{"ecdsa_secp256r1_sha256_test.json", new(ecdsa.Worker256), elliptic.P256()},
+	{"ecdsa_secp256r1_sha256_p1363_test.json", p1363(new(ecdsa.Worker256)), elliptic.P256()},
+	{"ecdsa_secp521r1_sha512_test.json", new(ecdsa.Worker512), elliptic.P521()},
+	{"ecdsa_secp521r1_sha512_p1363_test.json", p1363(new(ecdsa.Worker512)), elliptic.P521()},
+	{"ecdsa_secp256k1_sha256_test.json", secp.New(), secp.S256()},
+	{"ecdsa_secp256k1_sha256_p1363_test.json", p1363(secp.New()), secp.S256()},
+	{"ed25519_test.json", new(ed25519.Worker), nil},
+	{"ed25519_test.json", &ed25519.Worker{Mode: ed25519.VerifyStrict}, nil},
+	{"ed25519_test.json", &ed25519.Worker{Mode: ed25519.VerifyCofactored}, nil},
+	{"ed25519_test.json", &ed25519.Worker{Mode: ed25519.VerifyZIP215}, nil},
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func wycheproofDigest(t *testing.T, sha string, msg []byte) []byte {
+	switch sha {
+	case "SHA-256":
+		h := sha256.Sum256(msg)
+		return h[:]
+	case "SHA-512":
+		h := sha512.Sum512(msg)
+		return h[:]
+	case "":
+		// EdDSA signs the message as a whole
+		return msg
+	}
+
+	t.Fatalf("unsupported hash %s", sha)
+	return nil
+}
+
+// flagStats counts the outcomes of the vectors carrying a flag
+type flagStats struct {
+	passed, failed, deviated int
+}
+
+func TestWycheproof(t *testing.T) {
+	for _, f := range wycheproofFiles {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			data, err := ioutil.ReadFile(filepath.Join("testdata", "wycheproof", f.name))
+			if nil != err {
+				t.Fatal(err)
+			}
+
+			var suite wycheproofSuite
+			if err := json.Unmarshal(data, &suite); nil != err {
+				t.Fatal(err)
+			}
+
+			deviations := wycheproofDeviations[f.name]
+			stats := make(map[string]*flagStats)
+			seen := 0
+
+			for _, g := range suite.TestGroups {
+				var pub ec.PublicKey
+				if nil == f.curve {
+					pub = ed25519.PublicKey(mustHex(t, g.PublicKey.Pk))
+				} else {
+					pub = &stdEcdsa.PublicKey{
+						Curve: f.curve,
+						X:     new(big.Int).SetBytes(mustHex(t, g.PublicKey.Wx)),
+						Y:     new(big.Int).SetBytes(mustHex(t, g.PublicKey.Wy)),
+					}
+				}
+
+				for _, tc := range g.Tests {
+					seen++
+
+					sig := mustHex(t, tc.Sig)
+					digest := wycheproofDigest(t, g.Sha, mustHex(t, tc.Msg))
+
+					got := f.worker.Verify(pub, digest, sig)
+					// the acceptable vectors may go either way
+					ok := ("acceptable" == tc.Result) || (got == ("valid" == tc.Result))
+
+					_, allowed := deviations[tc.TcID]
+					for _, flag := range tc.Flags {
+						if nil == stats[flag] {
+							stats[flag] = new(flagStats)
+						}
+						switch {
+						case ok:
+							stats[flag].passed++
+						case allowed:
+							stats[flag].deviated++
+						default:
+							stats[flag].failed++
+						}
+					}
+
+					switch {
+					case !ok && !allowed:
+						t.Errorf("tcId %d (%s, %v): want %s, got valid=%v", tc.TcID, tc.Comment, tc.Flags, tc.Result, got)
+					case ok && allowed:
+						t.Logf("tcId %d passes now and can be dropped from the deviations", tc.TcID)
+					}
+				}
+			}
+
+			if suite.NumberOfTests != seen {
+				t.Errorf("want %d vectors, got %d", suite.NumberOfTests, seen)
+			}
+
+			flags := make([]string, 0, len(stats))
+			for flag := range stats {
+				flags = append(flags, flag)
+			}
+			sort.Strings(flags)
+			for _, flag := range flags {
+				s := stats[flag]
+				t.Logf("%-30s passed %4d, failed %4d, known deviations %4d", flag, s.passed, s.failed, s.deviated)
+			}
+		})
+	}
+}